@@ -10,6 +10,7 @@ import (
 	"syscall"
 
 	"github.com/gorilla/mux"
+	"github.com/kyma-project/helm-broker/internal/audit"
 	"github.com/kyma-project/helm-broker/internal/bind"
 	"github.com/kyma-project/helm-broker/internal/broker"
 	"github.com/kyma-project/helm-broker/internal/config"
@@ -47,8 +48,31 @@ func main() {
 	sFact, err := storage.NewFactory(&storageConfig)
 	fatalOnError(err)
 
-	srv := broker.New(sFact.Addon(), sFact.Chart(), sFact.InstanceOperation(), sFact.BindOperation(), sFact.Instance(), sFact.InstanceBindData(),
-		bind.NewRenderer(), bind.NewResolver(clientset.CoreV1()), helmClient, log)
+	instanceOperationStorage, bindOperationStorage := sFact.InstanceOperation(), sFact.BindOperation()
+	auditSink, err := audit.NewSink(cfg.Audit)
+	fatalOnError(err)
+	if auditSink != nil {
+		defer auditSink.Close()
+		instanceOperationStorage = audit.NewInstanceOperationStorage(instanceOperationStorage, auditSink, log)
+		bindOperationStorage = audit.NewBindOperationStorage(bindOperationStorage, auditSink, log)
+	}
+
+	authenticator, err := broker.NewAuthenticator(cfg.Auth, clientset.AuthenticationV1().TokenReviews())
+	fatalOnError(err)
+	authorizer := broker.NewAuthorizer(cfg.Authz, clientset.AuthorizationV1().SubjectAccessReviews())
+	adminAuthn, adminAuthz := broker.NewAdminAuth(cfg.AdminAuth, clientset.AuthenticationV1().TokenReviews(), clientset.AuthorizationV1().SubjectAccessReviews())
+
+	// the dashboard URL templates can look up objects only from the namespace of the instance
+	dashboardRenderer := bind.NewRenderer().WithNamespacedLookup(k8sConfig)
+
+	srv := broker.New(sFact.Addon(), sFact.Chart(), instanceOperationStorage, bindOperationStorage, sFact.Instance(), sFact.InstanceBindData(),
+		bind.NewRenderer(), bind.NewResolver(clientset.CoreV1()), dashboardRenderer, helmClient, authenticator, authorizer, broker.Config{
+			OperationQueue:              cfg.OperationQueue,
+			OperationReaper:             cfg.OperationReaper,
+			DriftReconciler:             cfg.DriftReconciler,
+			AtomicProvisioning:          cfg.AtomicProvisioning,
+			SynchronousOperationTimeout: cfg.SynchronousOperationTimeout,
+		}, log).WithAdminAuth(adminAuthn, adminAuthz)
 
 	go health.NewBrokerProbes(fmt.Sprintf(":%d", cfg.StatusPort), storageConfig.ExtractEtcdURL()).Handle()
 	go runMetricsServer(fmt.Sprintf(":%d", cfg.MetricsPort))
@@ -61,7 +85,11 @@ func main() {
 
 	fatalOnError(storageConfig.WaitForEtcdReadiness(log))
 
-	err = srv.Run(ctx, fmt.Sprintf(":%d", cfg.Port), startedCh)
+	if cfg.TLS.Enabled() {
+		err = srv.RunTLS(ctx, fmt.Sprintf(":%d", cfg.Port), cfg.TLS, startedCh)
+	} else {
+		err = srv.Run(ctx, fmt.Sprintf(":%d", cfg.Port), startedCh)
+	}
 	fatalOnError(err)
 }
 
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+)
+
+// apiVersion is sent in the X-Broker-API-Version header required by the broker
+const apiVersion = "2.14"
+
+func main() {
+	brokerURL := flag.String("broker-url", "", "URL of the broker, e.g. http://helm-broker.kyma-system.svc.cluster.local/cluster for cluster-wide addons or http://helm-broker.kyma-system.svc.cluster.local/ns/stage for addons from the stage Namespace.")
+	releaseName := flag.String("release", "", "Name of the Helm release to import.")
+	namespace := flag.String("namespace", "", "Namespace of the Helm release.")
+	addonID := flag.String("addon-id", "", "ID of the addon which chart was used to install the release.")
+	planID := flag.String("plan-id", "", "ID of the addon plan which chart was used to install the release.")
+	instanceID := flag.String("instance-id", "", "ID of the created service instance.")
+	token := flag.String("token", "", "Bearer token of the Kubernetes user allowed to import releases from the Namespace.")
+	flag.Parse()
+
+	l := logrus.New()
+	l.Formatter = &logrus.TextFormatter{
+		FullTimestamp: true,
+	}
+
+	if *brokerURL == "" || *instanceID == "" || *token == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	req := broker.ImportInstanceRequestDTO{
+		ReleaseName: internal.ReleaseName(*releaseName),
+		Namespace:   internal.Namespace(*namespace),
+		AddonID:     internal.AddonID(*addonID),
+		PlanID:      internal.AddonPlanID(*planID),
+	}
+	if err := req.Validate(); err != nil {
+		l.Fatalln(errors.Wrap(err, "while validating flags"))
+	}
+
+	httpReq, err := newImportRequest(*brokerURL, internal.InstanceID(*instanceID), req)
+	if err != nil {
+		l.Fatalln(errors.Wrap(err, "while creating import request"))
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+*token)
+
+	resp, err := (&http.Client{Timeout: time.Minute}).Do(httpReq)
+	if err != nil {
+		l.Fatalln(errors.Wrap(err, "while sending import request"))
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		l.Fatalln(errors.Wrap(err, "while reading import response"))
+	}
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		l.Infof("Helm release %q was imported as instance %q", *releaseName, *instanceID)
+	case http.StatusOK:
+		l.Infof("Helm release %q is already imported as instance %q", *releaseName, *instanceID)
+	default:
+		l.Fatalf("Import failed with status %d: %s", resp.StatusCode, body)
+	}
+	fmt.Println(string(body))
+}
+
+func newImportRequest(brokerURL string, iID internal.InstanceID, dto broker.ImportInstanceRequestDTO) (*http.Request, error) {
+	body, err := json.Marshal(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/admin/service_instances/%s/import", strings.TrimSuffix(brokerURL, "/"), iID)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(osb.APIVersionHeader, apiVersion)
+	return req, nil
+}
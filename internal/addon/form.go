@@ -3,6 +3,7 @@ package addon
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/kyma-project/helm-broker/internal"
@@ -189,6 +190,8 @@ type formPlan struct {
 	SchemasUpdate *internal.PlanSchema
 	Values        map[string]interface{}
 	BindTemplate  []byte
+	// DashboardURLTemplate is optional
+	DashboardURLTemplate []byte
 }
 
 func (p *formPlan) Validate() error {
@@ -225,6 +228,11 @@ func (p *formPlan) ToModel(c *chart.Chart) (internal.AddonPlan, error) {
 		Version: *cVer,
 	}
 
+	helmOptions, err := p.Meta.Helm.ToModel()
+	if err != nil {
+		return internal.AddonPlan{}, errors.Wrap(err, "while converting helm options")
+	}
+
 	mappedSchemas := make(map[internal.PlanSchemaType]internal.PlanSchema)
 
 	if p.SchemasUpdate != nil {
@@ -244,12 +252,17 @@ func (p *formPlan) ToModel(c *chart.Chart) (internal.AddonPlan, error) {
 		Metadata: internal.AddonPlanMetadata{
 			DisplayName: p.Meta.DisplayName,
 		},
-		ChartValues:  internal.ChartValues(p.Values),
-		Schemas:      mappedSchemas,
-		ChartRef:     cRef,
-		Bindable:     p.Meta.Bindable,
-		BindTemplate: p.BindTemplate,
-		Free:         p.Meta.Free,
+		ChartValues:           internal.ChartValues(p.Values),
+		Schemas:               mappedSchemas,
+		ChartRef:              cRef,
+		Bindable:              p.Meta.Bindable,
+		BindTemplate:          p.BindTemplate,
+		DashboardURLTemplate:  p.DashboardURLTemplate,
+		Free:                  p.Meta.Free,
+		AtomicProvisioning:    p.Meta.AtomicProvisioning,
+		SynchronousOperations: p.Meta.SynchronousOperations,
+		RunTests:              p.Meta.RunTests,
+		HelmOptions:           helmOptions,
 	}, nil
 }
 
@@ -260,6 +273,57 @@ type formPlanMeta struct {
 	DisplayName string `yaml:"displayName"`
 	Bindable    *bool  `yaml:"bindable"`
 	Free        *bool  `yaml:"free"`
+	// AtomicProvisioning overrides the broker configuration of the cleanup of failed provisioning
+	AtomicProvisioning *bool `yaml:"atomicProvisioning"`
+	// SynchronousOperations allows for provisioning and deprovisioning without the asynchronous operation mode
+	SynchronousOperations bool `yaml:"synchronousOperations"`
+	// RunTests enables running the chart tests after the release is installed or upgraded
+	RunTests bool `yaml:"runTests"`
+	// Helm defines the options of the helm operations on the release of the plan
+	Helm formPlanHelmOptions `yaml:"helm"`
+}
+
+type formPlanHelmOptions struct {
+	Timeout      string `yaml:"timeout"`
+	Wait         *bool  `yaml:"wait"`
+	WaitForJobs  bool   `yaml:"waitForJobs"`
+	Atomic       bool   `yaml:"atomic"`
+	DisableHooks bool   `yaml:"disableHooks"`
+	SkipCRDs     bool   `yaml:"skipCRDs"`
+}
+
+// Validate checks if the timeout is a valid duration and if the options do not exclude each other
+func (o *formPlanHelmOptions) Validate() error {
+	if _, err := o.ToModel(); err != nil {
+		return err
+	}
+	if o.Wait != nil && !*o.Wait && o.WaitForJobs {
+		return errors.New("helm waitForJobs option requires the wait option")
+	}
+	return nil
+}
+
+func (o *formPlanHelmOptions) ToModel() (internal.HelmOptions, error) {
+	var timeout time.Duration
+	if o.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(o.Timeout)
+		if err != nil {
+			return internal.HelmOptions{}, errors.Wrap(err, "while parsing helm timeout option")
+		}
+		if timeout <= 0 {
+			return internal.HelmOptions{}, errors.Errorf("helm timeout option must be positive, got %s", o.Timeout)
+		}
+	}
+
+	return internal.HelmOptions{
+		Timeout:      timeout,
+		Wait:         o.Wait,
+		WaitForJobs:  o.WaitForJobs,
+		Atomic:       o.Atomic,
+		DisableHooks: o.DisableHooks,
+		SkipCRDs:     o.SkipCRDs,
+	}, nil
 }
 
 func (f *formPlanMeta) Validate() error {
@@ -276,6 +340,9 @@ func (f *formPlanMeta) Validate() error {
 	if f.DisplayName == "" {
 		messages = append(messages, "missing displayName field")
 	}
+	if err := f.Helm.Validate(); err != nil {
+		messages = append(messages, err.Error())
+	}
 	if len(messages) > 0 {
 		return errors.New(strings.Join(messages, ", "))
 	}
@@ -2,6 +2,7 @@ package addon
 
 import (
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/kyma-project/helm-broker/internal"
@@ -175,6 +176,10 @@ func TestFormValidateFailure(t *testing.T) {
 func TestFormPlanToModelSuccess(t *testing.T) {
 	// given
 	fixPlan := fixValidFormPlan("test-to-model-success")
+	atomic := true
+	fixPlan.Meta.AtomicProvisioning = &atomic
+	noWait := false
+	fixPlan.Meta.Helm = formPlanHelmOptions{Timeout: "2h", Wait: &noWait, DisableHooks: true, SkipCRDs: true}
 	fixChart := fixValidChart()
 
 	charVer, err := semver.NewVersion(fixValidChart().Metadata.Version)
@@ -196,9 +201,17 @@ func TestFormPlanToModelSuccess(t *testing.T) {
 			Name:    internal.ChartName(fixChart.Metadata.Name),
 			Version: *charVer,
 		},
-		ChartValues:  fixPlan.Values,
-		Bindable:     fixPlan.Meta.Bindable,
-		BindTemplate: fixPlan.BindTemplate,
+		ChartValues:          fixPlan.Values,
+		Bindable:             fixPlan.Meta.Bindable,
+		BindTemplate:         fixPlan.BindTemplate,
+		DashboardURLTemplate: fixPlan.DashboardURLTemplate,
+		AtomicProvisioning:   &atomic,
+		HelmOptions: internal.HelmOptions{
+			Timeout:      2 * time.Hour,
+			Wait:         &noWait,
+			DisableHooks: true,
+			SkipCRDs:     true,
+		},
 	}
 
 	// when
@@ -307,6 +320,24 @@ func TestFormPlanValidateFailure(t *testing.T) {
 			}(),
 			errMsg: "while validating plan meta: missing displayName field",
 		},
+		"invalid helm timeout": {
+			fixFormPlan: func() formPlan {
+				fix := fixValidFormPlan("invalid-helm-options")
+				fix.Meta.Helm.Timeout = "-1m"
+				return fix
+			}(),
+			errMsg: "while validating plan meta: helm timeout option must be positive, got -1m",
+		},
+		"helm wait for jobs without wait": {
+			fixFormPlan: func() formPlan {
+				fix := fixValidFormPlan("invalid-helm-options")
+				noWait := false
+				fix.Meta.Helm.Wait = &noWait
+				fix.Meta.Helm.WaitForJobs = true
+				return fix
+			}(),
+			errMsg: "while validating plan meta: helm waitForJobs option requires the wait option",
+		},
 	} {
 		t.Run(tn, func(t *testing.T) {
 			// when
@@ -361,7 +392,8 @@ func fixValidFormPlan(id string) formPlan {
 		Values: map[string]interface{}{
 			"par1": "val1",
 		},
-		BindTemplate: []byte(`bindTemplate`),
+		BindTemplate:         []byte(`bindTemplate`),
+		DashboardURLTemplate: []byte(`https://{{ .Values.host }}`),
 	}
 }
 
@@ -32,6 +32,7 @@ const (
 	addonPlanSchemaUpdateJSONName = "update-instance-schema.json"
 	addonPlanValuesFileName       = "values.yaml"
 	addonPlanBindTemplateFileName = "bind.yaml"
+	addonPlanDashboardURLFileName = "dashboard-url.tpl"
 
 	maxSchemaLength = 65536 // 64 k
 )
@@ -91,6 +92,8 @@ func (l Loader) loadDir(path string) (*internal.Addon, []*chart.Chart, error) {
 		return nil, nil, errors.Wrap(err, "while validating form")
 	}
 
+	l.deriveCreateSchemas(form, c)
+
 	yb, err := form.ToModel(c)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "while mapping form to model")
@@ -99,6 +102,27 @@ func (l Loader) loadDir(path string) (*internal.Addon, []*chart.Chart, error) {
 	return &yb, []*chart.Chart{c}, nil
 }
 
+// deriveCreateSchemas sets the create schema derived from the chart values schema for all plans
+// which do not define their own create schema.
+func (l Loader) deriveCreateSchemas(f *form, c *chart.Chart) {
+	if len(c.Schema) == 0 {
+		return
+	}
+
+	for name, plan := range f.Plans {
+		if plan.SchemasCreate != nil {
+			continue
+		}
+
+		schema, err := createSchemaFromValuesSchema(c.Schema, c.Values, plan.Values)
+		if err != nil {
+			l.log.Warnf("Create schema for plan %q was not derived from the chart values schema: %v", name, err)
+			continue
+		}
+		plan.SchemasCreate = schema
+	}
+}
+
 func (l Loader) loadChartFromDir(baseDir string) (*chart.Chart, error) {
 	// In current version we have only one chart per addon
 	// in future version we will have some loop over each plan to load all charts
@@ -247,6 +271,10 @@ func (Loader) loadPlanDefinition(path string, plan *formPlan) error {
 		return errors.Wrapf(err, "while loading plan %q file", addonPlanBindTemplateFileName)
 	}
 
+	if plan.DashboardURLTemplate, err = loadRaw(topdir, addonPlanDashboardURLFileName, false); err != nil {
+		return errors.Wrapf(err, "while loading plan %q file", addonPlanDashboardURLFileName)
+	}
+
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package addon_test
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/chart/loader"
 
+	"github.com/kyma-project/helm-broker/internal"
 	"github.com/kyma-project/helm-broker/internal/addon"
 	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
 )
@@ -123,3 +125,43 @@ func TestLoaderLoadFailure(t *testing.T) {
 	}
 
 }
+
+func TestLoaderLoadDirDerivesCreateSchemaFromValuesSchema(t *testing.T) {
+	// given
+	addonLoader := addon.NewLoader("../../tmp", spy.NewLogDummy())
+
+	// when
+	yb, _, err := addonLoader.LoadDir("testdata/addon-values-schema")
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, yb)
+
+	defaultPlan, found := yb.Plans["7fa8a1c6-2a5f-4b3d-9c1e-4a9c1f0b6c01"]
+	require.True(t, found)
+	derived, err := json.Marshal(defaultPlan.Schemas[internal.SchemaTypeProvision])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"replicas": {"type": "integer", "minimum": 1},
+			"image": {
+				"type": "object",
+				"properties": {"tag": {"type": "string"}},
+				"required": ["tag"]
+			}
+		},
+		"required": ["replicas"]
+	}`, string(derived))
+
+	customPlan, found := yb.Plans["7fa8a1c6-2a5f-4b3d-9c1e-4a9c1f0b6c02"]
+	require.True(t, found)
+	own, err := json.Marshal(customPlan.Schemas[internal.SchemaTypeProvision])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"type": "object",
+		"properties": {"replicas": {"type": "integer"}}
+	}`, string(own))
+}
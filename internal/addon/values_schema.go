@@ -0,0 +1,125 @@
+package addon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+// createSchemaFromValuesSchema builds the plan create schema from the chart values schema (values.schema.json).
+// Keys which are already fixed by the plan values are removed from the schema,
+// so the schema describes only those parameters which can be still provided by the user.
+// Keys with the default value in the chart are not required from the user.
+func createSchemaFromValuesSchema(valuesSchema []byte, chartValues, planValues map[string]interface{}) (*internal.PlanSchema, error) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(valuesSchema, &schema); err != nil {
+		return nil, errors.Wrap(err, "while unmarshalling chart values schema")
+	}
+
+	narrowSchema(schema, planValues)
+	relaxRequired(schema, chartValues)
+
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "while marshalling narrowed values schema")
+	}
+
+	// OSB API defines: Schemas MUST NOT be larger than 64kB.
+	// See: https://github.com/openservicebrokerapi/servicebroker/blob/v2.14/spec.md#schema-object
+	if len(raw) >= maxSchemaLength {
+		return nil, fmt.Errorf("schema derived from chart values schema is larger than 64 kB")
+	}
+
+	var out internal.PlanSchema
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, errors.Wrap(err, "while unmarshalling narrowed values schema")
+	}
+
+	return &out, nil
+}
+
+// narrowSchema removes from the object schema all properties fixed by the given values.
+// Nested objects are narrowed recursively when the values fix only some of their keys.
+func narrowSchema(schema map[string]interface{}, fixed map[string]interface{}) {
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	var fixedKeys []string
+	for key, fixedVal := range fixed {
+		propSchema, found := props[key]
+		if !found {
+			continue
+		}
+
+		nestedSchema, isSchema := propSchema.(map[string]interface{})
+		nestedFixed, isMap := fixedVal.(map[string]interface{})
+		if isSchema && isMap {
+			if _, hasProps := nestedSchema["properties"]; hasProps {
+				narrowSchema(nestedSchema, nestedFixed)
+				if nestedProps, _ := nestedSchema["properties"].(map[string]interface{}); len(nestedProps) > 0 {
+					continue
+				}
+			}
+		}
+
+		delete(props, key)
+		fixedKeys = append(fixedKeys, key)
+	}
+
+	removeRequired(schema, fixedKeys)
+}
+
+// relaxRequired removes from the required properties of the object schema all keys which have default values.
+func relaxRequired(schema map[string]interface{}, defaults map[string]interface{}) {
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	var defaultKeys []string
+	for key, defaultVal := range defaults {
+		defaultKeys = append(defaultKeys, key)
+
+		nestedSchema, isSchema := props[key].(map[string]interface{})
+		nestedDefaults, isMap := defaultVal.(map[string]interface{})
+		if isSchema && isMap {
+			relaxRequired(nestedSchema, nestedDefaults)
+		}
+	}
+
+	removeRequired(schema, defaultKeys)
+}
+
+func removeRequired(schema map[string]interface{}, keys []string) {
+	required, ok := schema["required"].([]interface{})
+	if !ok || len(keys) == 0 {
+		return
+	}
+
+	isRemoved := func(name interface{}) bool {
+		for _, k := range keys {
+			if k == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var narrowedRequired []interface{}
+	for _, name := range required {
+		if !isRemoved(name) {
+			narrowedRequired = append(narrowedRequired, name)
+		}
+	}
+
+	if len(narrowedRequired) == 0 {
+		delete(schema, "required")
+		return
+	}
+	schema["required"] = narrowedRequired
+}
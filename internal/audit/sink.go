@@ -0,0 +1,104 @@
+// Package audit records who requested the service instance and binding operations and how the operations ended.
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kyma-project/helm-broker/internal"
+	yTime "github.com/kyma-project/helm-broker/internal/platform/time"
+)
+
+// OutputStdout is the audit log output which writes events to the standard output
+const OutputStdout = "stdout"
+
+// Config holds configuration of the audit log
+type Config struct {
+	// Output defines where the audit events are written, "stdout" or the path to the file.
+	// The audit log is disabled when it is not set.
+	Output string
+}
+
+// Event describes the state transition of the operation
+type Event struct {
+	Time          time.Time               `json:"time"`
+	InstanceID    internal.InstanceID     `json:"instanceID"`
+	BindingID     internal.BindingID      `json:"bindingID,omitempty"`
+	OperationID   internal.OperationID    `json:"operationID"`
+	OperationType internal.OperationType  `json:"operationType"`
+	State         internal.OperationState `json:"state"`
+	Description   string                  `json:"description,omitempty"`
+	Username      string                  `json:"username,omitempty"`
+	UID           string                  `json:"uid,omitempty"`
+}
+
+// Sink writes audit events
+type Sink interface {
+	Write(event Event) error
+}
+
+// JSONLinesSink writes every event as the single line JSON document
+type JSONLinesSink struct {
+	mu          sync.Mutex
+	enc         *json.Encoder
+	closer      io.Closer
+	nowProvider yTime.NowProvider
+}
+
+// NewSink returns the sink configured by the output. Nil is returned when the audit log is disabled.
+func NewSink(cfg Config) (*JSONLinesSink, error) {
+	switch cfg.Output {
+	case "":
+		return nil, nil
+	case OutputStdout:
+		return NewJSONLinesSink(os.Stdout, nil), nil
+	default:
+		f, err := os.OpenFile(cfg.Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while opening audit log file %s", cfg.Output)
+		}
+		return NewJSONLinesSink(f, f), nil
+	}
+}
+
+// NewJSONLinesSink returns the sink which writes events to the writer. The closer is called on Close, it may be nil.
+func NewJSONLinesSink(w io.Writer, closer io.Closer) *JSONLinesSink {
+	return &JSONLinesSink{
+		enc:    json.NewEncoder(w),
+		closer: closer,
+	}
+}
+
+// WithTimeProvider allows for passing custom time provider.
+// Used mostly in testing.
+func (s *JSONLinesSink) WithTimeProvider(nowProvider func() time.Time) *JSONLinesSink {
+	s.nowProvider = nowProvider
+	return s
+}
+
+// Write writes the event. The event time is set when it is empty.
+func (s *JSONLinesSink) Write(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = s.nowProvider.Now().UTC()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(event); err != nil {
+		return errors.Wrap(err, "while writing audit event")
+	}
+	return nil
+}
+
+// Close closes the underlying file
+func (s *JSONLinesSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
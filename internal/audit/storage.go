@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/storage"
+)
+
+// NewInstanceOperationStorage returns the storage which writes the audit event on every inserted operation
+// and every state change. Failures of the sink are logged and do not fail the storage calls.
+func NewInstanceOperationStorage(s storage.InstanceOperation, sink Sink, log logrus.FieldLogger) storage.InstanceOperation {
+	return &instanceOperationStorage{
+		InstanceOperation: s,
+		sink:              sink,
+		log:               log.WithField("service", "audit:instance-operation"),
+	}
+}
+
+type instanceOperationStorage struct {
+	storage.InstanceOperation
+	sink Sink
+	log  logrus.FieldLogger
+}
+
+// Insert inserts the operation and writes the event with its initial state
+func (s *instanceOperationStorage) Insert(io *internal.InstanceOperation) error {
+	if err := s.InstanceOperation.Insert(io); err != nil {
+		return err
+	}
+	s.write(io)
+	return nil
+}
+
+// UpdateState modifies the state and writes the event
+func (s *instanceOperationStorage) UpdateState(iID internal.InstanceID, opID internal.OperationID, state internal.OperationState) error {
+	if err := s.InstanceOperation.UpdateState(iID, opID, state); err != nil {
+		return err
+	}
+	s.writeStored(iID, opID)
+	return nil
+}
+
+// UpdateStateDesc modifies the state and description and writes the event
+func (s *instanceOperationStorage) UpdateStateDesc(iID internal.InstanceID, opID internal.OperationID, state internal.OperationState, desc *string) error {
+	if err := s.InstanceOperation.UpdateStateDesc(iID, opID, state, desc); err != nil {
+		return err
+	}
+	s.writeStored(iID, opID)
+	return nil
+}
+
+func (s *instanceOperationStorage) writeStored(iID internal.InstanceID, opID internal.OperationID) {
+	io, err := s.InstanceOperation.Get(iID, opID)
+	if err != nil {
+		s.log.Errorf("Cannot get operation %s of instance %s to write audit event: %v", opID, iID, err)
+		return
+	}
+	s.write(io)
+}
+
+func (s *instanceOperationStorage) write(io *internal.InstanceOperation) {
+	err := s.sink.Write(Event{
+		InstanceID:    io.InstanceID,
+		OperationID:   io.OperationID,
+		OperationType: io.Type,
+		State:         io.State,
+		Description:   deref(io.StateDescription),
+		Username:      io.RequestedBy.Username,
+		UID:           io.RequestedBy.UID,
+	})
+	if err != nil {
+		s.log.Errorf("Cannot write audit event for operation %s of instance %s: %v", io.OperationID, io.InstanceID, err)
+	}
+}
+
+// NewBindOperationStorage returns the storage which writes the audit event on every inserted operation
+// and every state change. Failures of the sink are logged and do not fail the storage calls.
+func NewBindOperationStorage(s storage.BindOperation, sink Sink, log logrus.FieldLogger) storage.BindOperation {
+	return &bindOperationStorage{
+		BindOperation: s,
+		sink:          sink,
+		log:           log.WithField("service", "audit:bind-operation"),
+	}
+}
+
+type bindOperationStorage struct {
+	storage.BindOperation
+	sink Sink
+	log  logrus.FieldLogger
+}
+
+// Insert inserts the operation and writes the event with its initial state
+func (s *bindOperationStorage) Insert(bo *internal.BindOperation) error {
+	if err := s.BindOperation.Insert(bo); err != nil {
+		return err
+	}
+	s.write(bo)
+	return nil
+}
+
+// UpdateState modifies the state and writes the event
+func (s *bindOperationStorage) UpdateState(iID internal.InstanceID, bID internal.BindingID, opID internal.OperationID, state internal.OperationState) error {
+	if err := s.BindOperation.UpdateState(iID, bID, opID, state); err != nil {
+		return err
+	}
+	s.writeStored(iID, bID, opID)
+	return nil
+}
+
+// UpdateStateDesc modifies the state and description and writes the event
+func (s *bindOperationStorage) UpdateStateDesc(iID internal.InstanceID, bID internal.BindingID, opID internal.OperationID, state internal.OperationState, desc *string) error {
+	if err := s.BindOperation.UpdateStateDesc(iID, bID, opID, state, desc); err != nil {
+		return err
+	}
+	s.writeStored(iID, bID, opID)
+	return nil
+}
+
+func (s *bindOperationStorage) writeStored(iID internal.InstanceID, bID internal.BindingID, opID internal.OperationID) {
+	bo, err := s.BindOperation.Get(iID, bID, opID)
+	if err != nil {
+		s.log.Errorf("Cannot get operation %s of binding %s to write audit event: %v", opID, bID, err)
+		return
+	}
+	s.write(bo)
+}
+
+func (s *bindOperationStorage) write(bo *internal.BindOperation) {
+	err := s.sink.Write(Event{
+		InstanceID:    bo.InstanceID,
+		BindingID:     bo.BindingID,
+		OperationID:   bo.OperationID,
+		OperationType: bo.Type,
+		State:         bo.State,
+		Description:   deref(bo.StateDescription),
+		Username:      bo.RequestedBy.Username,
+		UID:           bo.RequestedBy.UID,
+	})
+	if err != nil {
+		s.log.Errorf("Cannot write audit event for operation %s of binding %s: %v", bo.OperationID, bo.BindingID, err)
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
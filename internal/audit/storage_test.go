@@ -0,0 +1,86 @@
+package audit_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/audit"
+	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
+	"github.com/kyma-project/helm-broker/internal/storage/driver/memory"
+)
+
+func TestInstanceOperationStorageWritesEventOnStateTransition(t *testing.T) {
+	// GIVEN
+	fixTime := time.Date(2020, 1, 1, 0, 0, 1, 0, time.UTC)
+	buf := &bytes.Buffer{}
+	sink := audit.NewJSONLinesSink(buf, nil).WithTimeProvider(func() time.Time { return fixTime })
+	s := audit.NewInstanceOperationStorage(memory.NewInstanceOperation(), sink, spy.NewLogSink().Logger)
+
+	requester := internal.OperationRequester{Username: "admin", UID: "123"}
+
+	// WHEN
+	require.NoError(t, s.Insert(&internal.InstanceOperation{
+		InstanceID:  "iID",
+		OperationID: "opID",
+		Type:        internal.OperationTypeCreate,
+		State:       internal.OperationStateInProgress,
+		RequestedBy: requester,
+	}))
+	require.NoError(t, s.UpdateStateDesc("iID", "opID", internal.OperationStateFailed, ptrStr("release failed")))
+
+	// THEN
+	assert.Equal(t, []audit.Event{
+		{Time: fixTime, InstanceID: "iID", OperationID: "opID", OperationType: internal.OperationTypeCreate, State: internal.OperationStateInProgress, Username: "admin", UID: "123"},
+		{Time: fixTime, InstanceID: "iID", OperationID: "opID", OperationType: internal.OperationTypeCreate, State: internal.OperationStateFailed, Description: "release failed", Username: "admin", UID: "123"},
+	}, decodeEvents(t, buf))
+}
+
+func TestBindOperationStorageWritesEventOnStateTransition(t *testing.T) {
+	// GIVEN
+	buf := &bytes.Buffer{}
+	sink := audit.NewJSONLinesSink(buf, nil)
+	s := audit.NewBindOperationStorage(memory.NewBindOperation(), sink, spy.NewLogSink().Logger)
+
+	// WHEN
+	require.NoError(t, s.Insert(&internal.BindOperation{
+		InstanceID:  "iID",
+		BindingID:   "bID",
+		OperationID: "opID",
+		Type:        internal.OperationTypeCreate,
+		State:       internal.OperationStateInProgress,
+		RequestedBy: internal.OperationRequester{Username: "john"},
+	}))
+	require.NoError(t, s.UpdateState("iID", "bID", "opID", internal.OperationStateSucceeded))
+	err := s.UpdateState("iID", "bID", "not-existing", internal.OperationStateSucceeded)
+
+	// THEN
+	assert.Error(t, err)
+	events := decodeEvents(t, buf)
+	require.Len(t, events, 2, "failed update must not be recorded")
+	assert.Equal(t, internal.BindingID("bID"), events[1].BindingID)
+	assert.Equal(t, internal.OperationStateSucceeded, events[1].State)
+	assert.Equal(t, "john", events[1].Username)
+	assert.False(t, events[1].Time.IsZero())
+}
+
+func decodeEvents(t *testing.T, buf *bytes.Buffer) []audit.Event {
+	var events []audit.Event
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var event audit.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func ptrStr(s string) *string {
+	return &s
+}
@@ -0,0 +1,57 @@
+package bind
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+// restrictToNamespace returns the copy of the config which allows only for the API discovery
+// and reading objects from the given namespace
+func restrictToNamespace(config *rest.Config, namespace internal.Namespace) *rest.Config {
+	out := rest.CopyConfig(config)
+	out.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &namespaceRestrictedTransport{namespace: string(namespace), next: rt}
+	})
+	return out
+}
+
+type namespaceRestrictedTransport struct {
+	namespace string
+	next      http.RoundTripper
+}
+
+func (t *namespaceRestrictedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isAllowedLookupRequest(req, t.namespace) {
+		return nil, errors.Errorf("lookup of %s is not allowed, only objects from the namespace %q can be read", req.URL.Path, t.namespace)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// isAllowedLookupRequest returns true for the GET request of the API discovery, e.g. /api/v1 or /apis/apps/v1,
+// or of objects from the namespace, e.g. /apis/networking.k8s.io/v1/namespaces/{namespace}/ingresses/{name}
+func isAllowedLookupRequest(req *http.Request, namespace string) bool {
+	if req.Method != http.MethodGet || namespace == "" {
+		return false
+	}
+
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	var resourcePath []string
+	switch {
+	case segments[0] == "api" && len(segments) <= 2, segments[0] == "apis" && len(segments) <= 3:
+		return true
+	case segments[0] == "api":
+		resourcePath = segments[2:]
+	case segments[0] == "apis":
+		resourcePath = segments[3:]
+	default:
+		return false
+	}
+
+	// the namespaced resource path is namespaces/{namespace}/{resource}[/{name}]
+	return len(resourcePath) >= 3 && len(resourcePath) <= 4 && resourcePath[0] == "namespaces" && resourcePath[1] == namespace
+}
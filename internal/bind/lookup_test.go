@@ -0,0 +1,121 @@
+package bind_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/client-go/rest"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/bind"
+)
+
+func TestIsAllowedLookupRequest(t *testing.T) {
+	for path, exp := range map[string]bool{
+		"/api":                       true,
+		"/api/v1":                    true,
+		"/apis":                      true,
+		"/apis/networking.k8s.io/v1": true,
+		"/api/v1/namespaces/test-ns/secrets/redis":                true,
+		"/api/v1/namespaces/test-ns/secrets":                      true,
+		"/apis/networking.k8s.io/v1/namespaces/test-ns/ingresses": true,
+		"/api/v1/namespaces/kube-system/secrets/x":                false,
+		"/api/v1/secrets":                                 false,
+		"/api/v1/namespaces/test-ns":                      false,
+		"/api/v1/nodes/node-1":                            false,
+		"/apis/rbac.authorization.k8s.io/v1/clusterroles": false,
+		"/version": false,
+	} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			assert.Equal(t, exp, bind.IsAllowedLookupRequest(req, "test-ns"))
+		})
+	}
+
+	t.Run("write request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/namespaces/test-ns/secrets/redis", nil)
+		assert.False(t, bind.IsAllowedLookupRequest(req, "test-ns"))
+	})
+}
+
+func TestRendererLookupIsLimitedToInstanceNamespace(t *testing.T) {
+	// given
+	apiServer := newFakeAPIServer()
+	defer apiServer.Close()
+
+	renderer := bind.NewRenderer().WithNamespacedLookup(&rest.Config{Host: apiServer.URL})
+	instance := fixInstance()
+
+	newChart := func() *chart.Chart {
+		return &chart.Chart{Metadata: &chart.Metadata{Name: "test-chart", APIVersion: chart.APIVersionV2, Version: "0.1.0"}}
+	}
+
+	t.Run("bind template has no cluster access", func(t *testing.T) {
+		// when
+		out, err := renderer.Render(internal.AddonPlanBindTemplate(`secret: {{ (lookup "v1" "Secret" "kube-system" "x").data }}`), &instance, fixBindingID, nil, newChart())
+
+		// then
+		require.NoError(t, err)
+		assert.EqualValues(t, "secret: ", out)
+		assert.Empty(t, apiServer.ObjectRequests())
+	})
+
+	t.Run("dashboard template reads object from instance namespace", func(t *testing.T) {
+		// when
+		out, err := renderer.RenderDashboardURL(internal.AddonPlanDashboardURLTemplate(`https://{{ (lookup "v1" "Secret" .Release.Namespace "redis").metadata.name }}`), &instance, newChart())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "https://redis", out)
+	})
+
+	t.Run("dashboard template cannot read object from other namespace", func(t *testing.T) {
+		// when
+		_, err := renderer.RenderDashboardURL(internal.AddonPlanDashboardURLTemplate(`{{ (lookup "v1" "Secret" "kube-system" "x").data }}`), &instance, newChart())
+
+		// then
+		assert.Error(t, err)
+		assert.NotContains(t, apiServer.ObjectRequests(), "/api/v1/namespaces/kube-system/secrets/x")
+	})
+}
+
+// fakeAPIServer serves the discovery of the core API with Secrets and returns every requested Secret
+type fakeAPIServer struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	objectRequests []string
+}
+
+func newFakeAPIServer() *fakeAPIServer {
+	s := &fakeAPIServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			fmt.Fprint(w, `{"kind": "APIVersions", "versions": ["v1"]}`)
+		case "/apis":
+			fmt.Fprint(w, `{"kind": "APIGroupList", "groups": []}`)
+		case "/api/v1":
+			fmt.Fprint(w, `{"kind": "APIResourceList", "groupVersion": "v1", "resources": [{"name": "secrets", "kind": "Secret", "namespaced": true, "verbs": ["get", "list"]}]}`)
+		default:
+			s.mu.Lock()
+			s.objectRequests = append(s.objectRequests, r.URL.Path)
+			s.mu.Unlock()
+			fmt.Fprint(w, `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "redis"}}`)
+		}
+	}))
+	return s
+}
+
+func (s *fakeAPIServer) ObjectRequests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.objectRequests...)
+}
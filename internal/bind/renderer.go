@@ -2,16 +2,24 @@ package bind
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/kyma-project/helm-broker/internal"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/engine"
+	"k8s.io/client-go/rest"
 )
 
 const (
-	bindFile = "bindTmpl"
+	bindFile         = "bindTmpl"
+	dashboardURLFile = "dashboardURLTmpl"
+
+	// bindingValuesKey is the key under which service binding data are available in the bind template values,
+	// e.g. {{ .Values.serviceBinding.id }} or {{ .Values.serviceBinding.parameters.username }}
+	bindingValuesKey = "serviceBinding"
 )
 
 //go:generate mockery -name=chartGoTemplateRenderer -output=automock -outpkg=automock -case=underscore
@@ -25,6 +33,9 @@ type toRenderValuesCaps func(*chart.Chart, map[string]interface{}, chartutil.Rel
 type Renderer struct {
 	renderEngine       chartGoTemplateRenderer
 	toRenderValuesCaps toRenderValuesCaps
+	// dashboardEngine returns the engine which renders the dashboard URL of the instance from given namespace,
+	// the renderEngine is used when it is not set
+	dashboardEngine func(namespace internal.Namespace) chartGoTemplateRenderer
 }
 
 // NewRenderer creates new instance of Renderer.
@@ -35,8 +46,19 @@ func NewRenderer() *Renderer {
 	}
 }
 
+// WithNamespacedLookup enables the helm lookup function in the dashboard URL templates, so they can read objects
+// from the namespace of the instance, e.g. the host of the Ingress created by the release.
+// The bind templates are always rendered without the access to the cluster.
+func (r *Renderer) WithNamespacedLookup(config *rest.Config) *Renderer {
+	r.dashboardEngine = func(namespace internal.Namespace) chartGoTemplateRenderer {
+		return &clusterAccessEngine{config: restrictToNamespace(config, namespace)}
+	}
+	return r
+}
+
 // Render renders given bindTemplate in context of helm Chart by e.g. replacing directives like: {{ .Release.Namespace }}
-func (r *Renderer) Render(bindTemplate internal.AddonPlanBindTemplate, instance *internal.Instance, ch *chart.Chart) (RenderedBindYAML, error) {
+// The service binding ID and bind parameters are exposed as {{ .Values.serviceBinding.id }} and {{ .Values.serviceBinding.parameters }}.
+func (r *Renderer) Render(bindTemplate internal.AddonPlanBindTemplate, instance *internal.Instance, bindingID internal.BindingID, bindParams map[string]interface{}, ch *chart.Chart) (RenderedBindYAML, error) {
 
 	options := r.createReleaseOptions(instance)
 	chartCap := &chartutil.Capabilities{}
@@ -46,6 +68,22 @@ func (r *Renderer) Render(bindTemplate internal.AddonPlanBindTemplate, instance
 		return nil, errors.Wrap(err, "while merging values to render")
 	}
 
+	if bindParams == nil {
+		bindParams = map[string]interface{}{}
+	}
+	// copy chart values, so the release config values are not modified
+	values := chartutil.Values{}
+	if chrtVals, err := valsToRender.Table("Values"); err == nil {
+		for k, v := range chrtVals {
+			values[k] = v
+		}
+	}
+	values[bindingValuesKey] = map[string]interface{}{
+		"id":         string(bindingID),
+		"parameters": bindParams,
+	}
+	valsToRender["Values"] = values
+
 	ch.Templates = append(ch.Templates, &chart.File{Name: bindFile, Data: bindTemplate})
 
 	files, err := r.renderEngine.Render(ch, valsToRender)
@@ -69,3 +107,50 @@ func (*Renderer) createReleaseOptions(instance *internal.Instance) chartutil.Rel
 		IsInstall: true,
 	}
 }
+
+// RenderDashboardURL renders given dashboard URL template in context of helm Chart and the release values of the instance,
+// e.g. http://{{ .Values.ingress.host }}. Only the chart partials, like _helpers.tpl, are rendered together with the template.
+func (r *Renderer) RenderDashboardURL(dashboardURLTemplate internal.AddonPlanDashboardURLTemplate, instance *internal.Instance, ch *chart.Chart) (string, error) {
+	// the chart from the storage is not modified and the release manifests are not rendered
+	dashboardChart := &chart.Chart{
+		Metadata: ch.Metadata,
+		Values:   ch.Values,
+	}
+	for _, tpl := range ch.Templates {
+		if strings.HasPrefix(path.Base(tpl.Name), "_") {
+			dashboardChart.Templates = append(dashboardChart.Templates, tpl)
+		}
+	}
+	dashboardChart.Templates = append(dashboardChart.Templates, &chart.File{Name: dashboardURLFile, Data: dashboardURLTemplate})
+
+	valsToRender, err := r.toRenderValuesCaps(dashboardChart, instance.ReleaseInfo.ConfigValues, r.createReleaseOptions(instance), &chartutil.Capabilities{})
+	if err != nil {
+		return "", errors.Wrap(err, "while merging values to render")
+	}
+
+	renderEngine := r.renderEngine
+	if r.dashboardEngine != nil {
+		renderEngine = r.dashboardEngine(instance.Namespace)
+	}
+
+	files, err := renderEngine.Render(dashboardChart, valsToRender)
+	if err != nil {
+		return "", errors.Wrap(err, "while rendering files")
+	}
+
+	rendered, exists := files[fmt.Sprintf("%s/%s", ch.Metadata.Name, dashboardURLFile)]
+	if !exists {
+		return "", fmt.Errorf("%v file was not resolved after rendering", dashboardURLFile)
+	}
+
+	return strings.TrimSpace(rendered), nil
+}
+
+// clusterAccessEngine renders templates with the lookup function backed by the cluster
+type clusterAccessEngine struct {
+	config *rest.Config
+}
+
+func (e *clusterAccessEngine) Render(ch *chart.Chart, values chartutil.Values) (map[string]string, error) {
+	return engine.RenderWithClient(ch, values, e.config)
+}
@@ -46,7 +46,7 @@ func ExampleNewRenderer() {
 	rendered, err := bindTmplRenderer.Render(internal.AddonPlanBindTemplate(b), &internal.Instance{
 		Namespace:   "ns-name",
 		ReleaseName: internal.ReleaseName(resp.Name),
-	}, ch)
+	}, internal.BindingID("binding-id"), nil, ch)
 	fatalOnErr(err)
 
 	fmt.Println(string(rendered))
@@ -1,8 +1,14 @@
 package bind
 
+import "net/http"
+
 func NewRendererWithDeps(renderEngine chartGoTemplateRenderer, toRenderValuesCaps toRenderValuesCaps) *Renderer {
 	return &Renderer{
 		renderEngine:       renderEngine,
 		toRenderValuesCaps: toRenderValuesCaps,
 	}
 }
+
+func IsAllowedLookupRequest(req *http.Request, namespace string) bool {
+	return isAllowedLookupRequest(req, namespace)
+}
@@ -28,14 +28,14 @@ func TestRenderSuccess(t *testing.T) {
 
 	engineRenderMock := &automock.ChartGoTemplateRenderer{}
 	defer engineRenderMock.AssertExpectations(t)
-	engineRenderMock.On("Render", mock.MatchedBy(chartWithTpl(t, tplToRender)), fixChartutilValues()).
+	engineRenderMock.On("Render", mock.MatchedBy(chartWithTpl(t, tplToRender)), fixValuesToRender()).
 		Return(fixRenderOutFiles, nil)
 
 	toRenderFake := toRenderValuesFake{t}.WithInputAssertion(fixChart())
 	renderer := bind.NewRendererWithDeps(engineRenderMock, toRenderFake)
 
 	// when
-	out, err := renderer.Render(tplToRender, &fixedInstance, &fixedChart)
+	out, err := renderer.Render(tplToRender, &fixedInstance, fixBindingID, fixBindParams(), &fixedChart)
 
 	// then
 	require.NoError(t, err)
@@ -53,7 +53,7 @@ func TestRenderFailureOnCreatingToRenderValues(t *testing.T) {
 	renderer := bind.NewRendererWithDeps(nil, toRenderFake)
 
 	// when
-	out, err := renderer.Render(tplToRender, &fixedInstance, &fixedChart)
+	out, err := renderer.Render(tplToRender, &fixedInstance, fixBindingID, fixBindParams(), &fixedChart)
 
 	// then
 	require.EqualError(t, err, "while merging values to render: fix err")
@@ -71,13 +71,13 @@ func TestRenderFailureOnEngineRender(t *testing.T) {
 
 	engineRenderMock := &automock.ChartGoTemplateRenderer{}
 	defer engineRenderMock.AssertExpectations(t)
-	engineRenderMock.On("Render", mock.MatchedBy(chartWithTpl(t, tplToRender)), fixChartutilValues()).
+	engineRenderMock.On("Render", mock.MatchedBy(chartWithTpl(t, tplToRender)), fixValuesToRender()).
 		Return(nil, fixErr)
 
 	renderer := bind.NewRendererWithDeps(engineRenderMock, toRenderFake)
 
 	// when
-	out, err := renderer.Render(tplToRender, &fixedInstance, &fixedChart)
+	out, err := renderer.Render(tplToRender, &fixedInstance, fixBindingID, fixBindParams(), &fixedChart)
 
 	// then
 	assert.EqualError(t, err, fmt.Sprintf("while rendering files: %s", fixErr))
@@ -92,20 +92,85 @@ func TestRenderFailureOnExtractingResolveBindFile(t *testing.T) {
 
 	engineRenderMock := &automock.ChartGoTemplateRenderer{}
 	defer engineRenderMock.AssertExpectations(t)
-	engineRenderMock.On("Render", mock.MatchedBy(chartWithTpl(t, tplToRender)), fixChartutilValues()).
+	engineRenderMock.On("Render", mock.MatchedBy(chartWithTpl(t, tplToRender)), fixValuesToRender()).
 		Return(map[string]string{}, nil)
 
 	toRenderFake := toRenderValuesFake{t}.WithInputAssertion(fixChart())
 	renderer := bind.NewRendererWithDeps(engineRenderMock, toRenderFake)
 
 	// when
-	out, err := renderer.Render(tplToRender, &fixedInstance, &fixedChart)
+	out, err := renderer.Render(tplToRender, &fixedInstance, fixBindingID, fixBindParams(), &fixedChart)
 
 	// then
 	assert.EqualError(t, err, "bindTmpl file was not resolved after rendering")
 	assert.Nil(t, out)
 }
 
+func TestRenderSuccessWithBindingData(t *testing.T) {
+	// given
+	fixedInstance := fixInstance()
+	fixedInstance.ReleaseInfo.ConfigValues = map[string]interface{}{}
+	fixedChart := fixChart()
+	fixedChart.Metadata.APIVersion = chart.APIVersionV2
+	fixedChart.Metadata.Version = "0.1.0"
+	tplToRender := internal.AddonPlanBindTemplate("binding: {{ .Values.serviceBinding.id }}\nuser: {{ .Values.serviceBinding.parameters.user }}")
+
+	renderer := bind.NewRenderer()
+
+	// when
+	out, err := renderer.Render(tplToRender, &fixedInstance, fixBindingID, fixBindParams(), &fixedChart)
+
+	// then
+	require.NoError(t, err)
+	assert.EqualValues(t, "binding: test-binding-id\nuser: fix-user", out)
+}
+
+func TestRenderDashboardURLSuccess(t *testing.T) {
+	// given
+	fixedInstance := fixInstance()
+	fixedInstance.ReleaseInfo.ConfigValues = map[string]interface{}{
+		"ingress": map[string]interface{}{"host": "console.example.com"},
+	}
+	fixedChart := fixChart()
+	fixedChart.Metadata.APIVersion = chart.APIVersionV2
+	fixedChart.Metadata.Version = "0.1.0"
+	fixedChart.Templates = []*chart.File{
+		{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "host" }}{{ .Values.ingress.host }}{{ end }}`)},
+		{Name: "templates/deployment.yaml", Data: []byte(`{{ required "image is required" .Values.image }}`)},
+	}
+	tplToRender := internal.AddonPlanDashboardURLTemplate("https://{{ include \"host\" . }}/{{ .Release.Namespace }}\n")
+
+	renderer := bind.NewRenderer()
+
+	// when
+	out, err := renderer.RenderDashboardURL(tplToRender, &fixedInstance, &fixedChart)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "https://console.example.com/test-ns", out)
+	assert.Len(t, fixedChart.Templates, 2, "the chart must not be modified")
+}
+
+func TestRenderDashboardURLFailureOnEngineRender(t *testing.T) {
+	// given
+	fixedInstance := fixInstance()
+	fixedChart := fixChart()
+	fixErr := errors.New("fix err")
+
+	engineRenderMock := &automock.ChartGoTemplateRenderer{}
+	defer engineRenderMock.AssertExpectations(t)
+	engineRenderMock.On("Render", mock.Anything, fixChartutilValues()).Return(nil, fixErr)
+
+	renderer := bind.NewRendererWithDeps(engineRenderMock, toRenderValuesFake{t}.WithForcedValues())
+
+	// when
+	out, err := renderer.RenderDashboardURL(internal.AddonPlanDashboardURLTemplate("http://{{ .Values.host }}"), &fixedInstance, &fixedChart)
+
+	// then
+	assert.EqualError(t, err, fmt.Sprintf("while rendering files: %s", fixErr))
+	assert.Empty(t, out)
+}
+
 func chartWithTpl(t *testing.T, expTpl internal.AddonPlanBindTemplate) func(*chart.Chart) bool {
 	return func(ch *chart.Chart) bool {
 		assert.Contains(t, ch.Templates, &chart.File{Name: "bindTmpl", Data: expTpl})
@@ -132,6 +197,12 @@ func (r toRenderValuesFake) WithInputAssertion(expChrt chart.Chart) func(*chart.
 	}
 }
 
+func (r toRenderValuesFake) WithForcedValues() func(*chart.Chart, map[string]interface{}, chartutil.ReleaseOptions, *chartutil.Capabilities) (chartutil.Values, error) {
+	return func(chrt *chart.Chart, chrtVals map[string]interface{}, options chartutil.ReleaseOptions, caps *chartutil.Capabilities) (chartutil.Values, error) {
+		return fixChartutilValues(), nil
+	}
+}
+
 func (r toRenderValuesFake) WithForcedError(err error) func(*chart.Chart, map[string]interface{}, chartutil.ReleaseOptions, *chartutil.Capabilities) (chartutil.Values, error) {
 	return func(chrt *chart.Chart, chrtVals map[string]interface{}, options chartutil.ReleaseOptions, caps *chartutil.Capabilities) (chartutil.Values, error) {
 		return nil, err
@@ -142,6 +213,23 @@ func fixChartutilValues() chartutil.Values {
 	return chartutil.Values{"fix_val_key": "fix_val"}
 }
 
+const fixBindingID = internal.BindingID("test-binding-id")
+
+func fixBindParams() map[string]interface{} {
+	return map[string]interface{}{"user": "fix-user"}
+}
+
+func fixValuesToRender() chartutil.Values {
+	vals := fixChartutilValues()
+	vals["Values"] = chartutil.Values{
+		"serviceBinding": map[string]interface{}{
+			"id":         string(fixBindingID),
+			"parameters": fixBindParams(),
+		},
+	}
+	return vals
+}
+
 func fixChart() chart.Chart {
 	return chart.Chart{
 		Metadata: &chart.Metadata{
@@ -0,0 +1,163 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+const adminUserContextKey contextKey = 5002
+
+// adminPathPattern matches the routes of the admin API, e.g. /cluster/admin/service_instances/{instance_id}/import
+var adminPathPattern = regexp.MustCompile(`^/(cluster|ns/[^/]+)/admin/`)
+
+// AdminAuthConfig holds configuration of the authentication of the admin API requests, e.g. the import of helm releases
+type AdminAuthConfig struct {
+	// Enabled exposes the admin API. Requests must contain the bearer token of the Kubernetes user who is allowed
+	// to perform the configured verb on the configured resource in the target namespace.
+	Enabled bool
+	// Verb defines the verb checked by the SubjectAccessReview
+	Verb string `default:"update"`
+	// Group defines the API group of the resource checked by the SubjectAccessReview
+	Group string
+	// Resource defines the resource checked by the SubjectAccessReview, by default the Secrets which store helm releases
+	Resource string `default:"secrets"`
+}
+
+// AdminAuthenticator checks credentials of the request sent to the admin API.
+// Nil user is returned when the request is not authenticated.
+type AdminAuthenticator interface {
+	AuthenticateAdmin(r *http.Request) (*OriginatingUser, error)
+}
+
+// NewAdminAuth returns the authenticator and the authorizer of the admin API users. Nils are returned when the admin API is disabled.
+func NewAdminAuth(cfg AdminAuthConfig, tokenReviewer authenticationv1client.TokenReviewInterface, sarClient authorizationv1client.SubjectAccessReviewInterface) (AdminAuthenticator, Authorizer) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	// admin requests are rare, so the decisions are not cached
+	authz := NewSubjectAccessReviewAuthorizer(AuthzConfig{
+		Verb:     cfg.Verb,
+		Group:    cfg.Group,
+		Resource: cfg.Resource,
+	}, sarClient, time.Now)
+	return NewTokenReviewAdminAuthenticator(tokenReviewer), authz
+}
+
+// TokenReviewAdminAuthenticator returns the Kubernetes user of the bearer token validated by the TokenReview
+type TokenReviewAdminAuthenticator struct {
+	tokenReviewer authenticationv1client.TokenReviewInterface
+}
+
+// NewTokenReviewAdminAuthenticator returns authenticator which validates bearer tokens in the Kubernetes API server
+func NewTokenReviewAdminAuthenticator(tokenReviewer authenticationv1client.TokenReviewInterface) *TokenReviewAdminAuthenticator {
+	return &TokenReviewAdminAuthenticator{
+		tokenReviewer: tokenReviewer,
+	}
+}
+
+// AuthenticateAdmin creates the TokenReview for the request bearer token
+func (a *TokenReviewAdminAuthenticator) AuthenticateAdmin(r *http.Request) (*OriginatingUser, error) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return nil, nil
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if token == "" {
+		return nil, nil
+	}
+
+	review, err := a.tokenReviewer.Create(context.Background(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "while creating TokenReview")
+	}
+	if !review.Status.Authenticated {
+		return nil, nil
+	}
+
+	extra := map[string][]string{}
+	for k, v := range review.Status.User.Extra {
+		extra[k] = v
+	}
+	return &OriginatingUser{
+		Username: review.Status.User.Username,
+		UID:      review.Status.User.UID,
+		Groups:   review.Status.User.Groups,
+		Extra:    extra,
+	}, nil
+}
+
+// AdminAuthMiddleware rejects requests which are not authenticated by the admin authenticator
+// and passes the authenticated user to the next handler in the request context
+type AdminAuthMiddleware struct {
+	authenticator AdminAuthenticator
+	log           logrus.FieldLogger
+}
+
+// NewAdminAuthMiddleware returns middleware which authenticates requests with the given admin authenticator
+func NewAdminAuthMiddleware(authenticator AdminAuthenticator, log logrus.FieldLogger) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{
+		authenticator: authenticator,
+		log:           log,
+	}
+}
+
+// ServeHTTP passes only authenticated requests to the next handler
+func (m *AdminAuthMiddleware) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	user, err := m.authenticator.AuthenticateAdmin(r)
+	if err != nil {
+		m.log.Errorf("Cannot authenticate admin request %s %s: %v", r.Method, r.URL.Path, err)
+		writeErrorResponse(rw, http.StatusInternalServerError, "AuthenticationError", "Cannot authenticate the request")
+		return
+	}
+	if user == nil {
+		writeErrorResponse(rw, http.StatusUnauthorized, "Unauthorized", "Request requires the valid bearer token of the Kubernetes user")
+		return
+	}
+
+	next(rw, r.WithContext(context.WithValue(r.Context(), adminUserContextKey, *user)))
+}
+
+func adminUserFromContext(ctx context.Context) (OriginatingUser, bool) {
+	user, ok := ctx.Value(adminUserContextKey).(OriginatingUser)
+	return user, ok
+}
+
+// authorizeAdmin checks if the admin user is allowed to import releases from the namespace
+func authorizeAdmin(authz Authorizer, user OriginatingUser, namespace internal.Namespace) *osb.HTTPStatusCodeError {
+	allowed, reason, err := authz.Authorize(user, namespace)
+	switch {
+	case err != nil:
+		return &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while authorizing user %q: %v", user.Username, err))}
+	case !allowed:
+		desc := fmt.Sprintf("User %q is not allowed to import releases from the namespace %q", user.Username, namespace)
+		if reason != "" {
+			desc = fmt.Sprintf("%s: %s", desc, reason)
+		}
+		return &osb.HTTPStatusCodeError{StatusCode: http.StatusForbidden, ErrorMessage: strPtr("Forbidden"), Description: strPtr(desc)}
+	}
+	return nil
+}
+
+// isAdminRequest returns true for requests sent to the admin API, which are authenticated separately from the OSB API
+func isAdminRequest(r *http.Request) bool {
+	return adminPathPattern.MatchString(r.URL.Path)
+}
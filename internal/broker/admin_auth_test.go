@@ -0,0 +1,61 @@
+package broker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kyma-project/helm-broker/internal/broker"
+)
+
+func TestTokenReviewAdminAuthenticator(t *testing.T) {
+	// GIVEN
+	cli := fake.NewSimpleClientset()
+	cli.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "admin-token" {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: "admin", UID: "123", Groups: []string{"system:masters"}}
+		}
+		return true, review, nil
+	})
+	authenticator := broker.NewTokenReviewAdminAuthenticator(cli.AuthenticationV1().TokenReviews())
+
+	for tn, tc := range map[string]struct {
+		header  string
+		expUser *broker.OriginatingUser
+	}{
+		"valid token": {
+			header:  "Bearer admin-token",
+			expUser: &broker.OriginatingUser{Username: "admin", UID: "123", Groups: []string{"system:masters"}, Extra: map[string][]string{}},
+		},
+		"invalid token": {
+			header: "Bearer invalid-token",
+		},
+		"basic credentials": {
+			header: "Basic YWRtaW46cGFzcw==",
+		},
+		"missing header": {},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/cluster/admin/service_instances/inst/import", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			// WHEN
+			user, err := authenticator.AuthenticateAdmin(req)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expUser, user)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package broker
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/platform/nsauth"
+)
+
+// AuthType defines how requests sent to the OSB API are authenticated
+type AuthType string
+
+const (
+	// AuthTypeNone disables the authentication
+	AuthTypeNone AuthType = ""
+	// AuthTypeBasic authenticates requests with static basic auth credentials
+	AuthTypeBasic AuthType = "basic"
+	// AuthTypeBearer authenticates requests with bearer tokens validated by the Kubernetes TokenReview
+	AuthTypeBearer AuthType = "bearer"
+
+	basicAuthUsernameKey = "username"
+	basicAuthPasswordKey = "password"
+)
+
+// AuthConfig holds configuration of the authentication of the OSB API requests
+type AuthConfig struct {
+	// Type defines the authentication type, one of: basic, bearer. The authentication is disabled when it is not set.
+	Type AuthType
+	// BasicSecretPath defines the directory with the mounted Secret which holds the username and password keys
+	BasicSecretPath string `default:"/etc/helm-broker/auth"`
+	// BearerAllowedUsers defines the users, e.g. the ServiceAccount of the Service Catalog controller, whose tokens
+	// are accepted on all routes. Tokens of the namespaced broker ServiceAccounts are accepted only on the routes of their namespace.
+	BearerAllowedUsers []string
+}
+
+// Authenticator checks credentials of the request sent to the OSB API. The returned namespace is not empty
+// when the credentials were issued for the ServiceBroker of that namespace and are valid only for its routes.
+type Authenticator interface {
+	Authenticate(r *http.Request) (bool, internal.Namespace, error)
+}
+
+// NewAuthenticator returns the authenticator for the configured type. Nil is returned when the authentication is disabled.
+func NewAuthenticator(cfg AuthConfig, tokenReviewer authenticationv1client.TokenReviewInterface) (Authenticator, error) {
+	switch cfg.Type {
+	case AuthTypeNone:
+		return nil, nil
+	case AuthTypeBasic:
+		authenticator, err := NewBasicAuthenticator(cfg.BasicSecretPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "while creating basic authenticator")
+		}
+		return authenticator, nil
+	case AuthTypeBearer:
+		if len(cfg.BearerAllowedUsers) == 0 {
+			return nil, errors.New("bearer authentication requires at least one allowed user")
+		}
+		return NewTokenReviewAuthenticator(tokenReviewer, cfg.BearerAllowedUsers), nil
+	default:
+		return nil, errors.Errorf("unknown authentication type %q, should be one of: %s, %s", cfg.Type, AuthTypeBasic, AuthTypeBearer)
+	}
+}
+
+// BasicAuthenticator checks if the request contains the basic auth credentials loaded from the mounted Secret
+type BasicAuthenticator struct {
+	username []byte
+	password []byte
+}
+
+// NewBasicAuthenticator loads credentials from the username and password files in the given directory
+func NewBasicAuthenticator(secretPath string) (*BasicAuthenticator, error) {
+	username, err := ioutil.ReadFile(filepath.Join(secretPath, basicAuthUsernameKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "while reading basic auth username")
+	}
+	password, err := ioutil.ReadFile(filepath.Join(secretPath, basicAuthPasswordKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "while reading basic auth password")
+	}
+	if len(username) == 0 || len(password) == 0 {
+		return nil, errors.Errorf("basic auth username and password in %s must not be empty", secretPath)
+	}
+
+	return &BasicAuthenticator{
+		username: username,
+		password: password,
+	}, nil
+}
+
+// Authenticate compares the request credentials with the loaded ones or with the credentials derived from them
+// for the namespace given in the username
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (bool, internal.Namespace, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false, "", nil
+	}
+	if credentialsMatch(username, password, a.username, a.password) {
+		return true, "", nil
+	}
+
+	namespace, scoped := nsauth.NamespaceFromBasicUsername(username)
+	if !scoped {
+		return false, "", nil
+	}
+	nsUsername, nsPassword := nsauth.BasicCredentials(a.username, a.password, namespace)
+	if !credentialsMatch(username, password, nsUsername, nsPassword) {
+		return false, "", nil
+	}
+	return true, internal.Namespace(namespace), nil
+}
+
+func credentialsMatch(username, password string, expUsername, expPassword []byte) bool {
+	// both values are always compared to not reveal which one is invalid
+	usernameMatch := subtle.ConstantTimeCompare([]byte(username), expUsername)
+	passwordMatch := subtle.ConstantTimeCompare([]byte(password), expPassword)
+	return usernameMatch&passwordMatch == 1
+}
+
+// TokenReviewAuthenticator checks if the request contains the bearer token accepted by the Kubernetes TokenReview
+// and issued for the allowed user or for the ServiceAccount of a namespaced ServiceBroker
+type TokenReviewAuthenticator struct {
+	tokenReviewer authenticationv1client.TokenReviewInterface
+	allowedUsers  map[string]struct{}
+}
+
+// NewTokenReviewAuthenticator returns authenticator which validates bearer tokens in the Kubernetes API server
+func NewTokenReviewAuthenticator(tokenReviewer authenticationv1client.TokenReviewInterface, allowedUsers []string) *TokenReviewAuthenticator {
+	users := make(map[string]struct{}, len(allowedUsers))
+	for _, u := range allowedUsers {
+		users[strings.TrimSpace(u)] = struct{}{}
+	}
+	return &TokenReviewAuthenticator{
+		tokenReviewer: tokenReviewer,
+		allowedUsers:  users,
+	}
+}
+
+// Authenticate creates the TokenReview for the request bearer token. Tokens of the ServiceAccounts created
+// for the namespaced ServiceBrokers are valid only for the broker of their namespace, tokens of the allowed users
+// are valid for all brokers. Tokens of all other users, e.g. the default ServiceAccounts of pods, are rejected.
+func (a *TokenReviewAuthenticator) Authenticate(r *http.Request) (bool, internal.Namespace, error) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false, "", nil
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if token == "" {
+		return false, "", nil
+	}
+
+	review, err := a.tokenReviewer.Create(context.Background(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", errors.Wrap(err, "while creating TokenReview")
+	}
+	if !review.Status.Authenticated {
+		return false, "", nil
+	}
+	username := review.Status.User.Username
+	if namespace, scoped := nsauth.NamespaceFromServiceAccount(username); scoped {
+		return true, internal.Namespace(namespace), nil
+	}
+	if _, allowed := a.allowedUsers[username]; allowed {
+		return true, "", nil
+	}
+	return false, "", nil
+}
+
+// AuthMiddleware rejects requests which are not authenticated by the authenticator
+type AuthMiddleware struct {
+	authenticator Authenticator
+	log           logrus.FieldLogger
+}
+
+// NewAuthMiddleware returns middleware which authenticates requests with the given authenticator
+func NewAuthMiddleware(authenticator Authenticator, log logrus.FieldLogger) *AuthMiddleware {
+	return &AuthMiddleware{
+		authenticator: authenticator,
+		log:           log,
+	}
+}
+
+// ServeHTTP passes only authenticated requests to the next handler. Requests with credentials issued for
+// the ServiceBroker of a namespace are passed only to the routes of that namespace.
+func (m *AuthMiddleware) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	authenticated, namespace, err := m.authenticator.Authenticate(r)
+	if err != nil {
+		m.log.Errorf("Cannot authenticate request %s %s: %v", r.Method, r.URL.Path, err)
+		writeErrorResponse(rw, http.StatusInternalServerError, "AuthenticationError", "Cannot authenticate the request")
+		return
+	}
+	if !authenticated {
+		writeErrorResponse(rw, http.StatusUnauthorized, "Unauthorized", "Request requires valid credentials")
+		return
+	}
+	if namespace != "" && !strings.HasPrefix(r.URL.Path, fmt.Sprintf("/ns/%s/", namespace)) {
+		writeErrorResponse(rw, http.StatusForbidden, "Forbidden", fmt.Sprintf("Credentials are valid only for the broker of the namespace %q", namespace))
+		return
+	}
+
+	next(rw, r)
+}
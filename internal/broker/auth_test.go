@@ -0,0 +1,269 @@
+package broker_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
+	"github.com/kyma-project/helm-broker/internal/platform/nsauth"
+)
+
+func TestBasicAuthenticator(t *testing.T) {
+	// GIVEN
+	secretPath, err := ioutil.TempDir("", "auth")
+	require.NoError(t, err)
+	defer os.RemoveAll(secretPath)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(secretPath, "username"), []byte("admin"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(secretPath, "password"), []byte("pass"), 0600))
+
+	authenticator, err := broker.NewAuthenticator(broker.AuthConfig{Type: broker.AuthTypeBasic, BasicSecretPath: secretPath}, nil)
+	require.NoError(t, err)
+
+	nsUsername, nsPassword := nsauth.BasicCredentials([]byte("admin"), []byte("pass"), "stage")
+
+	for tn, tc := range map[string]struct {
+		setAuth          func(r *http.Request)
+		expAuthenticated bool
+		expNamespace     internal.Namespace
+	}{
+		"valid credentials": {
+			setAuth:          func(r *http.Request) { r.SetBasicAuth("admin", "pass") },
+			expAuthenticated: true,
+		},
+		"valid namespace credentials": {
+			setAuth:          func(r *http.Request) { r.SetBasicAuth(string(nsUsername), string(nsPassword)) },
+			expAuthenticated: true,
+			expNamespace:     "stage",
+		},
+		"namespace credentials used for other namespace": {
+			setAuth:          func(r *http.Request) { r.SetBasicAuth("admin@prod", string(nsPassword)) },
+			expAuthenticated: false,
+		},
+		"broker password used for namespace": {
+			setAuth:          func(r *http.Request) { r.SetBasicAuth("admin@stage", "pass") },
+			expAuthenticated: false,
+		},
+		"invalid password": {
+			setAuth:          func(r *http.Request) { r.SetBasicAuth("admin", "wrong") },
+			expAuthenticated: false,
+		},
+		"invalid username": {
+			setAuth:          func(r *http.Request) { r.SetBasicAuth("root", "pass") },
+			expAuthenticated: false,
+		},
+		"missing credentials": {
+			setAuth:          func(r *http.Request) {},
+			expAuthenticated: false,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/cluster/v2/catalog", nil)
+			tc.setAuth(req)
+
+			// WHEN
+			authenticated, namespace, err := authenticator.Authenticate(req)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expAuthenticated, authenticated)
+			assert.Equal(t, tc.expNamespace, namespace)
+		})
+	}
+}
+
+func TestBasicAuthenticatorOnMissingSecret(t *testing.T) {
+	// WHEN
+	_, err := broker.NewAuthenticator(broker.AuthConfig{Type: broker.AuthTypeBasic, BasicSecretPath: "/not/existing"}, nil)
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	// GIVEN
+	authenticator := broker.NewTokenReviewAuthenticator(fixTokenReviewer(), []string{fixAllowedUser})
+
+	for tn, tc := range map[string]struct {
+		header           string
+		expAuthenticated bool
+		expNamespace     internal.Namespace
+	}{
+		"valid token": {
+			header:           "Bearer valid-token",
+			expAuthenticated: true,
+		},
+		"namespace token": {
+			header:           "Bearer stage-token",
+			expAuthenticated: true,
+			expNamespace:     "stage",
+		},
+		"invalid token": {
+			header:           "Bearer invalid-token",
+			expAuthenticated: false,
+		},
+		"token of not allowed service account": {
+			header:           "Bearer default-token",
+			expAuthenticated: false,
+		},
+		"basic credentials": {
+			header:           "Basic YWRtaW46cGFzcw==",
+			expAuthenticated: false,
+		},
+		"missing header": {
+			expAuthenticated: false,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/cluster/v2/catalog", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			// WHEN
+			authenticated, namespace, err := authenticator.Authenticate(req)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expAuthenticated, authenticated)
+			assert.Equal(t, tc.expNamespace, namespace)
+		})
+	}
+}
+
+func TestAuthMiddlewareRejectsTokenOfNotAllowedServiceAccount(t *testing.T) {
+	// GIVEN
+	authenticator, err := broker.NewAuthenticator(broker.AuthConfig{Type: broker.AuthTypeBearer, BearerAllowedUsers: []string{fixAllowedUser}}, fixTokenReviewer())
+	require.NoError(t, err)
+	middleware := broker.NewAuthMiddleware(authenticator, spy.NewLogDummy())
+
+	for _, path := range []string{"/cluster/v2/catalog", "/ns/stage/v2/catalog"} {
+		t.Run(path, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", "Bearer default-token")
+
+			// WHEN
+			middleware.ServeHTTP(rw, req, func(http.ResponseWriter, *http.Request) {
+				t.Error("next handler called for not authenticated request")
+			})
+
+			// THEN
+			assert.Equal(t, http.StatusUnauthorized, rw.Code)
+		})
+	}
+}
+
+func TestNewAuthenticatorBearerRequiresAllowedUsers(t *testing.T) {
+	// WHEN
+	_, err := broker.NewAuthenticator(broker.AuthConfig{Type: broker.AuthTypeBearer}, fixTokenReviewer())
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		authenticator broker.Authenticator
+		path          string
+		expStatusCode int
+		expNextCalled bool
+	}{
+		"authenticated": {
+			authenticator: fakeAuthenticator{authenticated: true},
+			expStatusCode: http.StatusOK,
+			expNextCalled: true,
+		},
+		"not authenticated": {
+			authenticator: fakeAuthenticator{authenticated: false},
+			expStatusCode: http.StatusUnauthorized,
+		},
+		"authentication error": {
+			authenticator: fakeAuthenticator{err: errors.New("fix")},
+			expStatusCode: http.StatusInternalServerError,
+		},
+		"namespace credentials on namespace route": {
+			authenticator: fakeAuthenticator{authenticated: true, namespace: "stage"},
+			path:          "/ns/stage/v2/catalog",
+			expStatusCode: http.StatusOK,
+			expNextCalled: true,
+		},
+		"namespace credentials on other namespace route": {
+			authenticator: fakeAuthenticator{authenticated: true, namespace: "stage"},
+			path:          "/ns/stage-2/v2/catalog",
+			expStatusCode: http.StatusForbidden,
+		},
+		"namespace credentials on cluster route": {
+			authenticator: fakeAuthenticator{authenticated: true, namespace: "stage"},
+			expStatusCode: http.StatusForbidden,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			middleware := broker.NewAuthMiddleware(tc.authenticator, spy.NewLogDummy())
+			rw := httptest.NewRecorder()
+			nextCalled := false
+			path := tc.path
+			if path == "" {
+				path = "/cluster/v2/catalog"
+			}
+
+			// WHEN
+			middleware.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, path, nil), func(http.ResponseWriter, *http.Request) {
+				nextCalled = true
+			})
+
+			// THEN
+			assert.Equal(t, tc.expStatusCode, rw.Code)
+			assert.Equal(t, tc.expNextCalled, nextCalled)
+		})
+	}
+}
+
+type fakeAuthenticator struct {
+	authenticated bool
+	namespace     internal.Namespace
+	err           error
+}
+
+func (a fakeAuthenticator) Authenticate(*http.Request) (bool, internal.Namespace, error) {
+	return a.authenticated, a.namespace, a.err
+}
+
+const fixAllowedUser = "system:serviceaccount:kyma-system:service-catalog-controller"
+
+// fixTokenReviewer authenticates tokens of the allowed user, of the namespaced broker ServiceAccount
+// and of the default ServiceAccount which is not allowed to call the broker
+func fixTokenReviewer() authenticationv1client.TokenReviewInterface {
+	cli := fake.NewSimpleClientset()
+	cli.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case "valid-token":
+			review.Status.Authenticated = true
+			review.Status.User.Username = fixAllowedUser
+		case "stage-token":
+			review.Status.Authenticated = true
+			review.Status.User.Username = "system:serviceaccount:stage:helm-broker-auth"
+		case "default-token":
+			review.Status.Authenticated = true
+			review.Status.User.Username = "system:serviceaccount:stage:default"
+		}
+		return true, review, nil
+	})
+	return cli.AuthenticationV1().TokenReviews()
+}
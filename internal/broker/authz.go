@@ -0,0 +1,199 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	"github.com/kyma-project/helm-broker/internal"
+	yTime "github.com/kyma-project/helm-broker/internal/platform/time"
+)
+
+// AuthzConfig holds configuration of the authorization of the OSB API requests
+type AuthzConfig struct {
+	// Enabled enables checking if the originating identity is allowed to perform the configured verb on the configured resource
+	// in the target namespace before the provisioning, update, deprovisioning and binding
+	Enabled bool
+	// Verb defines the verb checked by the SubjectAccessReview
+	Verb string `default:"create"`
+	// Group defines the API group of the resource checked by the SubjectAccessReview
+	Group string `default:"servicecatalog.k8s.io"`
+	// Resource defines the resource checked by the SubjectAccessReview
+	Resource string `default:"serviceinstances"`
+	// CacheTTL defines how long the SubjectAccessReview result is cached
+	CacheTTL time.Duration `default:"10s"`
+}
+
+// Authorizer checks if the user is allowed to manage service instances in the namespace
+type Authorizer interface {
+	Authorize(user OriginatingUser, namespace internal.Namespace) (allowed bool, reason string, err error)
+}
+
+// NewAuthorizer returns the SubjectAccessReview authorizer. Nil is returned when the authorization is disabled.
+func NewAuthorizer(cfg AuthzConfig, sarClient authorizationv1client.SubjectAccessReviewInterface) Authorizer {
+	if !cfg.Enabled {
+		return nil
+	}
+	return NewSubjectAccessReviewAuthorizer(cfg, sarClient, time.Now)
+}
+
+// SubjectAccessReviewAuthorizer checks permissions of the user with the Kubernetes SubjectAccessReview.
+// Results are cached for the configured time, because the Platform sends many requests on behalf of the same user.
+type SubjectAccessReviewAuthorizer struct {
+	cfg         AuthzConfig
+	sarClient   authorizationv1client.SubjectAccessReviewInterface
+	nowProvider yTime.NowProvider
+
+	mu    sync.Mutex
+	cache map[string]authzDecision
+}
+
+type authzDecision struct {
+	allowed   bool
+	reason    string
+	expiresAt time.Time
+}
+
+// NewSubjectAccessReviewAuthorizer returns authorizer which checks permissions in the Kubernetes API server
+func NewSubjectAccessReviewAuthorizer(cfg AuthzConfig, sarClient authorizationv1client.SubjectAccessReviewInterface, nowProvider yTime.NowProvider) *SubjectAccessReviewAuthorizer {
+	return &SubjectAccessReviewAuthorizer{
+		cfg:         cfg,
+		sarClient:   sarClient,
+		nowProvider: nowProvider,
+		cache:       map[string]authzDecision{},
+	}
+}
+
+// Authorize checks if the user can perform the configured verb on the configured resource in the namespace
+func (a *SubjectAccessReviewAuthorizer) Authorize(user OriginatingUser, namespace internal.Namespace) (bool, string, error) {
+	key, err := a.cacheKey(user, namespace)
+	if err != nil {
+		return false, "", err
+	}
+	if decision, found := a.cached(key); found {
+		return decision.allowed, decision.reason, nil
+	}
+
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, v := range user.Extra {
+		extra[k] = v
+	}
+	review, err := a.sarClient.Create(context.Background(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: string(namespace),
+				Verb:      a.cfg.Verb,
+				Group:     a.cfg.Group,
+				Resource:  a.cfg.Resource,
+			},
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", errors.Wrap(err, "while creating SubjectAccessReview")
+	}
+
+	a.store(key, authzDecision{
+		allowed:   review.Status.Allowed,
+		reason:    review.Status.Reason,
+		expiresAt: a.nowProvider.Now().Add(a.cfg.CacheTTL),
+	})
+	return review.Status.Allowed, review.Status.Reason, nil
+}
+
+func (a *SubjectAccessReviewAuthorizer) cacheKey(user OriginatingUser, namespace internal.Namespace) (string, error) {
+	raw, err := json.Marshal(user)
+	if err != nil {
+		return "", errors.Wrap(err, "while marshalling user")
+	}
+	return fmt.Sprintf("%s/%s", namespace, raw), nil
+}
+
+func (a *SubjectAccessReviewAuthorizer) cached(key string) (authzDecision, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	decision, found := a.cache[key]
+	if !found || !a.nowProvider.Now().Before(decision.expiresAt) {
+		return authzDecision{}, false
+	}
+	return decision, true
+}
+
+func (a *SubjectAccessReviewAuthorizer) store(key string, decision authzDecision) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// expired entries are removed on write, so the cache does not grow with users which stopped sending requests
+	now := a.nowProvider.Now()
+	for k, d := range a.cache {
+		if !now.Before(d.expiresAt) {
+			delete(a.cache, k)
+		}
+	}
+	a.cache[key] = decision
+}
+
+// requestAuthorizer authorizes the originating identity of requests which change service instances or bindings
+type requestAuthorizer struct {
+	authorizer     Authorizer
+	instanceGetter instanceGetter
+}
+
+// authorizeNamespace checks if the originating identity is allowed to manage service instances in the namespace.
+// All requests are allowed when the authorizer is not set.
+func (a *requestAuthorizer) authorizeNamespace(osbCtx OsbContext, namespace internal.Namespace) *osb.HTTPStatusCodeError {
+	if a.authorizer == nil {
+		return nil
+	}
+
+	user, err := osbCtx.originatingUser()
+	switch {
+	case err != nil:
+		return &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting originating identity: %v", err))}
+	case user == nil:
+		return &osb.HTTPStatusCodeError{StatusCode: http.StatusForbidden, ErrorMessage: strPtr("Forbidden"), Description: strPtr("The originating identity is required")}
+	}
+
+	allowed, reason, err := a.authorizer.Authorize(*user, namespace)
+	switch {
+	case err != nil:
+		return &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while authorizing user %q: %v", user.Username, err))}
+	case !allowed:
+		desc := fmt.Sprintf("User %q is not allowed to manage service instances in the namespace %q", user.Username, namespace)
+		if reason != "" {
+			desc = fmt.Sprintf("%s: %s", desc, reason)
+		}
+		return &osb.HTTPStatusCodeError{StatusCode: http.StatusForbidden, ErrorMessage: strPtr("Forbidden"), Description: strPtr(desc)}
+	}
+	return nil
+}
+
+// authorizeInstance checks if the originating identity is allowed to manage service instances in the namespace of the instance.
+// The request for the instance which does not exist is passed to the service which returns the proper response.
+func (a *requestAuthorizer) authorizeInstance(osbCtx OsbContext, iID internal.InstanceID) *osb.HTTPStatusCodeError {
+	if a.authorizer == nil {
+		return nil
+	}
+
+	instance, err := a.instanceGetter.Get(iID)
+	switch {
+	case IsNotFoundError(err):
+		return nil
+	case err != nil:
+		return &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting instance: %v", err))}
+	}
+	return a.authorizeNamespace(osbCtx, instance.Namespace)
+}
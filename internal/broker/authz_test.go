@@ -0,0 +1,245 @@
+package broker_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+)
+
+func TestSubjectAccessReviewAuthorizer(t *testing.T) {
+	// GIVEN
+	var reviews []authorizationv1.SubjectAccessReview
+	cli := fake.NewSimpleClientset()
+	cli.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		reviews = append(reviews, *review)
+		review.Status.Allowed = review.Spec.User == "admin"
+		if !review.Status.Allowed {
+			review.Status.Reason = "no RBAC policy matched"
+		}
+		return true, review, nil
+	})
+
+	now := time.Now()
+	authorizer := broker.NewSubjectAccessReviewAuthorizer(broker.AuthzConfig{
+		Enabled:  true,
+		Verb:     "create",
+		Group:    "apps",
+		Resource: "deployments",
+		CacheTTL: time.Minute,
+	}, cli.AuthorizationV1().SubjectAccessReviews(), func() time.Time { return now })
+
+	admin := broker.OriginatingUser{Username: "admin", UID: "123", Groups: []string{"system:authenticated"}, Extra: map[string][]string{"scopes": {"all"}}}
+
+	// WHEN
+	allowed, _, err := authorizer.Authorize(admin, "stage")
+	require.NoError(t, err)
+	allowedCached, _, err := authorizer.Authorize(admin, "stage")
+	require.NoError(t, err)
+	denied, reason, err := authorizer.Authorize(broker.OriginatingUser{Username: "john"}, "stage")
+	require.NoError(t, err)
+
+	// THEN
+	assert.True(t, allowed)
+	assert.True(t, allowedCached)
+	assert.False(t, denied)
+	assert.Equal(t, "no RBAC policy matched", reason)
+
+	require.Len(t, reviews, 2, "the result for the same user and namespace must be cached")
+	assert.Equal(t, authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: "stage",
+			Verb:      "create",
+			Group:     "apps",
+			Resource:  "deployments",
+		},
+		User:   "admin",
+		UID:    "123",
+		Groups: []string{"system:authenticated"},
+		Extra:  map[string]authorizationv1.ExtraValue{"scopes": {"all"}},
+	}, reviews[0].Spec)
+
+	// WHEN
+	now = now.Add(2 * time.Minute)
+	_, _, err = authorizer.Authorize(admin, "stage")
+	require.NoError(t, err)
+	_, _, err = authorizer.Authorize(admin, "prod")
+	require.NoError(t, err)
+
+	// THEN
+	assert.Len(t, reviews, 4, "the expired result and the result for other namespace must not be used")
+}
+
+func TestOSBAPIProvisionAuthorizedUser(t *testing.T) {
+	// GIVEN
+	authz := &fakeAuthorizer{allowedUser: "admin"}
+	ts := newOSBAPITestSuiteWithAuthorizer(t, "2.14", authz)
+
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{
+		Info: &release.Info{},
+	}, nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, ts.Exp.NewAddon())
+	require.NoError(t, err)
+	ts.StorageFactory.Chart().Upsert(internal.ClusterWide, ts.Exp.NewChart())
+
+	// WHEN
+	resp, err := ts.OSBClient().ProvisionInstance(&osb.ProvisionRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		Context:             map[string]interface{}{"namespace": string(ts.Exp.Namespace)},
+		OrganizationGUID:    "org",
+		SpaceGUID:           "space",
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: `{"username": "admin"}`},
+	})
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, resp.Async)
+	assert.Equal(t, []internal.Namespace{ts.Exp.Namespace}, authz.namespaces)
+
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+}
+
+func TestOSBAPIProvisionAuthorization(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		originatingIdentity *osb.OriginatingIdentity
+		expStatusCode       int
+	}{
+		"missing originating identity": {
+			expStatusCode: http.StatusForbidden,
+		},
+		"user not allowed": {
+			originatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: `{"username": "john"}`},
+			expStatusCode:       http.StatusForbidden,
+		},
+		"malformed originating identity": {
+			originatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: `["john"]`},
+			expStatusCode:       http.StatusBadRequest,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			authz := &fakeAuthorizer{allowedUser: "admin"}
+			ts := newOSBAPITestSuiteWithAuthorizer(t, "2.14", authz)
+			ts.ServerRun()
+			defer ts.ServerShutdown()
+
+			// WHEN
+			_, err := ts.OSBClient().ProvisionInstance(&osb.ProvisionRequest{
+				AcceptsIncomplete:   true,
+				InstanceID:          string(ts.Exp.InstanceID),
+				ServiceID:           string(ts.Exp.Service.ID),
+				PlanID:              string(ts.Exp.ServicePlan.ID),
+				Context:             map[string]interface{}{"namespace": string(ts.Exp.Namespace)},
+				OrganizationGUID:    "org",
+				SpaceGUID:           "space",
+				OriginatingIdentity: tc.originatingIdentity,
+			})
+
+			// THEN
+			assertHTTPStatusCode(t, tc.expStatusCode, err)
+			_, err = ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+			assert.True(t, broker.IsNotFoundError(err), "instance must not be created")
+		})
+	}
+}
+
+func TestOSBAPIDeprovisionAuthorizedInInstanceNamespace(t *testing.T) {
+	// GIVEN
+	authz := &fakeAuthorizer{allowedUser: "admin"}
+	ts := newOSBAPITestSuiteWithAuthorizer(t, "2.14", authz)
+
+	ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+	ts.StorageFactory.InstanceOperation().Insert(ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded))
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	// WHEN
+	_, err := ts.OSBClient().DeprovisionInstance(&osb.DeprovisionRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: `{"username": "john"}`},
+	})
+
+	// THEN
+	assertHTTPStatusCode(t, http.StatusForbidden, err)
+	assert.Equal(t, []internal.Namespace{ts.Exp.Namespace}, authz.namespaces)
+}
+
+func TestOSBAPIGetInstanceAuthorization(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		username      string
+		expStatusCode int
+	}{
+		"user allowed": {
+			username:      "admin",
+			expStatusCode: http.StatusOK,
+		},
+		"user not allowed": {
+			username:      "john",
+			expStatusCode: http.StatusForbidden,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			authz := &fakeAuthorizer{allowedUser: "admin"}
+			ts := newOSBAPITestSuiteWithAuthorizer(t, "2.14", authz)
+
+			ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+			ts.StorageFactory.InstanceOperation().Insert(ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded))
+
+			ts.ServerRun()
+			defer ts.ServerShutdown()
+
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/cluster/v2/service_instances/%s", ts.ServerAddr, ts.Exp.InstanceID), nil)
+			require.NoError(t, err)
+			req.Header.Set(osb.APIVersionHeader, "2.14")
+			identity := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf(`{"username": %q}`, tc.username)))
+			req.Header.Set(osb.OriginatingIdentityHeader, fmt.Sprintf("%s %s", osb.PlatformKubernetes, identity))
+
+			// WHEN
+			resp, err := http.DefaultClient.Do(req)
+
+			// THEN
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expStatusCode, resp.StatusCode)
+			assert.Equal(t, []internal.Namespace{ts.Exp.Namespace}, authz.namespaces)
+		})
+	}
+}
+
+type fakeAuthorizer struct {
+	allowedUser string
+	namespaces  []internal.Namespace
+}
+
+func (a *fakeAuthorizer) Authorize(user broker.OriginatingUser, namespace internal.Namespace) (bool, string, error) {
+	a.namespaces = append(a.namespaces, namespace)
+	return user.Username == a.allowedUser, "", nil
+}
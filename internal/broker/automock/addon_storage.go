@@ -4,6 +4,7 @@ package automock
 
 import internal "github.com/kyma-project/helm-broker/internal"
 import mock "github.com/stretchr/testify/mock"
+import semver "github.com/Masterminds/semver"
 
 // addonStorage is an autogenerated mock type for the addonStorage type
 type addonStorage struct {
@@ -33,6 +34,29 @@ func (_m *addonStorage) FindAll(namespace internal.Namespace) ([]*internal.Addon
 	return r0, r1
 }
 
+// Get provides a mock function with given fields: namespace, name, ver
+func (_m *addonStorage) Get(namespace internal.Namespace, name internal.AddonName, ver semver.Version) (*internal.Addon, error) {
+	ret := _m.Called(namespace, name, ver)
+
+	var r0 *internal.Addon
+	if rf, ok := ret.Get(0).(func(internal.Namespace, internal.AddonName, semver.Version) *internal.Addon); ok {
+		r0 = rf(namespace, name, ver)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*internal.Addon)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(internal.Namespace, internal.AddonName, semver.Version) error); ok {
+		r1 = rf(namespace, name, ver)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: namespace, id
 func (_m *addonStorage) GetByID(namespace internal.Namespace, id internal.AddonID) (*internal.Addon, error) {
 	ret := _m.Called(namespace, id)
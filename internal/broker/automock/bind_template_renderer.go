@@ -13,13 +13,13 @@ type bindTemplateRenderer struct {
 	mock.Mock
 }
 
-// Render provides a mock function with given fields: bindTemplate, instance, _a2
-func (_m *bindTemplateRenderer) Render(bindTemplate internal.AddonPlanBindTemplate, instance *internal.Instance, _a2 *chart.Chart) (bind.RenderedBindYAML, error) {
-	ret := _m.Called(bindTemplate, instance, _a2)
+// Render provides a mock function with given fields: bindTemplate, instance, bindingID, bindParams, _a4
+func (_m *bindTemplateRenderer) Render(bindTemplate internal.AddonPlanBindTemplate, instance *internal.Instance, bindingID internal.BindingID, bindParams map[string]interface{}, _a4 *chart.Chart) (bind.RenderedBindYAML, error) {
+	ret := _m.Called(bindTemplate, instance, bindingID, bindParams, _a4)
 
 	var r0 bind.RenderedBindYAML
-	if rf, ok := ret.Get(0).(func(internal.AddonPlanBindTemplate, *internal.Instance, *chart.Chart) bind.RenderedBindYAML); ok {
-		r0 = rf(bindTemplate, instance, _a2)
+	if rf, ok := ret.Get(0).(func(internal.AddonPlanBindTemplate, *internal.Instance, internal.BindingID, map[string]interface{}, *chart.Chart) bind.RenderedBindYAML); ok {
+		r0 = rf(bindTemplate, instance, bindingID, bindParams, _a4)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(bind.RenderedBindYAML)
@@ -27,8 +27,8 @@ func (_m *bindTemplateRenderer) Render(bindTemplate internal.AddonPlanBindTempla
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(internal.AddonPlanBindTemplate, *internal.Instance, *chart.Chart) error); ok {
-		r1 = rf(bindTemplate, instance, _a2)
+	if rf, ok := ret.Get(1).(func(internal.AddonPlanBindTemplate, *internal.Instance, internal.BindingID, map[string]interface{}, *chart.Chart) error); ok {
+		r1 = rf(bindTemplate, instance, bindingID, bindParams, _a4)
 	} else {
 		r1 = ret.Error(1)
 	}
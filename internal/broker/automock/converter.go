@@ -2,9 +2,9 @@
 
 package automock
 
+import broker "github.com/kyma-project/helm-broker/internal/broker"
 import internal "github.com/kyma-project/helm-broker/internal"
 import mock "github.com/stretchr/testify/mock"
-import v2 "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
 
 // converter is an autogenerated mock type for the converter type
 type converter struct {
@@ -12,14 +12,14 @@ type converter struct {
 }
 
 // Convert provides a mock function with given fields: b
-func (_m *converter) Convert(b *internal.Addon) (v2.Service, error) {
+func (_m *converter) Convert(b *internal.Addon) (broker.ServiceDTO, error) {
 	ret := _m.Called(b)
 
-	var r0 v2.Service
-	if rf, ok := ret.Get(0).(func(*internal.Addon) v2.Service); ok {
+	var r0 broker.ServiceDTO
+	if rf, ok := ret.Get(0).(func(*internal.Addon) broker.ServiceDTO); ok {
 		r0 = rf(b)
 	} else {
-		r0 = ret.Get(0).(v2.Service)
+		r0 = ret.Get(0).(broker.ServiceDTO)
 	}
 
 	var r1 error
@@ -50,18 +50,18 @@ func (_m *operationStorage) ExpectOnUpdateStateDesc(iID internal.InstanceID, opI
 
 // HelmClient extensions
 func (_m *helmClient) ExpectOnDelete(rName internal.ReleaseName, ns internal.Namespace) *mock.Call {
-	return _m.On("Delete", rName, ns).Return(nil)
+	return _m.On("Delete", rName, ns, mock.Anything).Return(nil)
 }
 
 func (_m *helmClient) ExpectErrorOnDelete(rName internal.ReleaseName, ns internal.Namespace, err error) *mock.Call {
-	return _m.On("Delete", rName, ns).Return(err)
+	return _m.On("Delete", rName, ns, mock.Anything).Return(err)
 }
 
 // InstanceBindDataRemover extensions
-func (_m *instanceBindDataRemover) ExpectOnRemove(iID internal.InstanceID) *mock.Call {
-	return _m.On("Remove", iID).Return(nil)
+func (_m *instanceBindDataRemover) ExpectOnRemoveAll(iID internal.InstanceID) *mock.Call {
+	return _m.On("RemoveAll", iID).Return(nil)
 }
 
-func (_m *instanceBindDataRemover) ExpectErrorRemove(iID internal.InstanceID, err error) *mock.Call {
-	return _m.On("Remove", iID).Return(err)
+func (_m *instanceBindDataRemover) ExpectErrorRemoveAll(iID internal.InstanceID, err error) *mock.Call {
+	return _m.On("RemoveAll", iID).Return(err)
 }
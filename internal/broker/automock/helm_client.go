@@ -12,13 +12,13 @@ type helmClient struct {
 	mock.Mock
 }
 
-// Delete provides a mock function with given fields: _a0
-func (_m *helmClient) Delete(_a0 internal.ReleaseName, _a1 internal.Namespace) error {
-	ret := _m.Called(_a0, _a1)
+// Delete provides a mock function with given fields: _a0, _a1, opts
+func (_m *helmClient) Delete(_a0 internal.ReleaseName, _a1 internal.Namespace, opts internal.HelmOptions) error {
+	ret := _m.Called(_a0, _a1, opts)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(internal.ReleaseName, internal.Namespace) error); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(internal.ReleaseName, internal.Namespace, internal.HelmOptions) error); ok {
+		r0 = rf(_a0, _a1, opts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -26,13 +26,13 @@ func (_m *helmClient) Delete(_a0 internal.ReleaseName, _a1 internal.Namespace) e
 	return r0
 }
 
-// Install provides a mock function with given fields: c, cv, releaseName, namespace
-func (_m *helmClient) Install(c *chart.Chart, cv internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace) (*release.Release, error) {
-	ret := _m.Called(c, cv, releaseName, namespace)
+// GetRelease provides a mock function with given fields: releaseName, namespace
+func (_m *helmClient) GetRelease(releaseName internal.ReleaseName, namespace internal.Namespace) (*release.Release, error) {
+	ret := _m.Called(releaseName, namespace)
 
 	var r0 *release.Release
-	if rf, ok := ret.Get(0).(func(*chart.Chart, internal.ChartValues, internal.ReleaseName, internal.Namespace) *release.Release); ok {
-		r0 = rf(c, cv, releaseName, namespace)
+	if rf, ok := ret.Get(0).(func(internal.ReleaseName, internal.Namespace) *release.Release); ok {
+		r0 = rf(releaseName, namespace)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*release.Release)
@@ -40,8 +40,112 @@ func (_m *helmClient) Install(c *chart.Chart, cv internal.ChartValues, releaseNa
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*chart.Chart, internal.ChartValues, internal.ReleaseName, internal.Namespace) error); ok {
-		r1 = rf(c, cv, releaseName, namespace)
+	if rf, ok := ret.Get(1).(func(internal.ReleaseName, internal.Namespace) error); ok {
+		r1 = rf(releaseName, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Install provides a mock function with given fields: c, cv, releaseName, namespace, opts
+func (_m *helmClient) Install(c *chart.Chart, cv internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) (*release.Release, error) {
+	ret := _m.Called(c, cv, releaseName, namespace, opts)
+
+	var r0 *release.Release
+	if rf, ok := ret.Get(0).(func(*chart.Chart, internal.ChartValues, internal.ReleaseName, internal.Namespace, internal.HelmOptions) *release.Release); ok {
+		r0 = rf(c, cv, releaseName, namespace, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*release.Release)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*chart.Chart, internal.ChartValues, internal.ReleaseName, internal.Namespace, internal.HelmOptions) error); ok {
+		r1 = rf(c, cv, releaseName, namespace, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListReleases provides a mock function with given fields: namespace
+func (_m *helmClient) ListReleases(namespace internal.Namespace) ([]*release.Release, error) {
+	ret := _m.Called(namespace)
+
+	var r0 []*release.Release
+	if rf, ok := ret.Get(0).(func(internal.Namespace) []*release.Release); ok {
+		r0 = rf(namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*release.Release)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(internal.Namespace) error); ok {
+		r1 = rf(namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseHealth provides a mock function with given fields: releaseName, namespace
+func (_m *helmClient) ReleaseHealth(releaseName internal.ReleaseName, namespace internal.Namespace) (internal.ReleaseHealth, error) {
+	ret := _m.Called(releaseName, namespace)
+
+	var r0 internal.ReleaseHealth
+	if rf, ok := ret.Get(0).(func(internal.ReleaseName, internal.Namespace) internal.ReleaseHealth); ok {
+		r0 = rf(releaseName, namespace)
+	} else {
+		r0 = ret.Get(0).(internal.ReleaseHealth)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(internal.ReleaseName, internal.Namespace) error); ok {
+		r1 = rf(releaseName, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunTests provides a mock function with given fields: releaseName, namespace, opts
+func (_m *helmClient) RunTests(releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) error {
+	ret := _m.Called(releaseName, namespace, opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(internal.ReleaseName, internal.Namespace, internal.HelmOptions) error); ok {
+		r0 = rf(releaseName, namespace, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Upgrade provides a mock function with given fields: c, cv, releaseName, namespace, opts
+func (_m *helmClient) Upgrade(c *chart.Chart, cv internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) (*release.Release, error) {
+	ret := _m.Called(c, cv, releaseName, namespace, opts)
+
+	var r0 *release.Release
+	if rf, ok := ret.Get(0).(func(*chart.Chart, internal.ChartValues, internal.ReleaseName, internal.Namespace, internal.HelmOptions) *release.Release); ok {
+		r0 = rf(c, cv, releaseName, namespace, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*release.Release)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*chart.Chart, internal.ChartValues, internal.ReleaseName, internal.Namespace, internal.HelmOptions) error); ok {
+		r1 = rf(c, cv, releaseName, namespace, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -10,13 +10,13 @@ type instanceBindDataGetter struct {
 	mock.Mock
 }
 
-// Get provides a mock function with given fields: iID
-func (_m *instanceBindDataGetter) Get(iID internal.InstanceID) (*internal.InstanceBindData, error) {
-	ret := _m.Called(iID)
+// Get provides a mock function with given fields: iID, bID
+func (_m *instanceBindDataGetter) Get(iID internal.InstanceID, bID internal.BindingID) (*internal.InstanceBindData, error) {
+	ret := _m.Called(iID, bID)
 
 	var r0 *internal.InstanceBindData
-	if rf, ok := ret.Get(0).(func(internal.InstanceID) *internal.InstanceBindData); ok {
-		r0 = rf(iID)
+	if rf, ok := ret.Get(0).(func(internal.InstanceID, internal.BindingID) *internal.InstanceBindData); ok {
+		r0 = rf(iID, bID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*internal.InstanceBindData)
@@ -24,8 +24,8 @@ func (_m *instanceBindDataGetter) Get(iID internal.InstanceID) (*internal.Instan
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(internal.InstanceID) error); ok {
-		r1 = rf(iID)
+	if rf, ok := ret.Get(1).(func(internal.InstanceID, internal.BindingID) error); ok {
+		r1 = rf(iID, bID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -10,8 +10,22 @@ type instanceBindDataRemover struct {
 	mock.Mock
 }
 
-// Remove provides a mock function with given fields: _a0
-func (_m *instanceBindDataRemover) Remove(_a0 internal.InstanceID) error {
+// Remove provides a mock function with given fields: _a0, _a1
+func (_m *instanceBindDataRemover) Remove(_a0 internal.InstanceID, _a1 internal.BindingID) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(internal.InstanceID, internal.BindingID) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveAll provides a mock function with given fields: _a0
+func (_m *instanceBindDataRemover) RemoveAll(_a0 internal.InstanceID) error {
 	ret := _m.Called(_a0)
 
 	var r0 error
@@ -10,13 +10,13 @@ type instanceBindDataStorage struct {
 	mock.Mock
 }
 
-// Get provides a mock function with given fields: iID
-func (_m *instanceBindDataStorage) Get(iID internal.InstanceID) (*internal.InstanceBindData, error) {
-	ret := _m.Called(iID)
+// Get provides a mock function with given fields: iID, bID
+func (_m *instanceBindDataStorage) Get(iID internal.InstanceID, bID internal.BindingID) (*internal.InstanceBindData, error) {
+	ret := _m.Called(iID, bID)
 
 	var r0 *internal.InstanceBindData
-	if rf, ok := ret.Get(0).(func(internal.InstanceID) *internal.InstanceBindData); ok {
-		r0 = rf(iID)
+	if rf, ok := ret.Get(0).(func(internal.InstanceID, internal.BindingID) *internal.InstanceBindData); ok {
+		r0 = rf(iID, bID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*internal.InstanceBindData)
@@ -24,8 +24,8 @@ func (_m *instanceBindDataStorage) Get(iID internal.InstanceID) (*internal.Insta
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(internal.InstanceID) error); ok {
-		r1 = rf(iID)
+	if rf, ok := ret.Get(1).(func(internal.InstanceID, internal.BindingID) error); ok {
+		r1 = rf(iID, bID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -47,8 +47,22 @@ func (_m *instanceBindDataStorage) Insert(_a0 *internal.InstanceBindData) error
 	return r0
 }
 
-// Remove provides a mock function with given fields: _a0
-func (_m *instanceBindDataStorage) Remove(_a0 internal.InstanceID) error {
+// Remove provides a mock function with given fields: _a0, _a1
+func (_m *instanceBindDataStorage) Remove(_a0 internal.InstanceID, _a1 internal.BindingID) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(internal.InstanceID, internal.BindingID) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveAll provides a mock function with given fields: _a0
+func (_m *instanceBindDataStorage) RemoveAll(_a0 internal.InstanceID) error {
 	ret := _m.Called(_a0)
 
 	var r0 error
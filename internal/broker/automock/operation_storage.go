@@ -56,6 +56,29 @@ func (_m *operationStorage) GetAll(iID internal.InstanceID) ([]*internal.Instanc
 	return r0, r1
 }
 
+// GetInstanceIDs provides a mock function with given fields:
+func (_m *operationStorage) GetInstanceIDs() ([]internal.InstanceID, error) {
+	ret := _m.Called()
+
+	var r0 []internal.InstanceID
+	if rf, ok := ret.Get(0).(func() []internal.InstanceID); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]internal.InstanceID)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Insert provides a mock function with given fields: io
 func (_m *operationStorage) Insert(io *internal.InstanceOperation) error {
 	ret := _m.Called(io)
@@ -2,9 +2,11 @@ package broker
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"sync"
 
 	"github.com/kennygrant/sanitize"
 
@@ -26,7 +28,8 @@ type bindService struct {
 	bindStateGetter         bindStateBindingGetter
 	bindOperationStorage    bindOperationStorage
 	operationIDProvider     func() (internal.OperationID, error)
-	mu                      sync.Mutex
+	instanceLocker          *instanceLocker
+	operationQueue          *operationQueue
 
 	log *logrus.Entry
 
@@ -34,17 +37,10 @@ type bindService struct {
 }
 
 func (svc *bindService) Bind(ctx context.Context, osbCtx OsbContext, req *osb.BindRequest) (*osb.BindResponse, *osb.HTTPStatusCodeError) {
-	if len(req.Parameters) > 0 {
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr("helm-broker does not support configuration options for the service binding")}
-	}
-
 	if !req.AcceptsIncomplete {
 		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr("asynchronous operation mode required")}
 	}
 
-	svc.mu.Lock()
-	defer svc.mu.Unlock()
-
 	iID := internal.InstanceID(req.InstanceID)
 	bID := internal.BindingID(req.BindingID)
 	svcID := internal.ServiceID(req.ServiceID)
@@ -54,10 +50,25 @@ func (svc *bindService) Bind(ctx context.Context, osbCtx OsbContext, req *osb.Bi
 		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while validating bind request: %v", err))}
 	}
 
+	paramsHash, hashErr := requestParamsHash(req.Parameters)
+	if hashErr != nil {
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while calculating hash of bind parameters: %v", hashErr))}
+	}
+
+	svc.instanceLocker.Lock(iID)
+	defer svc.instanceLocker.Unlock(iID)
+
 	switch opIDInProgress, inProgress, err := svc.bindStateGetter.IsBindingInProgress(iID, bID); true {
 	case err != nil:
 		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if service binding is being created: %v", err))}
 	case inProgress:
+		opInProgress, err := svc.bindOperationStorage.Get(iID, bID, opIDInProgress)
+		if err != nil {
+			return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting bind operation %q from storage: %v", opIDInProgress, err))}
+		}
+		if conflictErr := bindParamsConflict(*opInProgress, paramsHash); conflictErr != nil {
+			return nil, conflictErr
+		}
 		opKeyInProgress := osb.OperationKey(opIDInProgress)
 		return &osb.BindResponse{Async: true, OperationKey: &opKeyInProgress}, nil
 	}
@@ -66,23 +77,21 @@ func (svc *bindService) Bind(ctx context.Context, osbCtx OsbContext, req *osb.Bi
 	case err != nil:
 		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if service binding for service instance already exists: %v", err))}
 	case state:
-		opID := bindOp.OperationID
-		bindInput, err := svc.prepareBindInput(osbCtx, iID, bID, svcID, svcPlanID, opID)
-		if err != nil {
-			return nil, err
+		if conflictErr := bindParamsConflict(bindOp, paramsHash); conflictErr != nil {
+			return nil, conflictErr
 		}
-
-		svc.doAsync(ctx, bindInput)
-		out, getIbdErr := svc.getInstanceBindData(iID)
-		if getIbdErr != nil {
-			return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting bind data from storage for instance id: %q and service binding id: %q with error: %v", iID, bID, err))}
+		// the binding was already created with the same parameters, so the stored credentials are returned
+		// without creating them again. The binding of the plan without the bind template has no stored credentials.
+		var creds internal.InstanceCredentials
+		switch out, getIbdErr := svc.getInstanceBindData(iID, bID); {
+		case getIbdErr == nil:
+			creds = out.Credentials
+		case IsNotFoundError(getIbdErr):
+		default:
+			return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting bind data from storage for instance id: %q and service binding id: %q with error: %v", iID, bID, getIbdErr))}
 		}
 
-		credsOut := svc.dtoFromModel(out.Credentials)
-
-		if err := svc.instanceBindDataStorage.Remove(iID); err != nil {
-			svc.log.Errorf("while removing instance bind data after getting it from storage on bind, got error: %v", err)
-		}
+		credsOut := svc.dtoFromModel(creds)
 
 		return &osb.BindResponse{
 			Async:       false,
@@ -90,7 +99,12 @@ func (svc *bindService) Bind(ctx context.Context, osbCtx OsbContext, req *osb.Bi
 		}, nil
 	}
 
-	op, err := svc.prepareBindOperation(iID, bID)
+	bindInput, err := svc.prepareBindInput(osbCtx, iID, bID, svcID, svcPlanID, req.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := svc.prepareBindOperation(osbCtx, iID, bID, req.Parameters, paramsHash)
 	if err != nil {
 		return nil, err
 	}
@@ -100,11 +114,7 @@ func (svc *bindService) Bind(ctx context.Context, osbCtx OsbContext, req *osb.Bi
 	}
 
 	opID := op.OperationID
-
-	bindInput, err := svc.prepareBindInput(osbCtx, iID, bID, svcID, svcPlanID, opID)
-	if err != nil {
-		return nil, err
-	}
+	bindInput.operationID = opID
 
 	svc.doAsync(ctx, bindInput)
 
@@ -182,17 +192,13 @@ func (svc *bindService) GetBindData(ctx context.Context, osbCtx OsbContext, req
 		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusNotFound, ErrorMessage: strPtr(fmt.Sprintf("service binding id: %q is in progress", opIDInProgress))}
 	}
 
-	out, err := svc.instanceBindDataStorage.Get(iID)
+	out, err := svc.instanceBindDataStorage.Get(iID, bID)
 	if err != nil {
 		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusNotFound, ErrorMessage: strPtr(fmt.Sprintf("while getting bind data from storage for instance id: %q and service binding id: %q with error: %v", iID, bID, err))}
 	}
 
 	credsOut := svc.dtoFromModel(out.Credentials)
 
-	if removerErr := svc.instanceBindDataStorage.Remove(iID); removerErr != nil {
-		svc.log.Errorf("while removing instance bind data after getting it from storage on get service binding, got error: %v", removerErr)
-	}
-
 	return &osb.GetBindingResponse{
 		Credentials: credsOut,
 	}, nil
@@ -202,12 +208,13 @@ type bindingInput struct {
 	brokerNamespace internal.Namespace
 	instance        *internal.Instance
 	bindingID       internal.BindingID
+	bindParams      map[string]interface{}
 	operationID     internal.OperationID
 	addonPlan       internal.AddonPlan
 	isAddonBindable bool
 }
 
-func (svc *bindService) prepareBindInput(osbCtx OsbContext, iID internal.InstanceID, bID internal.BindingID, svcID internal.ServiceID, svcPlanID internal.ServicePlanID, opID internal.OperationID) (bindingInput, *osb.HTTPStatusCodeError) {
+func (svc *bindService) prepareBindInput(osbCtx OsbContext, iID internal.InstanceID, bID internal.BindingID, svcID internal.ServiceID, svcPlanID internal.ServicePlanID, bindParams map[string]interface{}) (bindingInput, *osb.HTTPStatusCodeError) {
 	instance, err := svc.instanceGetter.Get(iID)
 	switch {
 	case IsNotFoundError(err):
@@ -229,14 +236,21 @@ func (svc *bindService) prepareBindInput(osbCtx OsbContext, iID internal.Instanc
 	addonPlanID := internal.AddonPlanID(svcPlanID)
 	addonPlan, found := addon.Plans[addonPlanID]
 	if !found {
-		return bindingInput{}, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon does not contain requested plan (planID: %s): %v", err, addonPlanID))}
+		return bindingInput{}, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon does not contain requested plan (planID: %s)", addonPlanID))}
+	}
+
+	switch err := validateParameters(addonPlan, internal.SchemaTypeBind, bindParams); {
+	case IsParametersValidationError(err):
+		return bindingInput{}, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("bind parameters are invalid: %v", err))}
+	case err != nil:
+		return bindingInput{}, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while validating bind parameters: %v", err))}
 	}
 
 	bindInput := bindingInput{
 		brokerNamespace: osbCtx.BrokerNamespace,
 		instance:        instance,
 		bindingID:       bID,
-		operationID:     opID,
+		bindParams:      bindParams,
 		addonPlan:       addonPlan,
 		isAddonBindable: addon.Bindable,
 	}
@@ -244,7 +258,7 @@ func (svc *bindService) prepareBindInput(osbCtx OsbContext, iID internal.Instanc
 	return bindInput, nil
 }
 
-func (svc *bindService) prepareBindOperation(iID internal.InstanceID, bID internal.BindingID) (internal.BindOperation, *osb.HTTPStatusCodeError) {
+func (svc *bindService) prepareBindOperation(osbCtx OsbContext, iID internal.InstanceID, bID internal.BindingID, bindParams map[string]interface{}, paramsHash string) (internal.BindOperation, *osb.HTTPStatusCodeError) {
 	opID, err := svc.operationIDProvider()
 	if err != nil {
 		return internal.BindOperation{}, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while preparing bind operation: %v", err))}
@@ -256,16 +270,49 @@ func (svc *bindService) prepareBindOperation(iID internal.InstanceID, bID intern
 		OperationID: opID,
 		Type:        internal.OperationTypeCreate,
 		State:       internal.OperationStateInProgress,
+		RequestedBy: osbCtx.requester(),
+		Parameters:  &internal.RequestParameters{Data: bindParams},
+		ParamsHash:  paramsHash,
 	}
 
 	return op, nil
 }
 
+// requestParamsHash returns the hash of the bind or instance parameters. Map keys are sorted by the JSON encoding,
+// so equal parameters always give the same hash. Missing and empty parameters give the same hash.
+func requestParamsHash(params map[string]interface{}) (string, error) {
+	if len(params) == 0 {
+		params = nil
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", errors.Wrap(err, "while encoding parameters")
+	}
+	sum := sha256.Sum256(raw)
+	return base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+// bindParamsConflict returns the conflict error when the binding was created with different parameters.
+// Bindings created before the parameters hash was stored are treated as created with the same parameters.
+func bindParamsConflict(op internal.BindOperation, paramsHash string) *osb.HTTPStatusCodeError {
+	if op.ParamsHash == "" || op.ParamsHash == paramsHash {
+		return nil
+	}
+	return &osb.HTTPStatusCodeError{
+		StatusCode:   http.StatusConflict,
+		ErrorMessage: strPtr(fmt.Sprintf("service binding %q already exists with different parameters", op.BindingID)),
+	}
+}
+
 func (svc *bindService) doAsync(ctx context.Context, input bindingInput) {
 	if svc.testHookAsyncCalled != nil {
 		svc.testHookAsyncCalled(input.operationID)
 	}
-	go svc.do(ctx, input)
+	svc.operationQueue.Enqueue(asyncOperation{
+		operationID: input.operationID,
+		namespace:   input.instance.Namespace,
+		do:          func(ctx context.Context) { svc.do(ctx, input) },
+	})
 }
 
 func (svc *bindService) do(ctx context.Context, input bindingInput) {
@@ -277,7 +324,7 @@ func (svc *bindService) do(ctx context.Context, input bindingInput) {
 				return errors.Wrap(err, "while getting chart from storage")
 			}
 
-			resolveErr := svc.renderAndResolveBindData(input.addonPlan, input.instance, c)
+			resolveErr := svc.renderAndResolveBindData(input.addonPlan, input.instance, input.bindingID, input.bindParams, c)
 			if resolveErr != nil {
 				return errors.Wrap(resolveErr, "while resolving bind data")
 			}
@@ -305,8 +352,8 @@ func (svc *bindService) isBindable(plan internal.AddonPlan, isAddonBindable bool
 		(plan.Bindable == nil && isAddonBindable) // if bindable field is NOT set on plan that bindable field on addon is important
 }
 
-func (svc *bindService) renderAndResolveBindData(addonPlan internal.AddonPlan, instance *internal.Instance, ch *chart.Chart) error {
-	rendered, err := svc.bindTemplateRenderer.Render(addonPlan.BindTemplate, instance, ch)
+func (svc *bindService) renderAndResolveBindData(addonPlan internal.AddonPlan, instance *internal.Instance, bID internal.BindingID, bindParams map[string]interface{}, ch *chart.Chart) error {
+	rendered, err := svc.bindTemplateRenderer.Render(addonPlan.BindTemplate, instance, bID, bindParams, ch)
 	if err != nil {
 		return errors.Wrap(err, "while rendering bind yaml template")
 	}
@@ -318,6 +365,7 @@ func (svc *bindService) renderAndResolveBindData(addonPlan internal.AddonPlan, i
 
 	in := internal.InstanceBindData{
 		InstanceID:  instance.ID,
+		BindingID:   bID,
 		Credentials: out.Credentials,
 	}
 
@@ -329,9 +377,9 @@ func (svc *bindService) renderAndResolveBindData(addonPlan internal.AddonPlan, i
 	return nil
 }
 
-func (svc *bindService) getInstanceBindData(iID internal.InstanceID) (*internal.InstanceBindData, error) {
+func (svc *bindService) getInstanceBindData(iID internal.InstanceID, bID internal.BindingID) (*internal.InstanceBindData, error) {
 
-	ibd, err := svc.instanceBindDataStorage.Get(iID)
+	ibd, err := svc.instanceBindDataStorage.Get(iID, bID)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,8 @@ func NewBindService(ag addonIDGetter, cg chartGetter, ig instanceGetter, ibds in
 		bindStateGetter:         bsg,
 		bindOperationStorage:    bos,
 		operationIDProvider:     idp,
+		instanceLocker:          newInstanceLocker(),
+		operationQueue:          newStartedOperationQueue(),
 	}
 }
 
@@ -24,3 +26,11 @@ func (svc *bindService) WithTestHookOnAsyncCalled(h func(internal.OperationID))
 	svc.testHookAsyncCalled = h
 	return svc
 }
+
+func ParamsHash(params map[string]interface{}) string {
+	hash, err := requestParamsHash(params)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
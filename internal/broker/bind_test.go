@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -55,6 +56,13 @@ func (ts *bindServiceTestSuite) FixBindOperation(tpe internal.OperationType, sta
 	return *ts.Exp.NewBindOperation(tpe, state)
 }
 
+func (ts *bindServiceTestSuite) FixCreateBindOperationWithParams(bindParams map[string]interface{}) internal.BindOperation {
+	op := *ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	op.Parameters = &internal.RequestParameters{Data: bindParams}
+	op.ParamsHash = broker.ParamsHash(bindParams)
+	return op
+}
+
 func (ts *bindServiceTestSuite) FixBindRequest() osb.BindRequest {
 	return osb.BindRequest{
 		BindingID:  string(ts.Exp.BindingID),
@@ -117,7 +125,8 @@ func TestBindServiceBindSuccessAsyncWhenNotBound(t *testing.T) {
 
 	bosMock := &automock.BindOperationStorage{}
 	defer bosMock.AssertExpectations(t)
-	expBindOp := ts.FixBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	fixBindParams := map[string]interface{}{"username": "fix-user"}
+	expBindOp := ts.FixCreateBindOperationWithParams(fixBindParams)
 	bosMock.On("Insert", &expBindOp).Return(nil).Once()
 	operationSucceeded := make(chan struct{})
 	bosMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID, internal.OperationStateSucceeded, mock.Anything).Return(nil).Once().
@@ -126,7 +135,7 @@ func TestBindServiceBindSuccessAsyncWhenNotBound(t *testing.T) {
 	rendererMock := &automock.BindTemplateRenderer{}
 	defer rendererMock.AssertExpectations(t)
 	expRendered := bind.RenderedBindYAML{}
-	rendererMock.On("Render", ts.Exp.AddonPlan.BindTemplate, &expInstance, &expChart).Return(expRendered, nil)
+	rendererMock.On("Render", ts.Exp.AddonPlan.BindTemplate, &expInstance, ts.Exp.BindingID, fixBindParams, &expChart).Return(expRendered, nil)
 
 	resolverMock := &automock.BindTemplateResolver{}
 	defer resolverMock.AssertExpectations(t)
@@ -154,6 +163,7 @@ func TestBindServiceBindSuccessAsyncWhenNotBound(t *testing.T) {
 	ctx := context.Background()
 	osbCtx := *broker.NewOSBContext("", "v1")
 	req := ts.FixBindRequest()
+	req.Parameters = fixBindParams
 
 	//when
 	resp, err := svc.Bind(ctx, osbCtx, &req)
@@ -249,7 +259,7 @@ func TestBindServiceBindFailureAsyncWhenNotBoundOnChartGet(t *testing.T) {
 
 	bosMock := &automock.BindOperationStorage{}
 	defer bosMock.AssertExpectations(t)
-	expBindOp := ts.FixBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	expBindOp := ts.FixCreateBindOperationWithParams(nil)
 	bosMock.On("Insert", &expBindOp).Return(nil).Once()
 	operationFailed := make(chan struct{})
 	bosMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID, internal.OperationStateFailed, mock.Anything).Return(nil).Once().
@@ -322,11 +332,11 @@ func TestBindServiceBindFailureWhenNotBoundAsyncOnRenderAndResolve(t *testing.T)
 	isMock.On("Get", ts.Exp.InstanceID).Return(&expInstance, nil).Once()
 
 	ibdsMock := &automock.InstanceBindDataStorage{}
-	ibdsMock.On("Get", ts.Exp.InstanceID).Return(nil, notFoundError{}).Once()
+	ibdsMock.On("Get", ts.Exp.InstanceID, ts.Exp.BindingID).Return(nil, notFoundError{}).Once()
 
 	bosMock := &automock.BindOperationStorage{}
 	defer bosMock.AssertExpectations(t)
-	expBindOp := ts.FixBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	expBindOp := ts.FixCreateBindOperationWithParams(nil)
 	bosMock.On("Insert", &expBindOp).Return(nil).Once()
 	operationFailed := make(chan struct{})
 	bosMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID, internal.OperationStateFailed, mock.Anything).Return(nil).Once().
@@ -335,7 +345,7 @@ func TestBindServiceBindFailureWhenNotBoundAsyncOnRenderAndResolve(t *testing.T)
 	rendererMock := &automock.BindTemplateRenderer{}
 	defer rendererMock.AssertExpectations(t)
 	expRenError := errors.New("fake-renderer-error")
-	rendererMock.On("Render", ts.Exp.AddonPlan.BindTemplate, &expInstance, &expChart).Return(nil, expRenError).Once()
+	rendererMock.On("Render", ts.Exp.AddonPlan.BindTemplate, &expInstance, ts.Exp.BindingID, map[string]interface{}(nil), &expChart).Return(nil, expRenError).Once()
 
 	resolverMock := &automock.BindTemplateResolver{}
 
@@ -382,7 +392,7 @@ func TestBindServiceBindFailureWhenNotBoundAsyncOnRenderAndResolve(t *testing.T)
 	}
 }
 
-func TestBindServiceBindSuccessAsyncWhenBound(t *testing.T) {
+func TestBindServiceBindSuccessWhenBound(t *testing.T) {
 	//given
 	ts := newBindServiceTestSuite(t)
 	ts.SetUp()
@@ -391,44 +401,26 @@ func TestBindServiceBindSuccessAsyncWhenBound(t *testing.T) {
 
 	asMock := &automock.AddonStorage{}
 	defer asMock.AssertExpectations(t)
-	expAddon := ts.FixAddon()
-	asMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
-
 	cgMock := &automock.ChartGetter{}
 	defer cgMock.AssertExpectations(t)
-	expChart := ts.FixChart()
-	cgMock.On("Get", internal.ClusterWide, ts.Exp.Chart.Name, ts.Exp.Chart.Version).Return(&expChart, nil).Once()
-
 	isMock := &automock.InstanceStorage{}
 	defer isMock.AssertExpectations(t)
-	expInstance := ts.FixInstanceWithInfo()
-	isMock.On("Get", ts.Exp.InstanceID).Return(&expInstance, nil).Once()
 
 	ibdsMock := &automock.InstanceBindDataStorage{}
 	defer ibdsMock.AssertExpectations(t)
 	expIbd := ts.FixInstanceBindData(expCreds)
-	ibdsMock.On("Insert", &expIbd).Return(nil).Once()
-	ibdsMock.On("Get", ts.Exp.InstanceID).Return(&expIbd, nil).Once()
-	ibdsMock.On("Remove", ts.Exp.InstanceID).Return(nil).Once()
+	ibdsMock.On("Get", ts.Exp.InstanceID, ts.Exp.BindingID).Return(&expIbd, nil).Once()
 
 	bosMock := &automock.BindOperationStorage{}
 	defer bosMock.AssertExpectations(t)
 	expBindOp := ts.FixBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
-	operationSucceeded := make(chan struct{})
-	bosMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID, internal.OperationStateSucceeded, mock.Anything).Return(nil).Once().
-		Run(func(mock.Arguments) { close(operationSucceeded) })
 
 	req := ts.FixBindRequest()
 
 	rendererMock := &automock.BindTemplateRenderer{}
 	defer rendererMock.AssertExpectations(t)
-	expRendered := bind.RenderedBindYAML{}
-	rendererMock.On("Render", ts.Exp.AddonPlan.BindTemplate, &expInstance, &expChart).Return(expRendered, nil)
-
 	resolverMock := &automock.BindTemplateResolver{}
 	defer resolverMock.AssertExpectations(t)
-	expResolved := bind.ResolveOutput{Credentials: expCreds}
-	resolverMock.On("Resolve", expRendered, ts.Exp.Namespace).Return(&expResolved, nil).Once()
 
 	bsgMock := &automock.BindStateGetter{}
 	defer bsgMock.AssertExpectations(t)
@@ -439,13 +431,10 @@ func TestBindServiceBindSuccessAsyncWhenBound(t *testing.T) {
 		return ts.Exp.OperationID, nil
 	}
 
-	testHookCalled := make(chan struct{})
-
 	svc := broker.NewBindService(asMock, cgMock, isMock, ibdsMock,
 		rendererMock, resolverMock, bsgMock, bosMock, oipFake).
 		WithTestHookOnAsyncCalled(func(opID internal.OperationID) {
-			assert.Equal(t, ts.Exp.OperationID, opID)
-			close(testHookCalled)
+			t.Errorf("bind operation %q was started for already existing binding", opID)
 		})
 
 	ctx := context.Background()
@@ -460,83 +449,48 @@ func TestBindServiceBindSuccessAsyncWhenBound(t *testing.T) {
 	assert.EqualValues(t, map[string]interface{}{
 		"password": "secret",
 	}, resp.Credentials)
-
-	select {
-	case <-operationSucceeded:
-	case <-time.After(time.Millisecond * 100):
-		t.Fatal("timeout on operation succeeded")
-	}
-
-	select {
-	case <-testHookCalled:
-	default:
-		t.Fatal("async test hook not called")
-	}
 }
 
-func TestBindServiceBindFailureAsyncWhenBoundOnGetIbd(t *testing.T) {
+func TestBindServiceBindSuccessWhenBoundWithoutBindData(t *testing.T) {
 	//given
 	ts := newBindServiceTestSuite(t)
 	ts.SetUp()
 
 	asMock := &automock.AddonStorage{}
 	defer asMock.AssertExpectations(t)
-	expAddon := ts.FixAddon()
-	asMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
-
 	cgMock := &automock.ChartGetter{}
 	defer cgMock.AssertExpectations(t)
-	expChart := ts.FixChart()
-	cgMock.On("Get", internal.ClusterWide, ts.Exp.Chart.Name, ts.Exp.Chart.Version).Return(&expChart, nil).Once()
-
 	isMock := &automock.InstanceStorage{}
 	defer isMock.AssertExpectations(t)
-	expInstance := ts.FixInstanceWithInfo()
-	isMock.On("Get", ts.Exp.InstanceID).Return(&expInstance, nil).Once()
 
 	ibdsMock := &automock.InstanceBindDataStorage{}
-	ibdsMock.AssertExpectations(t)
-	expIbd := ts.FixInstanceBindData(ts.FixInstanceCredentials())
-	ibdsMock.On("Insert", &expIbd).Return(nil).Once()
-	expIbdGetError := errors.New("fake-ibd-get-error")
-	ibdsMock.On("Get", ts.Exp.InstanceID).Return(nil, expIbdGetError).Once()
+	defer ibdsMock.AssertExpectations(t)
+	ibdsMock.On("Get", ts.Exp.InstanceID, ts.Exp.BindingID).Return(nil, notFoundError{}).Once()
 
 	bosMock := &automock.BindOperationStorage{}
 	defer bosMock.AssertExpectations(t)
-	operationSucceeded := make(chan struct{})
-	bosMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID, internal.OperationStateSucceeded, mock.Anything).Return(nil).Once().
-		Run(func(mock.Arguments) { close(operationSucceeded) })
+	expBindOp := ts.FixBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
 
 	req := ts.FixBindRequest()
 
 	rendererMock := &automock.BindTemplateRenderer{}
 	defer rendererMock.AssertExpectations(t)
-	expRendered := bind.RenderedBindYAML{}
-	rendererMock.On("Render", ts.Exp.AddonPlan.BindTemplate, &expInstance, &expChart).Return(expRendered, nil)
-
 	resolverMock := &automock.BindTemplateResolver{}
 	defer resolverMock.AssertExpectations(t)
-	expCreds := ts.FixInstanceCredentials()
-	expResolved := bind.ResolveOutput{Credentials: expCreds}
-	resolverMock.On("Resolve", expRendered, ts.Exp.Namespace).Return(&expResolved, nil).Once()
 
 	bsgMock := &automock.BindStateGetter{}
 	defer bsgMock.AssertExpectations(t)
-	expOp := ts.FixBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
-	bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(expOp, true, nil).Once()
+	bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(expBindOp, true, nil).Once()
 	bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
 
 	oipFake := func() (internal.OperationID, error) {
 		return ts.Exp.OperationID, nil
 	}
 
-	testHookCalled := make(chan struct{})
-
 	svc := broker.NewBindService(asMock, cgMock, isMock, ibdsMock,
 		rendererMock, resolverMock, bsgMock, bosMock, oipFake).
 		WithTestHookOnAsyncCalled(func(opID internal.OperationID) {
-			assert.Equal(t, ts.Exp.OperationID, opID)
-			close(testHookCalled)
+			t.Errorf("bind operation %q was started for already existing binding", opID)
 		})
 
 	ctx := context.Background()
@@ -546,20 +500,59 @@ func TestBindServiceBindFailureAsyncWhenBoundOnGetIbd(t *testing.T) {
 	resp, err := svc.Bind(ctx, osbCtx, &req)
 
 	//then
-	assert.NotNil(t, err)
-	assert.Nil(t, resp)
+	assert.Nil(t, err)
+	assert.False(t, resp.Async)
+	assert.Empty(t, resp.Credentials)
+}
 
-	select {
-	case <-operationSucceeded:
-	case <-time.After(time.Millisecond * 100):
-		t.Fatal("timeout on operation succeeded")
-	}
+func TestBindServiceBindFailureWhenBoundOnGetIbd(t *testing.T) {
+	//given
+	ts := newBindServiceTestSuite(t)
+	ts.SetUp()
 
-	select {
-	case <-testHookCalled:
-	default:
-		t.Fatal("async test hook not called")
+	asMock := &automock.AddonStorage{}
+	cgMock := &automock.ChartGetter{}
+	isMock := &automock.InstanceStorage{}
+
+	ibdsMock := &automock.InstanceBindDataStorage{}
+	defer ibdsMock.AssertExpectations(t)
+	expIbdGetError := errors.New("fake-ibd-get-error")
+	ibdsMock.On("Get", ts.Exp.InstanceID, ts.Exp.BindingID).Return(nil, expIbdGetError).Once()
+
+	bosMock := &automock.BindOperationStorage{}
+	defer bosMock.AssertExpectations(t)
+
+	req := ts.FixBindRequest()
+
+	rendererMock := &automock.BindTemplateRenderer{}
+	resolverMock := &automock.BindTemplateResolver{}
+
+	bsgMock := &automock.BindStateGetter{}
+	defer bsgMock.AssertExpectations(t)
+	expOp := ts.FixBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(expOp, true, nil).Once()
+	bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
 	}
+
+	svc := broker.NewBindService(asMock, cgMock, isMock, ibdsMock,
+		rendererMock, resolverMock, bsgMock, bosMock, oipFake).
+		WithTestHookOnAsyncCalled(func(opID internal.OperationID) {
+			t.Errorf("bind operation %q was started for already existing binding", opID)
+		})
+
+	ctx := context.Background()
+	osbCtx := *broker.NewOSBContext("", "v1")
+
+	//when
+	resp, err := svc.Bind(ctx, osbCtx, &req)
+
+	//then
+	assert.Nil(t, resp)
+	assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode)
+	assert.Contains(t, *err.ErrorMessage, expIbdGetError.Error())
 }
 
 func TestBindServiceBindSuccessAsyncWhenBindingInProgress(t *testing.T) {
@@ -575,6 +568,8 @@ func TestBindServiceBindSuccessAsyncWhenBindingInProgress(t *testing.T) {
 
 	bosMock := &automock.BindOperationStorage{}
 	defer bosMock.AssertExpectations(t)
+	expOp := ts.FixCreateBindOperationWithParams(nil)
+	bosMock.On("Get", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID).Return(&expOp, nil).Once()
 
 	req := ts.FixBindRequest()
 
@@ -610,6 +605,62 @@ func TestBindServiceBindSuccessAsyncWhenBindingInProgress(t *testing.T) {
 	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
 }
 
+func TestBindServiceBindFailureOnConflictingParameters(t *testing.T) {
+	for name, inProgress := range map[string]bool{
+		"bound":       false,
+		"in progress": true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			//given
+			ts := newBindServiceTestSuite(t)
+			ts.SetUp()
+
+			asMock := &automock.AddonStorage{}
+			cgMock := &automock.ChartGetter{}
+			isMock := &automock.InstanceStorage{}
+			ibdsMock := &automock.InstanceBindDataStorage{}
+			rendererMock := &automock.BindTemplateRenderer{}
+			resolverMock := &automock.BindTemplateResolver{}
+
+			expOp := ts.FixCreateBindOperationWithParams(map[string]interface{}{"username": "fix-user"})
+
+			bosMock := &automock.BindOperationStorage{}
+			defer bosMock.AssertExpectations(t)
+			bsgMock := &automock.BindStateGetter{}
+			defer bsgMock.AssertExpectations(t)
+			if inProgress {
+				bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(ts.Exp.OperationID, true, nil).Once()
+				bosMock.On("Get", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID).Return(&expOp, nil).Once()
+			} else {
+				expOp.State = internal.OperationStateSucceeded
+				bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+				bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(expOp, true, nil).Once()
+			}
+
+			oipFake := func() (internal.OperationID, error) {
+				return ts.Exp.OperationID, nil
+			}
+
+			svc := broker.NewBindService(asMock, cgMock, isMock, ibdsMock,
+				rendererMock, resolverMock, bsgMock, bosMock, oipFake)
+
+			ctx := context.Background()
+			osbCtx := *broker.NewOSBContext("", "v1")
+			req := ts.FixBindRequest()
+			req.Parameters = map[string]interface{}{"username": "other-user"}
+
+			//when
+			resp, err := svc.Bind(ctx, osbCtx, &req)
+
+			//then
+			assert.Nil(t, resp)
+			if assert.NotNil(t, err) {
+				assert.Equal(t, http.StatusConflict, err.StatusCode)
+			}
+		})
+	}
+}
+
 func TestBindServiceBindFailureWhenBindingInProgressOnIsBindingInProgress(t *testing.T) {
 	//given
 	ts := newBindServiceTestSuite(t)
@@ -653,7 +704,7 @@ func TestBindServiceBindFailureWhenBindingInProgressOnIsBindingInProgress(t *tes
 	assert.Nil(t, resp)
 }
 
-func TestBindServiceBindFailureWhenGivenIncorrectParameters(t *testing.T) {
+func TestBindServiceBindFailureWhenAsyncNotAccepted(t *testing.T) {
 	//given
 	ts := newBindServiceTestSuite(t)
 	ts.SetUp()
@@ -669,37 +720,16 @@ func TestBindServiceBindFailureWhenGivenIncorrectParameters(t *testing.T) {
 		return ts.Exp.OperationID, nil
 	}
 
-	testHookCalled := make(chan struct{})
-
 	svc := broker.NewBindService(asMock, cgMock, isMock, ibdsMock, rendererMock, resolverMock,
-		bsgMock, bosMock, oipFake).
-		WithTestHookOnAsyncCalled(func(opID internal.OperationID) {
-			assert.Equal(t, ts.Exp.OperationID, opID)
-			close(testHookCalled)
-		})
+		bsgMock, bosMock, oipFake)
 
 	ctx := context.Background()
 	osbCtx := *broker.NewOSBContext("", "v1")
 	req := ts.FixBindRequest()
-	req.Parameters = map[string]interface{}{
-		"param1": 121,
-		"param2": 132,
-	}
-
-	//when
-	resp, err := svc.Bind(ctx, osbCtx, &req)
-
-	//then
-	assert.NotNil(t, err)
-	assert.EqualValues(t, "helm-broker does not support configuration options for the service binding", *err.ErrorMessage)
-	assert.Nil(t, resp)
-
-	//given
-	req = ts.FixBindRequest()
 	req.AcceptsIncomplete = false
 
 	//when
-	resp, err = svc.Bind(ctx, osbCtx, &req)
+	resp, err := svc.Bind(ctx, osbCtx, &req)
 
 	//then
 	assert.NotNil(t, err)
@@ -863,8 +893,7 @@ func TestBindServiceGetServiceBindingSuccessWhenBound(t *testing.T) {
 	ibdsMock := &automock.InstanceBindDataStorage{}
 	defer ibdsMock.AssertExpectations(t)
 	expIbd := ts.FixInstanceBindData(expCreds)
-	ibdsMock.On("Get", ts.Exp.InstanceID).Return(&expIbd, nil).Once()
-	ibdsMock.On("Remove", ts.Exp.InstanceID).Return(nil).Once()
+	ibdsMock.On("Get", ts.Exp.InstanceID, ts.Exp.BindingID).Return(&expIbd, nil).Once()
 
 	bosMock := &automock.BindOperationStorage{}
 
@@ -913,7 +942,7 @@ func TestBindServiceGetServiceBindingSuccessWhenBoundOnIbdGet(t *testing.T) {
 	ibdsMock := &automock.InstanceBindDataStorage{}
 	defer ibdsMock.AssertExpectations(t)
 	expIbdGetErr := errors.New("fake-ibd-get-error")
-	ibdsMock.On("Get", ts.Exp.InstanceID).Return(nil, expIbdGetErr).Once()
+	ibdsMock.On("Get", ts.Exp.InstanceID, ts.Exp.BindingID).Return(nil, expIbdGetErr).Once()
 
 	bosMock := &automock.BindOperationStorage{}
 	rendererMock := &automock.BindTemplateRenderer{}
@@ -1032,3 +1061,51 @@ func TestBindServiceGetServiceBindingFailureOnIsBindingInProgress(t *testing.T)
 	assert.NotNil(t, err)
 	assert.Nil(t, resp)
 }
+
+func TestBindServiceBindFailureWhenNotBoundOnInvalidParameters(t *testing.T) {
+	//given
+	ts := newBindServiceTestSuite(t)
+	ts.SetUp()
+
+	asMock := &automock.AddonStorage{}
+	defer asMock.AssertExpectations(t)
+	expAddon := ts.Exp.NewAddonWithPlanSchemas()
+	asMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(expAddon, nil).Once()
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	expInstance := ts.FixInstanceWithInfo()
+	isMock.On("Get", ts.Exp.InstanceID).Return(&expInstance, nil).Once()
+
+	bsgMock := &automock.BindStateGetter{}
+	defer bsgMock.AssertExpectations(t)
+	bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.BindOperation{}, false, nil).Once()
+	bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	ibdsMock := &automock.InstanceBindDataStorage{}
+	bosMock := &automock.BindOperationStorage{}
+	rendererMock := &automock.BindTemplateRenderer{}
+	resolverMock := &automock.BindTemplateResolver{}
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewBindService(asMock, cgMock, isMock, ibdsMock, rendererMock, resolverMock,
+		bsgMock, bosMock, oipFake)
+
+	ctx := context.Background()
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixBindRequest()
+	req.Parameters = map[string]interface{}{"username": "fix-user"}
+
+	//when
+	resp, err := svc.Bind(ctx, osbCtx, &req)
+
+	//then
+	assert.Nil(t, resp)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	assert.Contains(t, *err.ErrorMessage, "(root): replicas is required")
+}
@@ -30,6 +30,9 @@ import (
 //go:generate mockery -name=bindTemplateResolver -output=automock -outpkg=automock -case=underscore
 
 type (
+	addonGetter interface {
+		Get(namespace internal.Namespace, name internal.AddonName, ver semver.Version) (*internal.Addon, error)
+	}
 	addonIDGetter interface {
 		GetByID(namespace internal.Namespace, id internal.AddonID) (*internal.Addon, error)
 	}
@@ -37,6 +40,7 @@ type (
 		FindAll(namespace internal.Namespace) ([]*internal.Addon, error)
 	}
 	addonStorage interface {
+		addonGetter
 		addonIDGetter
 		addonFinder
 	}
@@ -56,6 +60,7 @@ type (
 	}
 	operationCollectionGetter interface {
 		GetAll(iID internal.InstanceID) ([]*internal.InstanceOperation, error)
+		GetInstanceIDs() ([]internal.InstanceID, error)
 	}
 	operationUpdater interface {
 		UpdateState(iID internal.InstanceID, opID internal.OperationID, state internal.OperationState) error
@@ -80,6 +85,7 @@ type (
 	}
 	bindOperationCollectionGetter interface {
 		GetAll(iID internal.InstanceID) ([]*internal.BindOperation, error)
+		GetInstanceIDs() ([]internal.InstanceID, error)
 	}
 	bindOperationUpdater interface {
 		UpdateState(iID internal.InstanceID, bID internal.BindingID, opID internal.OperationID, state internal.OperationState) error
@@ -101,8 +107,15 @@ type (
 		IsBindingInProgress(internal.InstanceID, internal.BindingID) (internal.OperationID, bool, error)
 	}
 
+	bindStateUnbindingGetter interface {
+		IsBound(internal.InstanceID, internal.BindingID) (internal.BindOperation, bool, error)
+		IsBindingInProgress(internal.InstanceID, internal.BindingID) (internal.OperationID, bool, error)
+		IsUnbindingInProgress(internal.InstanceID, internal.BindingID) (internal.OperationID, bool, error)
+	}
+
 	bindStateGetter interface {
 		bindStateBindingGetter
+		bindStateUnbindingGetter
 	}
 
 	instanceInserter interface {
@@ -138,18 +151,38 @@ type (
 	}
 
 	helmInstaller interface {
-		Install(chrt *chart.Chart, values internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace) (*release.Release, error)
+		Install(chrt *chart.Chart, values internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) (*release.Release, error)
+	}
+	helmUpgrader interface {
+		Upgrade(chrt *chart.Chart, values internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) (*release.Release, error)
 	}
 	helmDeleter interface {
-		Delete(releaseName internal.ReleaseName, namespace internal.Namespace) error
+		Delete(releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) error
+	}
+	helmReleaseGetter interface {
+		GetRelease(releaseName internal.ReleaseName, namespace internal.Namespace) (*release.Release, error)
+	}
+	helmReleaseLister interface {
+		ListReleases(namespace internal.Namespace) ([]*release.Release, error)
+	}
+	helmTester interface {
+		RunTests(releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) error
+	}
+	helmReleaseHealthGetter interface {
+		ReleaseHealth(releaseName internal.ReleaseName, namespace internal.Namespace) (internal.ReleaseHealth, error)
 	}
 	helmClient interface {
 		helmInstaller
+		helmUpgrader
 		helmDeleter
+		helmReleaseGetter
+		helmReleaseLister
+		helmTester
+		helmReleaseHealthGetter
 	}
 
 	instanceBindDataGetter interface {
-		Get(iID internal.InstanceID) (*internal.InstanceBindData, error)
+		Get(iID internal.InstanceID, bID internal.BindingID) (*internal.InstanceBindData, error)
 	}
 
 	instanceBindDataInserter interface {
@@ -157,7 +190,8 @@ type (
 	}
 
 	instanceBindDataRemover interface {
-		Remove(internal.InstanceID) error
+		Remove(internal.InstanceID, internal.BindingID) error
+		RemoveAll(internal.InstanceID) error
 	}
 
 	instanceBindDataStorage interface {
@@ -167,17 +201,22 @@ type (
 	}
 
 	bindTemplateRenderer interface {
-		Render(bindTemplate internal.AddonPlanBindTemplate, instance *internal.Instance, chart *chart.Chart) (bind.RenderedBindYAML, error)
+		Render(bindTemplate internal.AddonPlanBindTemplate, instance *internal.Instance, bindingID internal.BindingID, bindParams map[string]interface{}, chart *chart.Chart) (bind.RenderedBindYAML, error)
 	}
 
 	bindTemplateResolver interface {
 		Resolve(bindYAML bind.RenderedBindYAML, ns internal.Namespace) (*bind.ResolveOutput, error)
 	}
+
+	dashboardURLRenderer interface {
+		RenderDashboardURL(dashboardURLTemplate internal.AddonPlanDashboardURLTemplate, instance *internal.Instance, chart *chart.Chart) (string, error)
+	}
 )
 
-// New creates instance of broker.
+// New creates instance of broker. Requests are not authenticated when the authenticator is nil
+// and are not authorized when the authorizer is nil.
 func New(bs addonStorage, cs chartStorage, os operationStorage, bos bindOperationStorage, is instanceStorage, ibd instanceBindDataStorage,
-	bindTmplRenderer bindTemplateRenderer, bindTmplResolver bindTemplateResolver, hc helmClient, log *logrus.Entry) *Server {
+	bindTmplRenderer bindTemplateRenderer, bindTmplResolver bindTemplateResolver, dashboardRenderer dashboardURLRenderer, hc helmClient, authn Authenticator, authz Authorizer, cfg Config, log *logrus.Entry) *Server {
 	idpRaw := idprovider.New()
 	idp := func() (internal.OperationID, error) {
 		idRaw, err := idpRaw()
@@ -187,12 +226,48 @@ func New(bs addonStorage, cs chartStorage, os operationStorage, bos bindOperatio
 		return internal.OperationID(idRaw), nil
 	}
 
-	return newWithIDProvider(bs, cs, os, bos, is, ibd, bindTmplRenderer, bindTmplResolver, hc, log, idp)
+	return newWithIDProvider(bs, cs, os, bos, is, ibd, bindTmplRenderer, bindTmplResolver, dashboardRenderer, hc, authn, authz, cfg, log, idp)
 }
 
 func newWithIDProvider(bs addonStorage, cs chartStorage, os operationStorage, bos bindOperationStorage, is instanceStorage, ibd instanceBindDataStorage,
-	bindTmplRenderer bindTemplateRenderer, bindTmplResolver bindTemplateResolver, hc helmClient,
+	bindTmplRenderer bindTemplateRenderer, bindTmplResolver bindTemplateResolver, dashboardRenderer dashboardURLRenderer, hc helmClient, authn Authenticator, authz Authorizer, cfg Config,
 	log *logrus.Entry, idp func() (internal.OperationID, error)) *Server {
+	// operations on the same instance are serialized across all services
+	instLocker := newInstanceLocker()
+	opQueue := newOperationQueue(cfg.OperationQueue, log.WithField("service", "operation-queue"))
+	if cfg.SynchronousOperationTimeout <= 0 {
+		cfg.SynchronousOperationTimeout = defaultSynchronousOperationTimeout
+	}
+
+	deprovisioner := &deprovisionService{
+		addonIDGetter:     bs,
+		instanceGetter:    is,
+		instanceRemover:   is,
+		operationInserter: os,
+		instanceStateGetter: &instanceStateService{
+			operationCollectionGetter: os,
+		},
+		operationUpdater:        os,
+		instanceBindDataRemover: ibd,
+		operationIDProvider:     idp,
+		helmDeleter:             hc,
+		instanceLocker:          instLocker,
+		operationQueue:          opQueue,
+		synchronousTimeout:      cfg.SynchronousOperationTimeout,
+		log:                     log.WithField("service", "deprovisioner"),
+	}
+	unbinder := &unbindService{
+		bindStateGetter: &bindStateService{
+			bindOperationCollectionGetter: bos,
+		},
+		bindOperationStorage:    bos,
+		instanceBindDataRemover: ibd,
+		operationIDProvider:     idp,
+		instanceLocker:          instLocker,
+		operationQueue:          opQueue,
+		log:                     log.WithField("service", "unbinder"),
+	}
+
 	return &Server{
 		catalogGetter: &catalogService{
 			finder: bs,
@@ -210,21 +285,36 @@ func newWithIDProvider(bs addonStorage, cs chartStorage, os operationStorage, bo
 			operationUpdater:    os,
 			operationIDProvider: idp,
 			helmInstaller:       hc,
+			helmTester:          hc,
+			instanceLocker:      instLocker,
+			operationQueue:      opQueue,
+			atomicProvisioning:  cfg.AtomicProvisioning,
+			synchronousTimeout:  cfg.SynchronousOperationTimeout,
+			helmDeleter:         hc,
+			instanceRemover:     is,
+			dashboardRenderer:   dashboardRenderer,
 			log:                 log.WithField("service", "provisioner"),
 		},
-		deprovisioner: &deprovisionService{
-			instanceGetter:    is,
-			instanceRemover:   is,
-			operationInserter: os,
+		updater: &updateService{
+			addonGetter:      bs,
+			addonIDGetter:    bs,
+			chartGetter:      cs,
+			instanceInserter: is,
+			instanceGetter:   is,
 			instanceStateGetter: &instanceStateService{
 				operationCollectionGetter: os,
 			},
-			operationUpdater:        os,
-			instanceBindDataRemover: ibd,
-			operationIDProvider:     idp,
-			helmDeleter:             hc,
-			log:                     log.WithField("service", "deprovisioner"),
+			operationInserter:   os,
+			operationUpdater:    os,
+			operationIDProvider: idp,
+			helmUpgrader:        hc,
+			helmTester:          hc,
+			instanceLocker:      instLocker,
+			operationQueue:      opQueue,
+			dashboardRenderer:   dashboardRenderer,
+			log:                 log.WithField("service", "updater"),
 		},
+		deprovisioner: deprovisioner,
 		binder: &bindService{
 			addonIDGetter:           bs,
 			chartGetter:             cs,
@@ -237,11 +327,72 @@ func newWithIDProvider(bs addonStorage, cs chartStorage, os operationStorage, bo
 			},
 			bindOperationStorage: bos,
 			operationIDProvider:  idp,
+			instanceLocker:       instLocker,
+			operationQueue:       opQueue,
 			log:                  log.WithField("service", "binder"),
 		},
-		unbinder: &unbindService{},
+		unbinder: unbinder,
 		lastOpGetter: &getLastOperationService{
-			getter: os,
+			getter:         os,
+			instanceGetter: is,
+			healthGetter:   hc,
+			log:            log.WithField("service", "last-operation-getter"),
+		},
+		instStatusGetter: &instanceStatusService{
+			instanceGetter: is,
+			healthGetter:   hc,
+		},
+		instImporter: &instanceImportService{
+			addonIDGetter:       bs,
+			chartGetter:         cs,
+			instanceGetter:      is,
+			instanceInserter:    is,
+			operationInserter:   os,
+			operationIDProvider: idp,
+			helmReleaseGetter:   hc,
+			dashboardRenderer:   dashboardRenderer,
+			instanceLocker:      instLocker,
+			log:                 log.WithField("service", "importer"),
+		},
+		instFetcher: &getInstanceService{
+			instanceGetter: is,
+			instanceStateGetter: &instanceStateService{
+				operationCollectionGetter: os,
+			},
+		},
+		operationQueue: opQueue,
+		operationRecoverer: &operationRecoverer{
+			instanceGetter:       is,
+			operationStorage:     os,
+			bindOperationStorage: bos,
+			deprovisioner:        deprovisioner,
+			unbinder:             unbinder,
+			log:                  log.WithField("service", "operation-recoverer"),
+		},
+		operationReaper: &operationReaper{
+			cfg:                  cfg.OperationReaper,
+			instanceGetter:       is,
+			instanceInserter:     is,
+			operationStorage:     os,
+			bindOperationStorage: bos,
+			helmReleaseGetter:    hc,
+			deprovisioner:        deprovisioner,
+			instanceLocker:       instLocker,
+			operationQueue:       opQueue,
+			log:                  log.WithField("service", "operation-reaper"),
+		},
+		driftReconciler: &driftReconciler{
+			cfg:                       cfg.DriftReconciler,
+			instanceGetter:            is,
+			operationCollectionGetter: os,
+			helmReleaseLister:         hc,
+			helmDeleter:               hc,
+			log:                       log.WithField("service", "drift-reconciler"),
+		},
+		authenticator: authn,
+		authorizer: &requestAuthorizer{
+			authorizer:     authz,
+			instanceGetter: is,
 		},
 		logger: log.WithField("service", "server"),
 	}
@@ -7,7 +7,7 @@ import (
 )
 
 func NewWithIDProvider(bs addonStorage, cs chartStorage, os operationStorage, bos bindOperationStorage, is instanceStorage, ibd instanceBindDataStorage,
-	bindTmplRenderer bindTemplateRenderer, bindTmplResolver bindTemplateResolver,
-	hc helmClient, log *logrus.Entry, idp func() (internal.OperationID, error)) *Server {
-	return newWithIDProvider(bs, cs, os, bos, is, ibd, bindTmplRenderer, bindTmplResolver, hc, log, idp)
+	bindTmplRenderer bindTemplateRenderer, bindTmplResolver bindTemplateResolver, dashboardRenderer dashboardURLRenderer,
+	hc helmClient, authn Authenticator, authz Authorizer, cfg Config, log *logrus.Entry, idp func() (internal.OperationID, error)) *Server {
+	return newWithIDProvider(bs, cs, os, bos, is, ibd, bindTmplRenderer, bindTmplResolver, dashboardRenderer, hc, authn, authz, cfg, log, idp)
 }
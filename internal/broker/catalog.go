@@ -2,6 +2,7 @@ package broker
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/kyma-project/helm-broker/internal"
 
@@ -16,23 +17,29 @@ type catalogService struct {
 
 //go:generate mockery -name=converter -output=automock -outpkg=automock -case=underscore
 type converter interface {
-	Convert(b *internal.Addon) (osb.Service, error)
+	Convert(b *internal.Addon) (ServiceDTO, error)
 }
 
-// TODO: switch from osb.CatalogResponse to CatalogSuccessResponseDTO
-func (svc *catalogService) GetCatalog(ctx context.Context, osbCtx OsbContext) (*osb.CatalogResponse, error) {
+func (svc *catalogService) GetCatalog(ctx context.Context, osbCtx OsbContext) (*CatalogSuccessResponseDTO, error) {
 	addons, err := svc.finder.FindAll(osbCtx.BrokerNamespace)
 	if err != nil {
 		return nil, errors.Wrap(err, "while finding all addons")
 	}
 
-	resp := osb.CatalogResponse{}
-	resp.Services = make([]osb.Service, len(addons))
+	resp := CatalogSuccessResponseDTO{}
+	resp.Services = make([]ServiceDTO, len(addons))
 	for idx, b := range addons {
 		s, err := svc.conv.Convert(b)
 		if err != nil {
 			return nil, errors.Wrap(err, "while converting addon to service")
 		}
+		// the fields are not known to the Platforms which use the API version without given feature
+		s.InstancesRetrievable = s.InstancesRetrievable && osbCtx.supports(osbAPIFeatureInstanceRetrieval)
+		if !osbCtx.supports(osbAPIFeatureMaintenanceInfo) {
+			for i := range s.Plans {
+				s.Plans[i].MaintenanceInfo = nil
+			}
+		}
 		resp.Services[idx] = s
 	}
 	return &resp, nil
@@ -40,8 +47,8 @@ func (svc *catalogService) GetCatalog(ctx context.Context, osbCtx OsbContext) (*
 
 type addonToServiceConverter struct{}
 
-func (f *addonToServiceConverter) Convert(addon *internal.Addon) (osb.Service, error) {
-	var sPlans []osb.Plan
+func (f *addonToServiceConverter) Convert(addon *internal.Addon) (ServiceDTO, error) {
+	var sPlans []PlanDTO
 	for _, bPlan := range addon.Plans {
 		sPlan := osb.Plan{
 			ID:          string(bPlan.ID),
@@ -56,7 +63,10 @@ func (f *addonToServiceConverter) Convert(addon *internal.Addon) (osb.Service, e
 			sPlan.Schemas = f.mapToParametersSchemas(bPlan.Schemas)
 		}
 
-		sPlans = append(sPlans, sPlan)
+		sPlans = append(sPlans, PlanDTO{
+			Plan:            sPlan,
+			MaintenanceInfo: maintenanceInfo(addon),
+		})
 	}
 
 	var sTags []string
@@ -66,20 +76,32 @@ func (f *addonToServiceConverter) Convert(addon *internal.Addon) (osb.Service, e
 
 	meta := f.applyOverridesOnAddonMetadata(addon.Metadata)
 
-	return osb.Service{
-		ID:                  string(addon.ID),
-		Name:                string(addon.Name),
-		Description:         addon.Description,
-		Bindable:            addon.Bindable,
-		BindingsRetrievable: true, // FYI: needed for  async binding
-		Requires:            addon.Requires,
-		PlanUpdatable:       addon.PlanUpdatable,
-		Plans:               sPlans,
-		Metadata:            meta.ToMap(),
-		Tags:                sTags,
+	return ServiceDTO{
+		Service: osb.Service{
+			ID:                   string(addon.ID),
+			Name:                 string(addon.Name),
+			Description:          addon.Description,
+			Bindable:             addon.Bindable,
+			BindingsRetrievable:  true, // FYI: needed for  async binding
+			InstancesRetrievable: true,
+			Requires:             addon.Requires,
+			PlanUpdatable:        addon.PlanUpdatable,
+			Metadata:             meta.ToMap(),
+			Tags:                 sTags,
+		},
+		Plans: sPlans,
 	}, nil
 }
 
+// maintenanceInfo returns the maintenance info of all plans of the addon. Instances are upgraded to the new chart
+// when the repository delivers the new version of the addon.
+func maintenanceInfo(addon *internal.Addon) *MaintenanceInfoDTO {
+	return &MaintenanceInfoDTO{
+		Version:     addon.Version.String(),
+		Description: fmt.Sprintf("Addon %s in version %s", addon.Name, addon.Version.String()),
+	}
+}
+
 func (f *addonToServiceConverter) mapToParametersSchemas(planSchemas map[internal.PlanSchemaType]internal.PlanSchema) *osb.Schemas {
 	ensureServiceInstancesInit := func(in *osb.ServiceInstanceSchema) *osb.ServiceInstanceSchema {
 		if in == nil {
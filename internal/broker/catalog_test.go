@@ -42,6 +42,65 @@ func TestGetCatalog(t *testing.T) {
 
 }
 
+func TestGetCatalogInstancesRetrievableDependsOnAPIVersion(t *testing.T) {
+	for apiVersion, expRetrievable := range map[string]bool{
+		"2.13": false,
+		"2.14": true,
+		"2.16": true,
+	} {
+		t.Run(apiVersion, func(t *testing.T) {
+			// GIVEN
+			tc := newCatalogTC()
+			defer tc.AssertExpectations(t)
+			fixService := tc.fixService()
+			fixService.InstancesRetrievable = true
+			tc.finderMock.On("FindAll", internal.ClusterWide).Return(tc.fixAddons(), nil).Once()
+			tc.converterMock.On("Convert", tc.fixAddon()).Return(fixService, nil)
+
+			svc := broker.NewCatalogService(tc.finderMock, tc.converterMock)
+			osbCtx := broker.NewOSBContext("", apiVersion)
+			// WHEN
+			resp, err := svc.GetCatalog(context.Background(), *osbCtx)
+			// THEN
+			require.NoError(t, err)
+			require.Len(t, resp.Services, 1)
+			assert.Equal(t, expRetrievable, resp.Services[0].InstancesRetrievable)
+		})
+	}
+}
+
+func TestGetCatalogMaintenanceInfoDependsOnAPIVersion(t *testing.T) {
+	for apiVersion, expMaintenanceInfo := range map[string]bool{
+		"2.14": false,
+		"2.15": true,
+		"2.16": true,
+	} {
+		t.Run(apiVersion, func(t *testing.T) {
+			// GIVEN
+			tc := newCatalogTC()
+			defer tc.AssertExpectations(t)
+			tc.finderMock.On("FindAll", internal.ClusterWide).Return(tc.fixAddons(), nil).Once()
+			fixService := tc.fixService()
+			fixService.Plans = []broker.PlanDTO{
+				{Plan: osb.Plan{ID: "planID"}, MaintenanceInfo: &broker.MaintenanceInfoDTO{Version: "1.2.3"}},
+			}
+			tc.converterMock.On("Convert", tc.fixAddon()).Return(fixService, nil)
+
+			svc := broker.NewCatalogService(tc.finderMock, tc.converterMock)
+			osbCtx := broker.NewOSBContext("", apiVersion)
+
+			// WHEN
+			resp, err := svc.GetCatalog(context.Background(), *osbCtx)
+
+			// THEN
+			require.NoError(t, err)
+			require.Len(t, resp.Services, 1)
+			require.Len(t, resp.Services[0].Plans, 1)
+			assert.Equal(t, expMaintenanceInfo, resp.Services[0].Plans[0].MaintenanceInfo != nil)
+		})
+	}
+}
+
 func TestGetCatalogOnFindError(t *testing.T) {
 	// GIVEN
 	tc := newCatalogTC()
@@ -62,7 +121,7 @@ func TestGetCatalogOnConversionError(t *testing.T) {
 	defer tc.AssertExpectations(t)
 
 	tc.finderMock.On("FindAll", internal.ClusterWide).Return(tc.fixAddons(), nil).Once()
-	tc.converterMock.On("Convert", tc.fixAddon()).Return(osb.Service{}, tc.fixError())
+	tc.converterMock.On("Convert", tc.fixAddon()).Return(broker.ServiceDTO{}, tc.fixError())
 
 	svc := broker.NewCatalogService(tc.finderMock, tc.converterMock)
 	osbCtx := broker.NewOSBContext("not", "important")
@@ -118,6 +177,21 @@ func TestAddonConversion(t *testing.T) {
 	}
 }
 
+func TestAddonConversionSetsMaintenanceInfoFromAddonVersion(t *testing.T) {
+	// GIVEN
+	tc := newCatalogTC()
+	conv := broker.NewConverter()
+
+	// WHEN
+	convertedSvc, err := conv.Convert(tc.fixAddon())
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, convertedSvc.Plans, 1)
+	require.NotNil(t, convertedSvc.Plans[0].MaintenanceInfo)
+	assert.Equal(t, "1.2.3", convertedSvc.Plans[0].MaintenanceInfo.Version)
+}
+
 func TestAddonConversionOverridesLocalLabel(t *testing.T) {
 	// GIVEN
 	tc := newCatalogTC()
@@ -236,8 +310,8 @@ func fixBindSchema() internal.PlanSchema {
 	}
 }
 
-func (tc catalogTestCase) fixService() osb.Service {
-	return osb.Service{ID: "addonID"}
+func (tc catalogTestCase) fixService() broker.ServiceDTO {
+	return broker.ServiceDTO{Service: osb.Service{ID: "addonID"}}
 }
 
 func (tc catalogTestCase) fixError() error {
@@ -0,0 +1,20 @@
+package broker
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+// runChartTests runs the tests of the release when the plan enables them.
+// The returned error describes the failed test pods, so it is put in the operation description.
+func runChartTests(tester helmTester, plan internal.AddonPlan, releaseName internal.ReleaseName, namespace internal.Namespace) error {
+	if !plan.RunTests {
+		return nil
+	}
+
+	if err := tester.RunTests(releaseName, namespace, plan.HelmOptions); err != nil {
+		return errors.Wrap(err, "while running chart tests")
+	}
+	return nil
+}
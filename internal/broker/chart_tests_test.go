@@ -0,0 +1,124 @@
+package broker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+func TestOSBAPIProvisionRunsChartTests(t *testing.T) {
+	for name, tc := range map[string]struct {
+		testsErr error
+		expState internal.OperationState
+		expDesc  string
+	}{
+		"tests passed": {
+			expState: internal.OperationStateSucceeded,
+			expDesc:  "provisioning succeeded",
+		},
+		"tests failed": {
+			testsErr: errors.New("test pod redis-test failed with logs: connection refused"),
+			expState: internal.OperationStateFailed,
+			expDesc:  "provisioning failed on error: while running chart tests: test pod redis-test failed with logs: connection refused",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			ts := newOSBAPITestSuite(t, "2.14")
+			ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{
+				Info: &release.Info{},
+			}, nil).Once()
+			ts.HelmClient.On("RunTests", ts.Exp.ReleaseName, ts.Exp.Namespace, fixTestedPlanHelmOptions).Return(tc.testsErr).Once()
+			defer ts.HelmClient.AssertExpectations(t)
+
+			ts.ServerRun()
+			defer ts.ServerShutdown()
+			ts.upsertAddonWithTestedPlan()
+
+			req := &osb.ProvisionRequest{
+				AcceptsIncomplete:   true,
+				InstanceID:          string(ts.Exp.InstanceID),
+				ServiceID:           string(ts.Exp.Service.ID),
+				PlanID:              string(ts.Exp.ServicePlan.ID),
+				Context:             map[string]interface{}{"namespace": string(ts.Exp.Namespace)},
+				OrganizationGUID:    "org",
+				SpaceGUID:           "space",
+				OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+			}
+
+			// WHEN
+			_, err := ts.OSBClient().ProvisionInstance(req)
+
+			// THEN
+			require.NoError(t, err)
+			ts.AssertOperationState(tc.expState)
+
+			op, err := ts.StorageFactory.InstanceOperation().Get(ts.Exp.InstanceID, ts.Exp.OperationID)
+			require.NoError(t, err)
+			require.NotNil(t, op.StateDescription)
+			assert.Equal(t, tc.expDesc, *op.StateDescription)
+		})
+	}
+}
+
+func TestOSBAPIUpdateRunsChartTests(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+
+	fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	fixOperation.OperationID = internal.OperationID("fix-op-id")
+	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
+	ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+
+	ts.HelmClient.On("Upgrade", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}}, nil).Once()
+	ts.HelmClient.On("RunTests", ts.Exp.ReleaseName, ts.Exp.Namespace, fixTestedPlanHelmOptions).Return(errors.New("test pod redis-test failed")).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+	ts.upsertAddonWithTestedPlan()
+
+	req := &osb.UpdateInstanceRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		Parameters:          map[string]interface{}{"foo": "bar"},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
+
+	// WHEN
+	_, err := ts.OSBClient().UpdateInstance(req)
+
+	// THEN
+	require.NoError(t, err)
+	ts.AssertOperationState(internal.OperationStateFailed)
+
+	op, err := ts.StorageFactory.InstanceOperation().Get(ts.Exp.InstanceID, ts.Exp.OperationID)
+	require.NoError(t, err)
+	require.NotNil(t, op.StateDescription)
+	assert.Equal(t, "update failed on error: while running chart tests: test pod redis-test failed", *op.StateDescription)
+}
+
+// fixTestedPlanHelmOptions are passed to the chart tests, so they run with the timeout of the plan
+var fixTestedPlanHelmOptions = internal.HelmOptions{Timeout: 5 * time.Minute}
+
+func (ts *osbapiTestSuite) upsertAddonWithTestedPlan() {
+	addon := ts.Exp.NewAddon()
+	plan := addon.Plans[ts.Exp.AddonPlan.ID]
+	plan.RunTests = true
+	plan.HelmOptions = fixTestedPlanHelmOptions
+	addon.Plans[ts.Exp.AddonPlan.ID] = plan
+
+	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, addon)
+	require.NoError(ts.t, err)
+	_, err = ts.StorageFactory.Chart().Upsert(internal.ClusterWide, ts.Exp.NewChart())
+	require.NoError(ts.t, err)
+}
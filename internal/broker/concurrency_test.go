@@ -0,0 +1,181 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/broker/automock"
+	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
+)
+
+const (
+	fixBlockedInstanceID = internal.InstanceID("blocked-instance-id")
+	fixOtherInstanceID   = internal.InstanceID("other-instance-id")
+)
+
+// blockingCall makes the first state check of the blocked instance wait until the test releases it.
+// All requests end right after the state check because the check returns an error.
+type blockingCall struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingCall() *blockingCall {
+	return &blockingCall{
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (b *blockingCall) Run(mock.Arguments) {
+	close(b.entered)
+	<-b.release
+}
+
+// lockedServiceCall sets up the service and returns function which calls it for given instance
+type lockedServiceCall func(t *testing.T, blocking *blockingCall) func(iID internal.InstanceID)
+
+func TestConcurrencyOperationsOnInstances(t *testing.T) {
+	for tn, setUp := range map[string]lockedServiceCall{
+		"provision":   fixLockedProvisionCall,
+		"update":      fixLockedUpdateCall,
+		"deprovision": fixLockedDeprovisionCall,
+		"bind":        fixLockedBindCall,
+	} {
+		t.Run(tn+"/DifferentInstancesInParallel", func(t *testing.T) {
+			// GIVEN
+			blocking := newBlockingCall()
+			call := setUp(t, blocking)
+
+			go call(fixBlockedInstanceID)
+			waitFor(t, blocking.entered, "request for blocked instance not started")
+
+			// WHEN
+			otherDone := make(chan struct{})
+			go func() {
+				call(fixOtherInstanceID)
+				close(otherDone)
+			}()
+
+			// THEN
+			waitFor(t, otherDone, "request for other instance blocked by another instance")
+			close(blocking.release)
+		})
+
+		t.Run(tn+"/SameInstanceSerialized", func(t *testing.T) {
+			// GIVEN
+			blocking := newBlockingCall()
+			call := setUp(t, blocking)
+
+			firstDone := make(chan struct{})
+			go func() {
+				call(fixBlockedInstanceID)
+				close(firstDone)
+			}()
+			waitFor(t, blocking.entered, "request for blocked instance not started")
+
+			// WHEN
+			secondDone := make(chan struct{})
+			go func() {
+				call(fixBlockedInstanceID)
+				close(secondDone)
+			}()
+
+			// THEN
+			select {
+			case <-secondDone:
+				t.Fatal("second request for the same instance processed in parallel")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			close(blocking.release)
+			waitFor(t, firstDone, "first request not finished")
+			waitFor(t, secondDone, "second request not finished after the first one")
+		})
+	}
+}
+
+func fixLockedProvisionCall(t *testing.T, blocking *blockingCall) func(iID internal.InstanceID) {
+	fixErr := errors.New("fix err")
+	isgMock := &automock.InstanceStateGetter{}
+	isgMock.On("IsProvisioned", fixBlockedInstanceID).Run(blocking.Run).Return(false, fixErr).Once()
+	isgMock.On("IsProvisioned", mock.Anything).Return(false, fixErr)
+
+	svc := broker.NewProvisionService(&automock.AddonStorage{}, &automock.ChartGetter{}, &automock.InstanceStorage{}, isgMock,
+		&automock.OperationStorage{}, &automock.OperationStorage{}, &automock.HelmClient{}, fixUnexpectedOpIDProvider(t), spy.NewLogDummy())
+
+	return func(iID internal.InstanceID) {
+		svc.Provision(context.Background(), *broker.NewOSBContext("", "v1"), &osb.ProvisionRequest{InstanceID: string(iID), AcceptsIncomplete: true})
+	}
+}
+
+func fixLockedUpdateCall(t *testing.T, blocking *blockingCall) func(iID internal.InstanceID) {
+	fixErr := errors.New("fix err")
+	isgMock := &automock.InstanceStateGetter{}
+	isgMock.On("IsProvisioned", fixBlockedInstanceID).Run(blocking.Run).Return(false, fixErr).Once()
+	isgMock.On("IsProvisioned", mock.Anything).Return(false, fixErr)
+
+	svc := broker.NewUpdateService(&automock.AddonStorage{}, &automock.ChartGetter{}, &automock.InstanceStorage{}, isgMock,
+		&automock.OperationStorage{}, &automock.OperationStorage{}, &automock.HelmClient{}, fixUnexpectedOpIDProvider(t), spy.NewLogDummy())
+
+	return func(iID internal.InstanceID) {
+		svc.Update(context.Background(), *broker.NewOSBContext("", "v1"), &broker.UpdateInstanceRequest{UpdateInstanceRequest: osb.UpdateInstanceRequest{InstanceID: string(iID), AcceptsIncomplete: true}})
+	}
+}
+
+func fixLockedDeprovisionCall(t *testing.T, blocking *blockingCall) func(iID internal.InstanceID) {
+	fixErr := errors.New("fix err")
+	isgMock := &automock.InstanceStateGetter{}
+	isgMock.On("IsDeprovisioned", fixBlockedInstanceID).Run(blocking.Run).Return(false, fixErr).Once()
+	isgMock.On("IsDeprovisioned", mock.Anything).Return(false, fixErr)
+
+	svc := broker.NewDeprovisionService(&automock.InstanceStorage{}, &automock.OperationStorage{}, &automock.OperationStorage{},
+		&automock.InstanceBindDataStorage{}, &automock.HelmClient{}, fixUnexpectedOpIDProvider(t), isgMock)
+
+	return func(iID internal.InstanceID) {
+		svc.Deprovision(context.Background(), *broker.NewOSBContext("", "v1"), &osb.DeprovisionRequest{InstanceID: string(iID), AcceptsIncomplete: true})
+	}
+}
+
+func fixLockedBindCall(t *testing.T, blocking *blockingCall) func(iID internal.InstanceID) {
+	fixErr := errors.New("fix err")
+	bsgMock := &automock.BindStateGetter{}
+	bsgMock.On("IsBindingInProgress", fixBlockedInstanceID, mock.Anything).Run(blocking.Run).Return(internal.OperationID(""), false, fixErr).Once()
+	bsgMock.On("IsBindingInProgress", mock.Anything, mock.Anything).Return(internal.OperationID(""), false, fixErr)
+
+	svc := broker.NewBindService(&automock.AddonStorage{}, &automock.ChartGetter{}, &automock.InstanceStorage{}, &automock.InstanceBindDataStorage{},
+		&automock.BindTemplateRenderer{}, &automock.BindTemplateResolver{}, bsgMock, &automock.BindOperationStorage{}, fixUnexpectedOpIDProvider(t))
+
+	return func(iID internal.InstanceID) {
+		svc.Bind(context.Background(), *broker.NewOSBContext("", "v1"), &osb.BindRequest{
+			InstanceID:        string(iID),
+			BindingID:         "binding-id",
+			ServiceID:         "service-id",
+			PlanID:            "plan-id",
+			AcceptsIncomplete: true,
+		})
+	}
+}
+
+func fixUnexpectedOpIDProvider(t *testing.T) func() (internal.OperationID, error) {
+	return func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return "", nil
+	}
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}, failMsg string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal(failMsg)
+	}
+}
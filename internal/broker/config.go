@@ -0,0 +1,18 @@
+package broker
+
+import "time"
+
+// defaultSynchronousOperationTimeout is used when the timeout of synchronous operations is not configured
+const defaultSynchronousOperationTimeout = 30 * time.Second
+
+// Config holds configuration of the broker
+type Config struct {
+	OperationQueue  OperationQueueConfig
+	OperationReaper OperationReaperConfig
+	DriftReconciler DriftReconcilerConfig
+	// AtomicProvisioning enables removing the helm release and the instance when the provisioning fails
+	AtomicProvisioning bool
+	// SynchronousOperationTimeout defines how long the synchronous provisioning and deprovisioning requests wait
+	// for the operation result, the operation is continued asynchronously after the timeout
+	SynchronousOperationTimeout time.Duration
+}
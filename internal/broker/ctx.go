@@ -2,6 +2,9 @@ package broker
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
 	"strings"
 
 	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
@@ -12,11 +15,28 @@ import (
 type contextKey int
 
 const (
-	osbAPIVersion = "2.14"
-
 	osbContextKey contextKey = 5001
 )
 
+// supportedOSBAPIVersions lists versions of the Open Service Broker API accepted in the X-Broker-API-Version header
+var supportedOSBAPIVersions = []string{"2.13", "2.14", "2.15", "2.16"}
+
+// osbAPIFeature is the part of the Open Service Broker API which is not available in all supported versions
+type osbAPIFeature string
+
+const (
+	// osbAPIFeatureInstanceRetrieval allows to fetch the service instance by the Platform
+	osbAPIFeatureInstanceRetrieval osbAPIFeature = "instance retrieval"
+	// osbAPIFeatureMaintenanceInfo allows to upgrade service instances to the plan maintenance info
+	osbAPIFeatureMaintenanceInfo osbAPIFeature = "maintenance info"
+)
+
+// osbAPIFeatureMinorVersion defines the minor version of the Open Service Broker API in which the feature was introduced
+var osbAPIFeatureMinorVersion = map[osbAPIFeature]int{
+	osbAPIFeatureInstanceRetrieval: 14,
+	osbAPIFeatureMaintenanceInfo:   15,
+}
+
 // OsbContext contains data sent in X-Broker-API-Version and X-Broker-API-Originating-Identity HTTP headers.
 type OsbContext struct {
 	APIVersion          string
@@ -25,10 +45,29 @@ type OsbContext struct {
 }
 
 func (ctx *OsbContext) validateAPIVersion() error {
-	if ctx.APIVersion != osbAPIVersion {
-		return errors.Errorf("while checking 'X-Broker-API-Version' header, should be %s, got %s", osbAPIVersion, ctx.APIVersion)
+	for _, version := range supportedOSBAPIVersions {
+		if ctx.APIVersion == version {
+			return nil
+		}
 	}
-	return nil
+	return errors.Errorf("while checking 'X-Broker-API-Version' header, should be one of %s, got %s", strings.Join(supportedOSBAPIVersions, ", "), ctx.APIVersion)
+}
+
+// supports checks if the feature is available in the Open Service Broker API version of the request
+func (ctx *OsbContext) supports(feature osbAPIFeature) bool {
+	minor, err := ctx.apiMinorVersion()
+	if err != nil {
+		return false
+	}
+	return minor >= osbAPIFeatureMinorVersion[feature]
+}
+
+func (ctx *OsbContext) apiMinorVersion() (int, error) {
+	parts := strings.SplitN(ctx.APIVersion, ".", 2)
+	if len(parts) != 2 || parts[0] != "2" {
+		return 0, errors.Errorf("unsupported API version %q", ctx.APIVersion)
+	}
+	return strconv.Atoi(parts[1])
 }
 
 func (ctx *OsbContext) validateOriginatingIdentity() error {
@@ -38,6 +77,45 @@ func (ctx *OsbContext) validateOriginatingIdentity() error {
 	return nil
 }
 
+// OriginatingUser is the Kubernetes user on whose behalf the Platform sent the request.
+// It is decoded from the X-Broker-API-Originating-Identity header.
+type OriginatingUser struct {
+	Username string              `json:"username"`
+	UID      string              `json:"uid"`
+	Groups   []string            `json:"groups"`
+	Extra    map[string][]string `json:"extra"`
+}
+
+// originatingUser decodes the originating identity. Nil is returned when the header was not sent.
+func (ctx *OsbContext) originatingUser() (*OriginatingUser, error) {
+	if ctx.OriginatingIdentity == "" {
+		return nil, nil
+	}
+	parts := strings.SplitN(ctx.OriginatingIdentity, " ", 2)
+	if len(parts) != 2 || parts[0] != osb.PlatformKubernetes {
+		return nil, errors.Errorf("originating identity should be in format '%s <base64 encoded user>', got %s", osb.PlatformKubernetes, ctx.OriginatingIdentity)
+	}
+	raw, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "while decoding originating identity")
+	}
+	user := &OriginatingUser{}
+	if err := json.Unmarshal(raw, user); err != nil {
+		return nil, errors.Wrap(err, "while unmarshalling originating identity")
+	}
+	return user, nil
+}
+
+// requester returns the user recorded on operations. The empty requester is returned when the originating identity
+// was not sent or cannot be decoded, so requests of Platforms which do not send the Kubernetes user are still processed.
+func (ctx *OsbContext) requester() internal.OperationRequester {
+	user, err := ctx.originatingUser()
+	if err != nil || user == nil {
+		return internal.OperationRequester{}
+	}
+	return internal.OperationRequester{Username: user.Username, UID: user.UID}
+}
+
 func contextWithOSB(ctx context.Context, osbCtx OsbContext) context.Context {
 	return context.WithValue(ctx, osbContextKey, osbCtx)
 }
@@ -0,0 +1,52 @@
+package broker
+
+import (
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+// renderDashboardURL renders the dashboard URL of the instance from the plan template.
+// Empty URL is returned when the plan does not define the template.
+func renderDashboardURL(renderer dashboardURLRenderer, plan internal.AddonPlan, instance *internal.Instance, ch *chart.Chart) (string, error) {
+	if len(plan.DashboardURLTemplate) == 0 || renderer == nil {
+		return "", nil
+	}
+
+	url, err := renderer.RenderDashboardURL(plan.DashboardURLTemplate, instance, ch)
+	if err != nil {
+		return "", errors.Wrap(err, "while rendering dashboard URL")
+	}
+	return url, nil
+}
+
+// dashboardURLPreview renders the dashboard URL which is returned before the release is ready
+type dashboardURLPreview func() (string, error)
+
+// newDashboardURLPreview returns the preview of the dashboard URL of the instance which release will be installed or upgraded
+// with given values. The template can look up objects in the cluster, so the preview is rendered after the instance lock is released.
+func newDashboardURLPreview(cg chartGetter, renderer dashboardURLRenderer, brokerNamespace internal.Namespace, plan internal.AddonPlan, instance internal.Instance, values internal.ChartValues) dashboardURLPreview {
+	return func() (string, error) {
+		return previewDashboardURL(cg, renderer, brokerNamespace, plan, instance, values)
+	}
+}
+
+// previewDashboardURL renders the dashboard URL with the values which the release will be installed or upgraded with,
+// so it can be returned before the release is ready. The URL is rendered again when the release is ready.
+func previewDashboardURL(cg chartGetter, renderer dashboardURLRenderer, brokerNamespace internal.Namespace, plan internal.AddonPlan, instance internal.Instance, values internal.ChartValues) (string, error) {
+	if len(plan.DashboardURLTemplate) == 0 || renderer == nil {
+		return "", nil
+	}
+
+	ch, err := cg.Get(brokerNamespace, plan.ChartRef.Name, plan.ChartRef.Version)
+	if err != nil {
+		return "", errors.Wrap(err, "while getting chart from storage")
+	}
+
+	instance.ReleaseInfo = internal.ReleaseInfo{
+		Revision:     instance.ReleaseInfo.Revision,
+		ConfigValues: values,
+	}
+	return renderDashboardURL(renderer, plan, &instance, ch)
+}
@@ -3,7 +3,8 @@ package broker
 import (
 	"context"
 	"fmt"
-	"sync"
+	"net/http"
+	"time"
 
 	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
 	"github.com/kyma-project/helm-broker/internal"
@@ -13,6 +14,7 @@ import (
 )
 
 type deprovisionService struct {
+	addonIDGetter           addonIDGetter
 	instanceGetter          instanceGetter
 	instanceRemover         instanceRemover
 	instanceStateGetter     instanceStateDeprovisionGetter
@@ -21,62 +23,90 @@ type deprovisionService struct {
 	instanceBindDataRemover instanceBindDataRemover
 	operationIDProvider     func() (internal.OperationID, error)
 	helmDeleter             helmDeleter
+	instanceLocker          *instanceLocker
+	operationQueue          *operationQueue
+
+	// synchronousTimeout defines how long the deprovisioning of the plan which allows for synchronous operations is awaited
+	synchronousTimeout time.Duration
 
-	mu  sync.Mutex
 	log logrus.FieldLogger
 
 	testHookAsyncCalled func(internal.OperationID)
 }
 
+// Deprovision deprovisions the instance asynchronously. When the Platform does not accept the asynchronous operation mode
+// and the plan allows for synchronous operations, the deprovisioning is awaited. The response is not asynchronous
+// and has the operation key when the deprovisioning is finished within the timeout.
 func (svc *deprovisionService) Deprovision(ctx context.Context, osbCtx OsbContext, req *osb.DeprovisionRequest) (*osb.DeprovisionResponse, error) {
-	if !req.AcceptsIncomplete {
-		return nil, errors.New("asynchronous operation mode required")
+	resp, result, err := svc.deprovision(ctx, osbCtx, req)
+	if err != nil || result == nil {
+		return resp, err
 	}
 
-	// Single deprovisioning is supported concurrently.
-	// TODO: switch to lock per instanceID
-	svc.mu.Lock()
-	defer svc.mu.Unlock()
+	res, finished := waitForOperation(ctx, result, svc.synchronousTimeout)
+	switch {
+	case !finished:
+		svc.log.Infof("Deprovisioning of instance %s was not finished within %v, it is continued asynchronously", req.InstanceID, svc.synchronousTimeout)
+		return resp, nil
+	case res.state == internal.OperationStateFailed:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(res.description)}
+	}
 
+	resp.Async = false
+	return resp, nil
+}
+
+// deprovision starts the deprovisioning, the result channel is returned when the deprovisioning must be awaited
+func (svc *deprovisionService) deprovision(ctx context.Context, osbCtx OsbContext, req *osb.DeprovisionRequest) (*osb.DeprovisionResponse, <-chan operationResult, error) {
 	iID := internal.InstanceID(req.InstanceID)
 
+	svc.instanceLocker.Lock(iID)
+	defer svc.instanceLocker.Unlock(iID)
+
 	switch state, err := svc.instanceStateGetter.IsDeprovisioned(iID); true {
 	case IsNotFoundError(err):
-		return nil, err
+		return nil, nil, err
 	case err != nil:
-		return nil, errors.Wrap(err, "while checking if instance is already deprovisioned")
+		return nil, nil, errors.Wrap(err, "while checking if instance is already deprovisioned")
 	case state:
-		return &osb.DeprovisionResponse{Async: false}, nil
+		return &osb.DeprovisionResponse{Async: false}, nil, nil
 	}
 
 	switch opIDInProgress, inProgress, err := svc.instanceStateGetter.IsDeprovisioningInProgress(iID); true {
 	case IsNotFoundError(err):
-		return nil, err
+		return nil, nil, err
 	case err != nil:
-		return nil, errors.Wrap(err, "while checking if instance is being deprovisioned")
+		return nil, nil, errors.Wrap(err, "while checking if instance is being deprovisioned")
+	case inProgress && !req.AcceptsIncomplete:
+		// the deprovisioning in progress can be reported only asynchronously, also for the plans with synchronous operations
+		return nil, nil, asyncRequiredError()
 	case inProgress:
 		opKeyInProgress := osb.OperationKey(opIDInProgress)
-		return &osb.DeprovisionResponse{Async: true, OperationKey: &opKeyInProgress}, nil
+		return &osb.DeprovisionResponse{Async: true, OperationKey: &opKeyInProgress}, nil, nil
 	}
 
 	id, err := svc.operationIDProvider()
 	if err != nil {
-		return nil, errors.Wrap(err, "while generating ID for operation")
+		return nil, nil, errors.Wrap(err, "while generating ID for operation")
 	}
 	opID := internal.OperationID(id)
 
 	i, err := svc.instanceGetter.Get(iID)
 	switch {
 	case IsNotFoundError(err):
-		return nil, err
+		return nil, nil, err
 	case err != nil:
-		return nil, errors.Wrap(err, "while getting instance")
+		return nil, nil, errors.Wrap(err, "while getting instance")
 	}
 
 	// TODO: check if svcID/planID from request are matching the one from instance
 	//svcID := internal.ServiceID(req.ServiceID)
 	//svcPlanID := internal.ServicePlanID(req.PlanID)
 
+	if !req.AcceptsIncomplete && !svc.allowsSynchronousOperations(osbCtx, i) {
+		return nil, nil, asyncRequiredError()
+	}
+
 	op := internal.InstanceOperation{
 		InstanceID:  iID,
 		OperationID: opID,
@@ -85,13 +115,14 @@ func (svc *deprovisionService) Deprovision(ctx context.Context, osbCtx OsbContex
 		ProvisioningParameters: &internal.RequestParameters{
 			Data: make(map[string]interface{}),
 		},
+		RequestedBy: osbCtx.requester(),
 	}
 
 	if err := svc.operationInserter.Insert(&op); err != nil {
-		return nil, errors.Wrap(err, "while inserting instance operation to storage")
+		return nil, nil, errors.Wrap(err, "while inserting instance operation to storage")
 	}
 
-	svc.doAsync(ctx, *i, opID)
+	result := svc.doAsync(ctx, *i, opID)
 
 	opKey := osb.OperationKey(op.OperationID)
 	resp := &osb.DeprovisionResponse{
@@ -99,26 +130,49 @@ func (svc *deprovisionService) Deprovision(ctx context.Context, osbCtx OsbContex
 		Async:        true,
 	}
 
-	return resp, nil
+	if req.AcceptsIncomplete {
+		return resp, nil, nil
+	}
+	return resp, result, nil
+}
+
+// allowsSynchronousOperations checks if the plan of the instance allows for synchronous operations,
+// the asynchronous operation mode is required when the addon was removed from the repository.
+func (svc *deprovisionService) allowsSynchronousOperations(osbCtx OsbContext, instance *internal.Instance) bool {
+	addon, err := svc.addonIDGetter.GetByID(osbCtx.BrokerNamespace, internal.AddonID(instance.ServiceID))
+	if err != nil {
+		if !IsNotFoundError(err) {
+			svc.log.Errorf("Cannot get addon of instance %s: %v", instance.ID, err)
+		}
+		return false
+	}
+	plan, found := addon.Plans[internal.AddonPlanID(instance.ServicePlanID)]
+	return found && plan.SynchronousOperations
 }
 
-func (svc *deprovisionService) doAsync(ctx context.Context, inst internal.Instance, opID internal.OperationID) {
+func (svc *deprovisionService) doAsync(ctx context.Context, inst internal.Instance, opID internal.OperationID) <-chan operationResult {
 	if svc.testHookAsyncCalled != nil {
 		svc.testHookAsyncCalled(opID)
 	}
-	go svc.do(ctx, inst, opID)
+	result := make(chan operationResult, 1)
+	svc.operationQueue.Enqueue(asyncOperation{
+		operationID: opID,
+		namespace:   inst.Namespace,
+		do:          func(ctx context.Context) { result <- svc.do(ctx, inst, opID) },
+	})
+	return result
 }
 
 // do is called asynchronously
-func (svc *deprovisionService) do(ctx context.Context, inst internal.Instance, opID internal.OperationID) {
+func (svc *deprovisionService) do(ctx context.Context, inst internal.Instance, opID internal.OperationID) operationResult {
 	iID := inst.ID
 	fDo := func() error {
-		err := svc.helmDeleter.Delete(inst.ReleaseName, inst.Namespace)
+		err := svc.helmDeleter.Delete(inst.ReleaseName, inst.Namespace, inst.HelmOptions)
 		if err != nil && !errors.Is(err, helmErrors.ErrReleaseNotFound) {
 			return errors.Wrapf(err, "while deleting helm release %q", inst.ReleaseName)
 		}
 
-		err = svc.instanceBindDataRemover.Remove(inst.ID)
+		err = svc.instanceBindDataRemover.RemoveAll(inst.ID)
 		switch {
 		// we are not checking if instance was bindable and because of that NotFound error is also in happy path
 		// BEWARE: such solution can produce false positive errors e.g.
@@ -150,6 +204,7 @@ func (svc *deprovisionService) do(ctx context.Context, inst internal.Instance, o
 
 	if err := svc.operationUpdater.UpdateStateDesc(iID, opID, opState, &opDesc); err != nil {
 		svc.log.Errorf("Cannot update state for instance [%s]: [%v]", iID, err)
-		return
 	}
+
+	return operationResult{state: opState, description: opDesc}
 }
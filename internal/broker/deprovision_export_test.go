@@ -12,6 +12,8 @@ func NewDeprovisionService(is instanceStorage, oi operationInserter, ou operatio
 		operationIDProvider:     oIDProv,
 		instanceBindDataRemover: ibdr,
 		helmDeleter:             hd,
+		instanceLocker:          newInstanceLocker(),
+		operationQueue:          newStartedOperationQueue(),
 	}
 }
 
@@ -3,6 +3,7 @@ package broker_test
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -38,7 +39,7 @@ func TestDeprovisionServiceDeprovisionSuccess(t *testing.T) {
 
 	ts.HelmClientMock.ExpectOnDelete(ts.Exp.ReleaseName, ts.Exp.Namespace).Once()
 
-	ts.InstBindDataMock.ExpectOnRemove(ts.Exp.InstanceID).Once()
+	ts.InstBindDataMock.ExpectOnRemoveAll(ts.Exp.InstanceID).Once()
 	ts.InstStorageMock.ExpectOnRemove(ts.Exp.InstanceID).Once()
 
 	ts.OpIDProviderFake = func() (internal.OperationID, error) {
@@ -83,9 +84,9 @@ func TestDeprovisionServiceDeprovisionSuccessIfReleaseNotFound(t *testing.T) {
 			close(ts.UpdateStateDescMethodCalled)
 		}).Once()
 
-	ts.HelmClientMock.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace).Return(helmErrors.ErrReleaseNotFound).Once()
+	ts.HelmClientMock.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(helmErrors.ErrReleaseNotFound).Once()
 
-	ts.InstBindDataMock.ExpectOnRemove(ts.Exp.InstanceID).Once()
+	ts.InstBindDataMock.ExpectOnRemoveAll(ts.Exp.InstanceID).Once()
 	ts.InstStorageMock.ExpectOnRemove(ts.Exp.InstanceID).Once()
 
 	ts.OpIDProviderFake = func() (internal.OperationID, error) {
@@ -146,7 +147,7 @@ func TestDeprovisionServiceDeprovisionFailureAsync(t *testing.T) {
 
 			ts.HelmClientMock.ExpectOnDelete(ts.Exp.ReleaseName, ts.Exp.Namespace).Once()
 
-			ts.InstBindDataMock.ExpectErrorRemove(ts.Exp.InstanceID, fixErr).Once()
+			ts.InstBindDataMock.ExpectErrorRemoveAll(ts.Exp.InstanceID, fixErr).Once()
 		},
 		"on instance Remove": func(ts *deprovisionServiceTestSuite) {
 			ts.InstStateGetterMock.ExpectOnIsDeprovisioned(ts.Exp.InstanceID, false).Once()
@@ -162,7 +163,7 @@ func TestDeprovisionServiceDeprovisionFailureAsync(t *testing.T) {
 				}).Once()
 
 			ts.HelmClientMock.ExpectOnDelete(ts.Exp.ReleaseName, ts.Exp.Namespace).Once()
-			ts.InstBindDataMock.ExpectOnRemove(ts.Exp.InstanceID).Once()
+			ts.InstBindDataMock.ExpectOnRemoveAll(ts.Exp.InstanceID).Once()
 
 			ts.InstStorageMock.ExpectErrorRemove(ts.Exp.InstanceID, fixErr).Once()
 		},
@@ -278,6 +279,35 @@ func TestDeprovisionServiceDeprovisionSuccessOnDeprovisioningInProgressInstance(
 	}
 }
 
+func TestDeprovisionServiceDeprovisionFailureAsyncRequiredOnDeprovisioningInProgressInstance(t *testing.T) {
+	// GIVEN
+	ts := newDeprovisionServiceTestSuite(t)
+	ts.SetUp()
+
+	defer ts.AssertExpectations(t)
+
+	ts.InstStateGetterMock.ExpectOnIsDeprovisioned(ts.Exp.InstanceID, false).Once()
+	ts.InstStateGetterMock.ExpectOnIsDeprovisioningInProgress(ts.Exp.InstanceID, ts.Exp.OperationID, true).Once()
+
+	ts.OpIDProviderFake = func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewDeprovisionService(ts.GetAllMocks())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixDeprovisionRequest()
+	req.AcceptsIncomplete = false
+
+	// WHEN
+	resp, err := svc.Deprovision(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	assertHTTPStatusCode(t, http.StatusUnprocessableEntity, err)
+}
+
 func TestDeprovisionServiceDeprovisionFailureNotFoundOnIsDeprovisionedCheck(t *testing.T) {
 	// GIVEN
 	ts := newDeprovisionServiceTestSuite(t)
@@ -0,0 +1,205 @@
+package broker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/kyma-project/helm-broker/internal"
+	yTime "github.com/kyma-project/helm-broker/internal/platform/time"
+)
+
+// DriftReconcilerConfig holds configuration of the reconciler which detects drift between the stored instances and helm releases
+type DriftReconcilerConfig struct {
+	// Interval defines how often the instances and helm releases are compared
+	Interval time.Duration `default:"10m"`
+	// OrphanGC enables uninstalling the releases created by the broker which do not belong to any instance
+	OrphanGC bool
+	// OrphanGracePeriod defines how long the release has to be orphaned before it is uninstalled
+	OrphanGracePeriod time.Duration `default:"1h"`
+}
+
+var (
+	driftMissingReleases = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "helm_broker",
+		Subsystem: "drift",
+		Name:      "missing_releases",
+		Help:      "Number of service instances without the helm release found in the last reconciliation.",
+	})
+	driftOrphanedReleases = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "helm_broker",
+		Subsystem: "drift",
+		Name:      "orphaned_releases",
+		Help:      "Number of helm releases created by the broker without the service instance found in the last reconciliation.",
+	})
+	driftFailedReleases = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "helm_broker",
+		Subsystem: "drift",
+		Name:      "failed_releases",
+		Help:      "Number of service instances with the failed helm release found in the last reconciliation.",
+	})
+	driftDeletedOrphanedReleases = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "helm_broker",
+		Subsystem: "drift",
+		Name:      "deleted_orphaned_releases_total",
+		Help:      "Number of orphaned helm releases uninstalled by the broker.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(driftMissingReleases, driftOrphanedReleases, driftFailedReleases, driftDeletedOrphanedReleases)
+}
+
+type releaseKey struct {
+	namespace internal.Namespace
+	name      internal.ReleaseName
+}
+
+// driftReconciler periodically compares the stored instances with the helm releases. It reports the instances which release
+// is missing or failed and the releases created by the broker which instance does not exist anymore, e.g. because the helm release
+// was uninstalled manually or the instance was removed from the storage when the release could not be deleted.
+// The orphaned releases can be uninstalled after the grace period.
+type driftReconciler struct {
+	cfg DriftReconcilerConfig
+
+	instanceGetter            instanceGetter
+	operationCollectionGetter operationCollectionGetter
+	helmReleaseLister         helmReleaseLister
+	helmDeleter               helmDeleter
+	nowProvider               yTime.NowProvider
+
+	// orphanedSince holds the time when the orphaned release was found for the first time
+	orphanedSince map[releaseKey]time.Time
+
+	log logrus.FieldLogger
+}
+
+// Run reconciles the drift every configured interval until given context is cancelled.
+// The reconciler is disabled when the interval is not set.
+func (r *driftReconciler) Run(ctx context.Context) {
+	if r.cfg.Interval <= 0 {
+		r.log.Info("Interval is not set, drift between instances and helm releases is not reconciled")
+		return
+	}
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reconcile(ctx); err != nil {
+				r.log.Errorf("Cannot reconcile drift between instances and helm releases: %v", err)
+			}
+		}
+	}
+}
+
+// Reconcile finds the missing, failed and orphaned releases, reports them and uninstalls the orphaned releases if it is enabled.
+func (r *driftReconciler) Reconcile(ctx context.Context) error {
+	// releases are listed before instances, the instance is stored before its release is installed,
+	// so a release of the instance which is being provisioned is never treated as orphaned
+	listedAt := r.nowProvider.Now()
+	releases, err := r.helmReleaseLister.ListReleases(internal.ClusterWide)
+	if err != nil {
+		return errors.Wrap(err, "while listing helm releases")
+	}
+
+	instances, err := r.instanceGetter.GetAll()
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+	default:
+		return errors.Wrap(err, "while getting instances")
+	}
+
+	listed := make(map[releaseKey]*release.Release, len(releases))
+	for _, rel := range releases {
+		listed[releaseKey{namespace: internal.Namespace(rel.Namespace), name: internal.ReleaseName(rel.Name)}] = rel
+	}
+
+	var missing, failed int
+	owned := make(map[releaseKey]struct{}, len(instances))
+	for _, inst := range instances {
+		key := releaseKey{namespace: inst.Namespace, name: inst.ReleaseName}
+		owned[key] = struct{}{}
+
+		busy, err := r.hasOperationSince(inst.ID, listedAt)
+		if err != nil {
+			return errors.Wrapf(err, "while checking operations of instance %q", inst.ID)
+		}
+		if busy {
+			continue
+		}
+
+		rel, found := listed[key]
+		switch {
+		case !found:
+			missing++
+			r.log.Warnf("Helm release %q of instance %q is not deployed in namespace %q", inst.ReleaseName, inst.ID, inst.Namespace)
+		case rel.Info != nil && rel.Info.Status == release.StatusFailed:
+			failed++
+			r.log.Warnf("Helm release %q of instance %q in namespace %q is in status %q", inst.ReleaseName, inst.ID, inst.Namespace, rel.Info.Status)
+		}
+	}
+
+	var orphaned int
+	orphanedSince := make(map[releaseKey]time.Time)
+	for key := range listed {
+		if _, found := owned[key]; found || !strings.HasPrefix(string(key.name), releaseNamePrefix) {
+			continue
+		}
+
+		since, found := r.orphanedSince[key]
+		if !found {
+			since = listedAt
+			r.log.Warnf("Helm release %q in namespace %q does not belong to any instance", key.name, key.namespace)
+		}
+
+		if r.cfg.OrphanGC && listedAt.Sub(since) >= r.cfg.OrphanGracePeriod {
+			r.log.Infof("Uninstalling helm release %q in namespace %q which does not belong to any instance since %v", key.name, key.namespace, since)
+			err := r.helmDeleter.Delete(key.name, key.namespace, internal.HelmOptions{})
+			if err == nil {
+				driftDeletedOrphanedReleases.Inc()
+				continue
+			}
+			r.log.Errorf("Cannot uninstall orphaned helm release %q in namespace %q: %v", key.name, key.namespace, err)
+		}
+
+		orphaned++
+		orphanedSince[key] = since
+	}
+	r.orphanedSince = orphanedSince
+
+	driftMissingReleases.Set(float64(missing))
+	driftFailedReleases.Set(float64(failed))
+	driftOrphanedReleases.Set(float64(orphaned))
+
+	return nil
+}
+
+// hasOperationSince returns true if the instance operation is in progress or was started after given time,
+// the release of such instance can be not installed yet or not listed
+func (r *driftReconciler) hasOperationSince(iID internal.InstanceID, t time.Time) (bool, error) {
+	ops, err := r.operationCollectionGetter.GetAll(iID)
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+		return false, nil
+	default:
+		return false, err
+	}
+
+	for _, op := range ops {
+		if op.State == internal.OperationStateInProgress || op.CreatedAt.After(t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
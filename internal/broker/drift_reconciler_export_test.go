@@ -0,0 +1,27 @@
+package broker
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func NewDriftReconciler(cfg DriftReconcilerConfig, ig instanceGetter, ocg operationCollectionGetter, hc helmClient,
+	nowProvider func() time.Time, log logrus.FieldLogger) *driftReconciler {
+	return &driftReconciler{
+		cfg:                       cfg,
+		instanceGetter:            ig,
+		operationCollectionGetter: ocg,
+		helmReleaseLister:         hc,
+		helmDeleter:               hc,
+		nowProvider:               nowProvider,
+		log:                       log,
+	}
+}
+
+// DriftMetrics returns values of the missing, failed and orphaned releases gauges and the deleted orphaned releases counter
+func DriftMetrics() (missing, failed, orphaned, deleted float64) {
+	return testutil.ToFloat64(driftMissingReleases), testutil.ToFloat64(driftFailedReleases),
+		testutil.ToFloat64(driftOrphanedReleases), testutil.ToFloat64(driftDeletedOrphanedReleases)
+}
@@ -0,0 +1,177 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/broker/automock"
+	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
+	"github.com/kyma-project/helm-broker/internal/storage"
+)
+
+func TestDriftReconcilerReportsDrift(t *testing.T) {
+	// GIVEN
+	ts := newDriftReconcilerTestSuite(t)
+	ts.InsertInstance("deployed", internal.OperationStateSucceeded)
+	ts.InsertInstance("failed", internal.OperationStateSucceeded)
+	ts.InsertInstance("missing", internal.OperationStateSucceeded)
+	ts.InsertInstance("provisioning", internal.OperationStateInProgress)
+
+	ts.HelmClient.On("ListReleases", internal.ClusterWide).Return([]*release.Release{
+		fixDriftRelease("hb-redis-micro-deployed", release.StatusDeployed),
+		fixDriftRelease("hb-redis-micro-failed", release.StatusFailed),
+		fixDriftRelease("hb-redis-micro-orphaned", release.StatusDeployed),
+		fixDriftRelease("not-managed-by-broker", release.StatusDeployed),
+	}, nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	_, _, _, deletedBefore := broker.DriftMetrics()
+
+	// WHEN
+	err := ts.Reconciler(broker.DriftReconcilerConfig{}).Reconcile(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+	missing, failed, orphaned, deleted := broker.DriftMetrics()
+	assert.Equal(t, float64(1), missing)
+	assert.Equal(t, float64(1), failed)
+	assert.Equal(t, float64(1), orphaned)
+	assert.Equal(t, deletedBefore, deleted)
+
+	ts.LogSink.AssertLogged(t, logrus.WarnLevel, `Helm release "hb-redis-micro-missing" of instance "missing" is not deployed in namespace "stage"`)
+	ts.LogSink.AssertLogged(t, logrus.WarnLevel, `Helm release "hb-redis-micro-failed" of instance "failed" in namespace "stage" is in status "failed"`)
+	ts.LogSink.AssertLogged(t, logrus.WarnLevel, `Helm release "hb-redis-micro-orphaned" in namespace "stage" does not belong to any instance`)
+}
+
+func TestDriftReconcilerDeletesOrphansAfterGracePeriod(t *testing.T) {
+	// GIVEN
+	ts := newDriftReconcilerTestSuite(t)
+	ts.HelmClient.On("ListReleases", internal.ClusterWide).Return([]*release.Release{
+		fixDriftRelease("hb-redis-micro-orphaned", release.StatusDeployed),
+	}, nil).Times(3)
+	ts.HelmClient.On("Delete", internal.ReleaseName("hb-redis-micro-orphaned"), internal.Namespace("stage"), internal.HelmOptions{}).Return(nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	reconciler := ts.Reconciler(broker.DriftReconcilerConfig{OrphanGC: true, OrphanGracePeriod: time.Hour})
+	_, _, _, deletedBefore := broker.DriftMetrics()
+
+	// WHEN
+	require.NoError(t, reconciler.Reconcile(context.Background()))
+	ts.Elapse(30 * time.Minute)
+	require.NoError(t, reconciler.Reconcile(context.Background()))
+
+	// THEN
+	_, _, orphaned, deleted := broker.DriftMetrics()
+	assert.Equal(t, float64(1), orphaned)
+	assert.Equal(t, deletedBefore, deleted)
+
+	// WHEN
+	ts.Elapse(30 * time.Minute)
+	require.NoError(t, reconciler.Reconcile(context.Background()))
+
+	// THEN
+	_, _, orphaned, deleted = broker.DriftMetrics()
+	assert.Equal(t, float64(0), orphaned)
+	assert.Equal(t, deletedBefore+1, deleted)
+}
+
+func TestDriftReconcilerKeepsOrphanWhenDeleteFails(t *testing.T) {
+	// GIVEN
+	ts := newDriftReconcilerTestSuite(t)
+	ts.HelmClient.On("ListReleases", internal.ClusterWide).Return([]*release.Release{
+		fixDriftRelease("hb-redis-micro-orphaned", release.StatusDeployed),
+	}, nil).Once()
+	ts.HelmClient.On("Delete", internal.ReleaseName("hb-redis-micro-orphaned"), internal.Namespace("stage"), internal.HelmOptions{}).Return(errors.New("forbidden")).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	// WHEN
+	err := ts.Reconciler(broker.DriftReconcilerConfig{OrphanGC: true}).Reconcile(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+	_, _, orphaned, _ := broker.DriftMetrics()
+	assert.Equal(t, float64(1), orphaned)
+	ts.LogSink.AssertLogged(t, logrus.ErrorLevel, `Cannot uninstall orphaned helm release "hb-redis-micro-orphaned" in namespace "stage": forbidden`)
+}
+
+func TestDriftReconcilerFailsOnListError(t *testing.T) {
+	// GIVEN
+	ts := newDriftReconcilerTestSuite(t)
+	ts.HelmClient.On("ListReleases", internal.ClusterWide).Return(nil, errors.New("cluster unreachable")).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	// WHEN
+	err := ts.Reconciler(broker.DriftReconcilerConfig{}).Reconcile(context.Background())
+
+	// THEN
+	assert.EqualError(t, err, "while listing helm releases: cluster unreachable")
+}
+
+type driftReconcilerTestSuite struct {
+	t              *testing.T
+	StorageFactory storage.Factory
+	HelmClient     *automock.HelmClient
+	LogSink        *spy.LogSink
+	now            time.Time
+}
+
+func newDriftReconcilerTestSuite(t *testing.T) *driftReconcilerTestSuite {
+	sFact, err := storage.NewFactory(storage.NewConfigListAllMemory())
+	require.NoError(t, err)
+
+	return &driftReconcilerTestSuite{
+		t:              t,
+		StorageFactory: sFact,
+		HelmClient:     &automock.HelmClient{},
+		LogSink:        spy.NewLogSink(),
+		now:            time.Now(),
+	}
+}
+
+// InsertInstance inserts the instance with given ID and its provisioning operation in given state
+func (ts *driftReconcilerTestSuite) InsertInstance(id internal.InstanceID, opState internal.OperationState) {
+	require.NoError(ts.t, ts.StorageFactory.Instance().Insert(&internal.Instance{
+		ID:          id,
+		ReleaseName: internal.ReleaseName("hb-redis-micro-" + string(id)),
+		Namespace:   "stage",
+	}))
+	require.NoError(ts.t, ts.StorageFactory.InstanceOperation().Insert(&internal.InstanceOperation{
+		InstanceID:  id,
+		OperationID: "fix-op-id",
+		Type:        internal.OperationTypeCreate,
+		State:       opState,
+	}))
+}
+
+// Elapse moves the time seen by the reconciler forward
+func (ts *driftReconcilerTestSuite) Elapse(d time.Duration) {
+	ts.now = ts.now.Add(d)
+}
+
+// Reconciler returns the reconciler which sees the operations inserted by the suite as started an hour ago
+func (ts *driftReconcilerTestSuite) Reconciler(cfg broker.DriftReconcilerConfig) driftReconciler {
+	ts.now = time.Now().Add(time.Hour)
+	return broker.NewDriftReconciler(cfg, ts.StorageFactory.Instance(), ts.StorageFactory.InstanceOperation(), ts.HelmClient,
+		func() time.Time { return ts.now }, ts.LogSink.Logger)
+}
+
+type driftReconciler interface {
+	Reconcile(ctx context.Context) error
+}
+
+func fixDriftRelease(name string, status release.Status) *release.Release {
+	return &release.Release{
+		Name:      name,
+		Namespace: "stage",
+		Info:      &release.Info{Status: status},
+	}
+}
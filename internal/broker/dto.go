@@ -1,6 +1,7 @@
 package broker
 
 import (
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
 	"github.com/kyma-project/helm-broker/internal"
 	"github.com/pkg/errors"
 )
@@ -35,18 +36,70 @@ func (params *ProvisionRequestDTO) Validate() error {
 
 // ProvisionSuccessResponseDTO represents response after successful provisioning
 type ProvisionSuccessResponseDTO struct {
-	DashboardURL *string               `json:"dashboard_url"`
+	DashboardURL *string               `json:"dashboard_url,omitempty"`
 	Operation    *internal.OperationID `json:"operation,omitempty"`
 }
 
+// UpdateRequestDTO represents update request
+type UpdateRequestDTO struct {
+	ServiceID       internal.ServiceID      `json:"service_id"`
+	PlanID          *internal.ServicePlanID `json:"plan_id,omitempty"`
+	Parameters      map[string]interface{}  `json:"parameters,omitempty"`
+	Context         contextDTO              `json:"context,omitempty"`
+	MaintenanceInfo *MaintenanceInfoDTO     `json:"maintenance_info,omitempty"`
+}
+
+// Validate validates necessary update parameters
+func (params *UpdateRequestDTO) Validate() error {
+	if params.ServiceID == "" {
+		return errors.New("ServiceID must be non-empty string")
+	}
+	return nil
+}
+
+// UpdateSuccessResponseDTO represents response after successfully accepted update
+type UpdateSuccessResponseDTO struct {
+	DashboardURL *string               `json:"dashboard_url,omitempty"`
+	Operation    *internal.OperationID `json:"operation,omitempty"`
+}
+
+// GetInstanceSuccessResponseDTO represents response with the service instance
+type GetInstanceSuccessResponseDTO struct {
+	ServiceID    internal.ServiceID     `json:"service_id"`
+	PlanID       internal.ServicePlanID `json:"plan_id"`
+	DashboardURL *string                `json:"dashboard_url,omitempty"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty"`
+}
+
 // DeprovisionSuccessResponseDTO represents response after successful deprovisioning
 type DeprovisionSuccessResponseDTO struct {
 	Operation *internal.OperationID `json:"operation,omitempty"`
 }
 
 // CatalogSuccessResponseDTO represents info about successful catalog response
-// TODO: implement me based on osb.CatalogResponse
-type CatalogSuccessResponseDTO struct{}
+type CatalogSuccessResponseDTO struct {
+	Services []ServiceDTO `json:"services"`
+}
+
+// ServiceDTO represents the service in the catalog. It extends the osb.Service with the plan fields
+// which are not supported by the OSB client.
+type ServiceDTO struct {
+	osb.Service
+	Plans []PlanDTO `json:"plans"`
+}
+
+// PlanDTO represents the service plan in the catalog
+type PlanDTO struct {
+	osb.Plan
+	MaintenanceInfo *MaintenanceInfoDTO `json:"maintenance_info,omitempty"`
+}
+
+// MaintenanceInfoDTO represents the version of the addon which is used to provision or upgrade service instances.
+// Available since the Open Service Broker API v2.15.
+type MaintenanceInfoDTO struct {
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
 
 // LastOperationSuccessResponseDTO represents info response about last successful operation
 type LastOperationSuccessResponseDTO struct {
@@ -54,6 +107,55 @@ type LastOperationSuccessResponseDTO struct {
 	Description *string                 `json:"description,omitempty"`
 }
 
+// InstanceStatusSuccessResponseDTO represents response with the health of the service instance release
+type InstanceStatusSuccessResponseDTO struct {
+	InstanceID internal.InstanceID `json:"instance_id"`
+	Healthy    bool                `json:"healthy"`
+	Resources  []ResourceHealthDTO `json:"resources"`
+}
+
+// ResourceHealthDTO represents the health of a single resource of the release
+type ResourceHealthDTO struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// ImportInstanceRequestDTO represents request for importing the helm release as the service instance
+type ImportInstanceRequestDTO struct {
+	ReleaseName internal.ReleaseName `json:"release_name"`
+	Namespace   internal.Namespace   `json:"namespace"`
+	AddonID     internal.AddonID     `json:"addon_id"`
+	PlanID      internal.AddonPlanID `json:"plan_id"`
+}
+
+// Validate validates necessary import parameters
+func (params *ImportInstanceRequestDTO) Validate() error {
+	switch {
+	case params.ReleaseName == "":
+		return errors.New("release_name must be non-empty string")
+	case params.Namespace == "":
+		return errors.New("namespace must be non-empty string")
+	case params.AddonID == "":
+		return errors.New("addon_id must be non-empty string")
+	case params.PlanID == "":
+		return errors.New("plan_id must be non-empty string")
+	}
+	return nil
+}
+
+// ImportInstanceSuccessResponseDTO represents response with the service instance created from the helm release
+type ImportInstanceSuccessResponseDTO struct {
+	InstanceID   internal.InstanceID    `json:"instance_id"`
+	ServiceID    internal.ServiceID     `json:"service_id"`
+	PlanID       internal.ServicePlanID `json:"plan_id"`
+	ReleaseName  internal.ReleaseName   `json:"release_name"`
+	Namespace    internal.Namespace     `json:"namespace"`
+	Revision     int                    `json:"revision"`
+	DashboardURL string                 `json:"dashboard_url,omitempty"`
+}
+
 // BindSuccessResponseDTO represents response with credentials for service instance after successful binding
 type BindSuccessResponseDTO struct {
 	// Credentials is a free-form hash of credentials that can be used by
@@ -66,6 +168,11 @@ type BindInProgressResponseDTO struct {
 	Operation *internal.OperationID `json:"operation,omitempty"`
 }
 
+// UnbindSuccessResponseDTO represents response with operation for service binding removal in progress
+type UnbindSuccessResponseDTO struct {
+	Operation *internal.OperationID `json:"operation,omitempty"`
+}
+
 // BindParametersDTO contains parameters sent by Service Catalog in the body of bind request.
 type BindParametersDTO struct {
 	ServiceID  internal.ServiceID     `json:"service_id"`
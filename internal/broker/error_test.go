@@ -4,3 +4,8 @@ type notFoundError struct{}
 
 func (notFoundError) Error() string  { return "element not found" }
 func (notFoundError) NotFound() bool { return true }
+
+type activeOperationInProgressError struct{}
+
+func (activeOperationInProgressError) Error() string                   { return "active operation in progress" }
+func (activeOperationInProgressError) ActiveOperationInProgress() bool { return true }
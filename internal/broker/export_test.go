@@ -1,8 +1,29 @@
 package broker
 
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
 func NewOSBContext(originatingIdentity, apiVersion string) *OsbContext {
 	return &OsbContext{
 		OriginatingIdentity: originatingIdentity,
 		APIVersion:          apiVersion,
 	}
 }
+
+// newStartedOperationQueue returns operation queue which processes operations until the test binary exits
+func newStartedOperationQueue() *operationQueue {
+	q := newOperationQueue(OperationQueueConfig{GlobalLimit: 10, NamespaceLimit: 10}, logrus.New())
+	go q.Run(context.Background())
+	return q
+}
+
+func SupportedOSBAPIVersions() []string {
+	return supportedOSBAPIVersions
+}
+
+func SupportsInstanceRetrieval(apiVersion string) bool {
+	return NewOSBContext("", apiVersion).supports(osbAPIFeatureInstanceRetrieval)
+}
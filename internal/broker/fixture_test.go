@@ -1,6 +1,7 @@
 package broker_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -136,6 +137,32 @@ func (exp *expAll) NewAddon() *internal.Addon {
 	}
 }
 
+// NewAddonWithPlanSchemas returns addon which plan requires the integer "replicas" parameter in all schemas
+func (exp *expAll) NewAddonWithPlanSchemas() *internal.Addon {
+	var schema internal.PlanSchema
+	if err := json.Unmarshal([]byte(`{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"type": "object",
+		"properties": {
+			"replicas": {"type": "integer", "minimum": 1}
+		},
+		"required": ["replicas"]
+	}`), &schema); err != nil {
+		panic(err)
+	}
+
+	addon := exp.NewAddon()
+	plan := addon.Plans[exp.AddonPlan.ID]
+	plan.Schemas = map[internal.PlanSchemaType]internal.PlanSchema{
+		internal.SchemaTypeProvision: schema,
+		internal.SchemaTypeUpdate:    schema,
+		internal.SchemaTypeBind:      schema,
+	}
+	addon.Plans[exp.AddonPlan.ID] = plan
+
+	return addon
+}
+
 func (exp *expAll) NewInstance() *internal.Instance {
 	return &internal.Instance{
 		ID:                     exp.InstanceID,
@@ -145,6 +172,7 @@ func (exp *expAll) NewInstance() *internal.Instance {
 		Namespace:              exp.Namespace,
 		ProvisioningParameters: exp.ProvisioningParameters,
 		ParamsHash:             exp.ParamsHash,
+		AddonVersion:           internal.AddonVersion(exp.Addon.Version.String()),
 	}
 }
 
@@ -172,6 +200,7 @@ func (exp *expAll) NewInstanceWithInfo() *internal.Instance {
 func (exp *expAll) NewInstanceBindData(cr internal.InstanceCredentials) *internal.InstanceBindData {
 	return &internal.InstanceBindData{
 		InstanceID:  exp.InstanceID,
+		BindingID:   exp.BindingID,
 		Credentials: cr,
 	}
 }
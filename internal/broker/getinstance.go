@@ -0,0 +1,105 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+const maskedParameterValue = "******"
+
+// sensitiveParameterName matches names of the provisioning parameters which values are not returned by the broker
+var sensitiveParameterName = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|private_?key|api_?key|access_?key)`)
+
+type getInstanceService struct {
+	instanceGetter      instanceGetter
+	instanceStateGetter instanceStateGetter
+}
+
+// GetInstance returns the service instance. The instance which is being provisioned or deprovisioned cannot be fetched.
+func (svc *getInstanceService) GetInstance(ctx context.Context, osbCtx OsbContext, req *osb.GetInstanceRequest) (*osb.GetInstanceResponse, *osb.HTTPStatusCodeError) {
+	if !osbCtx.supports(osbAPIFeatureInstanceRetrieval) {
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("fetching service instance is not supported in the API version %s", osbCtx.APIVersion))}
+	}
+
+	iID := internal.InstanceID(req.InstanceID)
+
+	switch _, inProgress, err := svc.instanceStateGetter.IsProvisioningInProgress(iID); {
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if instance is being provisioned: %v", err))}
+	case inProgress:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusNotFound, ErrorMessage: strPtr(fmt.Sprintf("service instance %q is being provisioned", iID))}
+	}
+
+	switch _, inProgress, err := svc.instanceStateGetter.IsDeprovisioningInProgress(iID); {
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if instance is being deprovisioned: %v", err))}
+	case inProgress:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusUnprocessableEntity, ErrorMessage: strPtr("ConcurrencyError"), Description: strPtr("The service instance is being deprovisioned.")}
+	}
+
+	switch provisioned, err := svc.instanceStateGetter.IsProvisioned(iID); {
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if instance is provisioned: %v", err))}
+	case !provisioned:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusNotFound, ErrorMessage: strPtr(fmt.Sprintf("service instance %q is not provisioned", iID))}
+	}
+
+	instance, err := svc.instanceGetter.Get(iID)
+	switch {
+	case IsNotFoundError(err):
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusNotFound, ErrorMessage: strPtr(fmt.Sprintf("while getting instance from storage: %v", err))}
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting instance from storage: %v", err))}
+	}
+
+	var params map[string]interface{}
+	if instance.ProvisioningParameters != nil {
+		params = maskSensitiveParameters(instance.ProvisioningParameters.Data)
+	}
+
+	return &osb.GetInstanceResponse{
+		ServiceID:    string(instance.ServiceID),
+		PlanID:       string(instance.ServicePlanID),
+		DashboardURL: instance.DashboardURL,
+		Parameters:   params,
+	}, nil
+}
+
+// maskSensitiveParameters returns a copy of the parameters with the values of the sensitive parameters masked
+func maskSensitiveParameters(params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+
+	out := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		switch {
+		case sensitiveParameterName.MatchString(name):
+			out[name] = maskedParameterValue
+		default:
+			out[name] = maskSensitiveValue(value)
+		}
+	}
+	return out
+}
+
+func maskSensitiveValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return maskSensitiveParameters(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for idx, item := range v {
+			out[idx] = maskSensitiveValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,8 @@
+package broker
+
+func NewGetInstanceService(ig instanceGetter, isg instanceStateGetter) *getInstanceService {
+	return &getInstanceService{
+		instanceGetter:      ig,
+		instanceStateGetter: isg,
+	}
+}
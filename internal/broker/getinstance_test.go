@@ -0,0 +1,124 @@
+package broker_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/broker/automock"
+)
+
+func TestGetInstanceServiceGetInstanceSuccess(t *testing.T) {
+	// GIVEN
+	var exp expAll
+	exp.Populate()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioningInProgress", exp.InstanceID).Return(internal.OperationID(""), false, nil).Once()
+	isgMock.On("IsDeprovisioningInProgress", exp.InstanceID).Return(internal.OperationID(""), false, nil).Once()
+	isgMock.On("IsProvisioned", exp.InstanceID).Return(true, nil).Once()
+
+	fixInstance := exp.NewInstance()
+	fixInstance.ProvisioningParameters = &internal.RequestParameters{
+		Data: map[string]interface{}{
+			"replicas":      2,
+			"adminPassword": "pass",
+			"database": map[string]interface{}{
+				"name":     "db",
+				"apiToken": "token",
+			},
+			"users": []interface{}{
+				map[string]interface{}{"name": "john", "secret": "john-secret"},
+			},
+		},
+	}
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	isMock.On("Get", exp.InstanceID).Return(fixInstance, nil).Once()
+
+	svc := broker.NewGetInstanceService(isMock, isgMock)
+
+	// WHEN
+	resp, err := svc.GetInstance(context.Background(), *broker.NewOSBContext("", "2.14"), &osb.GetInstanceRequest{InstanceID: string(exp.InstanceID)})
+
+	// THEN
+	require.Nil(t, err)
+	assert.Equal(t, string(exp.Service.ID), resp.ServiceID)
+	assert.Equal(t, string(exp.ServicePlan.ID), resp.PlanID)
+	assert.Equal(t, map[string]interface{}{
+		"replicas":      2,
+		"adminPassword": "******",
+		"database": map[string]interface{}{
+			"name":     "db",
+			"apiToken": "******",
+		},
+		"users": []interface{}{
+			map[string]interface{}{"name": "john", "secret": "******"},
+		},
+	}, resp.Parameters)
+	assert.Equal(t, "pass", fixInstance.ProvisioningParameters.Data["adminPassword"], "stored parameters must not be modified")
+}
+
+func TestGetInstanceServiceGetInstanceFailure(t *testing.T) {
+	var exp expAll
+	exp.Populate()
+
+	for tn, tc := range map[string]struct {
+		apiVersion               string
+		provisioningInProgress   bool
+		deprovisioningInProgress bool
+		provisioned              bool
+		expStatusCode            int
+	}{
+		"API version without instance retrieval": {
+			apiVersion:    "2.13",
+			provisioned:   true,
+			expStatusCode: http.StatusBadRequest,
+		},
+		"provisioning in progress": {
+			provisioningInProgress: true,
+			expStatusCode:          http.StatusNotFound,
+		},
+		"deprovisioning in progress": {
+			deprovisioningInProgress: true,
+			expStatusCode:            http.StatusUnprocessableEntity,
+		},
+		"not provisioned": {
+			provisioned:   false,
+			expStatusCode: http.StatusNotFound,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			isgMock := &automock.InstanceStateGetter{}
+			isgMock.On("IsProvisioningInProgress", exp.InstanceID).Return(exp.OperationID, tc.provisioningInProgress, nil)
+			isgMock.On("IsDeprovisioningInProgress", exp.InstanceID).Return(exp.OperationID, tc.deprovisioningInProgress, nil)
+			isgMock.On("IsProvisioned", exp.InstanceID).Return(tc.provisioned, nil)
+
+			isMock := &automock.InstanceStorage{}
+			defer isMock.AssertExpectations(t)
+
+			svc := broker.NewGetInstanceService(isMock, isgMock)
+
+			apiVersion := tc.apiVersion
+			if apiVersion == "" {
+				apiVersion = "2.14"
+			}
+
+			// WHEN
+			resp, err := svc.GetInstance(context.Background(), *broker.NewOSBContext("", apiVersion), &osb.GetInstanceRequest{InstanceID: string(exp.InstanceID)})
+
+			// THEN
+			assert.Nil(t, resp)
+			require.NotNil(t, err)
+			assert.Equal(t, tc.expStatusCode, err.StatusCode)
+		})
+	}
+}
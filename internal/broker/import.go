@@ -0,0 +1,168 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/release"
+	helmErrors "helm.sh/helm/v3/pkg/storage/driver"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+// instanceImportService adopts the helm release installed without the broker as the provisioned service instance
+type instanceImportService struct {
+	addonIDGetter       addonIDGetter
+	chartGetter         chartGetter
+	instanceGetter      instanceGetter
+	instanceInserter    instanceInserter
+	operationInserter   operationInserter
+	operationIDProvider func() (internal.OperationID, error)
+	helmReleaseGetter   helmReleaseGetter
+	dashboardRenderer   dashboardURLRenderer
+	instanceLocker      *instanceLocker
+
+	log logrus.FieldLogger
+}
+
+// Import creates the instance of the release. The release must be deployed from the chart of the addon plan.
+// The instance is stored with the succeeded provisioning operation, so it can be bound, updated and deprovisioned at once.
+// Repeated import of the same release returns the imported instance, the created flag is false in such case.
+func (svc *instanceImportService) Import(ctx context.Context, osbCtx OsbContext, iID internal.InstanceID, req *ImportInstanceRequestDTO) (*ImportInstanceSuccessResponseDTO, bool, *osb.HTTPStatusCodeError) {
+	svc.instanceLocker.Lock(iID)
+	defer svc.instanceLocker.Unlock(iID)
+
+	svc.log.Infof("Triggered import of helm release %q in namespace %q as instance %s", req.ReleaseName, req.Namespace, iID)
+
+	instances, err := svc.instanceGetter.GetAll()
+	switch {
+	case err == nil, IsNotFoundError(err):
+	default:
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting instance collection: %v", err))}
+	}
+	for _, inst := range instances {
+		sameRelease := inst.ReleaseName == req.ReleaseName && inst.Namespace == req.Namespace
+		switch {
+		case inst.ID == iID && sameRelease && inst.ServiceID == internal.ServiceID(req.AddonID) && inst.ServicePlanID == internal.ServicePlanID(req.PlanID):
+			return newImportInstanceSuccessResponseDTO(inst), false, nil
+		case inst.ID == iID:
+			return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusConflict, ErrorMessage: strPtr(fmt.Sprintf("service instance %q already exists", iID))}
+		case sameRelease:
+			return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusConflict, ErrorMessage: strPtr(fmt.Sprintf("helm release %q in namespace %q already belongs to service instance %q", req.ReleaseName, req.Namespace, inst.ID))}
+		}
+	}
+
+	addon, err := svc.addonIDGetter.GetByID(osbCtx.BrokerNamespace, req.AddonID)
+	switch {
+	case IsNotFoundError(err):
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting addon: %v", err))}
+	case err != nil:
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting addon: %v", err))}
+	}
+	addonPlan, found := addon.Plans[req.PlanID]
+	if !found {
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon does not contain requested plan (planID: %s)", req.PlanID))}
+	}
+
+	rel, err := svc.helmReleaseGetter.GetRelease(req.ReleaseName, req.Namespace)
+	switch {
+	case errors.Is(err, helmErrors.ErrReleaseNotFound):
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusNotFound, ErrorMessage: strPtr(fmt.Sprintf("helm release %q does not exist in namespace %q", req.ReleaseName, req.Namespace))}
+	case err != nil:
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting helm release: %v", err))}
+	}
+	if err := checkImportedRelease(rel, addonPlan.ChartRef); err != nil {
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusUnprocessableEntity, ErrorMessage: strPtr(fmt.Sprintf("helm release %q cannot be imported: %v", req.ReleaseName, err))}
+	}
+
+	opID, err := svc.operationIDProvider()
+	if err != nil {
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while generating operation ID: %v", err))}
+	}
+
+	// the values of the release are kept as parameters, so they are not lost when the instance is updated
+	params := internal.RequestParameters{Data: rel.Config}
+	instance := internal.Instance{
+		ID:            iID,
+		Namespace:     req.Namespace,
+		ServiceID:     internal.ServiceID(addon.ID),
+		ServicePlanID: internal.ServicePlanID(addonPlan.ID),
+		ReleaseName:   req.ReleaseName,
+		ReleaseInfo: internal.ReleaseInfo{
+			ReleaseTime:  rel.Info.LastDeployed.Time,
+			Revision:     rel.Version,
+			ConfigValues: rel.Config,
+		},
+		ProvisioningParameters: &params,
+		AddonVersion:           internal.AddonVersion(addon.Version.String()),
+		HelmOptions:            addonPlan.HelmOptions,
+	}
+
+	if len(addonPlan.DashboardURLTemplate) > 0 && svc.dashboardRenderer != nil {
+		if ch, err := svc.chartGetter.Get(osbCtx.BrokerNamespace, addonPlan.ChartRef.Name, addonPlan.ChartRef.Version); err != nil {
+			svc.log.Errorf("Cannot render dashboard URL of instance %s: while getting chart from storage: %v", iID, err)
+		} else if instance.DashboardURL, err = renderDashboardURL(svc.dashboardRenderer, addonPlan, &instance, ch); err != nil {
+			svc.log.Errorf("Cannot render dashboard URL of instance %s: %v", iID, err)
+		}
+	}
+
+	if err := svc.instanceInserter.Insert(&instance); err != nil {
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while inserting instance to storage: %v", err))}
+	}
+
+	desc := fmt.Sprintf("provisioning succeeded, helm release %q revision %d was imported", rel.Name, rel.Version)
+	op := internal.InstanceOperation{
+		InstanceID:             iID,
+		OperationID:            opID,
+		Type:                   internal.OperationTypeCreate,
+		State:                  internal.OperationStateSucceeded,
+		StateDescription:       &desc,
+		ProvisioningParameters: &params,
+		RequestedBy:            osbCtx.requester(),
+	}
+	if err := svc.operationInserter.Insert(&op); err != nil {
+		return nil, false, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while inserting instance operation to storage: %v", err))}
+	}
+
+	svc.log.Infof("Helm release %q in namespace %q was imported as instance %s", req.ReleaseName, req.Namespace, iID)
+	return newImportInstanceSuccessResponseDTO(&instance), true, nil
+}
+
+// checkImportedRelease checks that the release is deployed from the chart referenced by the plan
+func checkImportedRelease(rel *release.Release, ref internal.ChartRef) error {
+	if rel.Info == nil || rel.Info.Status != release.StatusDeployed {
+		status := release.StatusUnknown
+		if rel.Info != nil {
+			status = rel.Info.Status
+		}
+		return errors.Errorf("release is in status %q, only deployed releases can be imported", status)
+	}
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return errors.New("release has no chart metadata")
+	}
+
+	chartName, chartVersion := rel.Chart.Metadata.Name, rel.Chart.Metadata.Version
+	ver, err := semver.NewVersion(chartVersion)
+	if chartName != string(ref.Name) || err != nil || !ver.Equal(&ref.Version) {
+		return errors.Errorf("release chart %s-%s does not match the plan chart %s-%s", chartName, chartVersion, ref.Name, ref.Version.String())
+	}
+	return nil
+}
+
+func newImportInstanceSuccessResponseDTO(inst *internal.Instance) *ImportInstanceSuccessResponseDTO {
+	return &ImportInstanceSuccessResponseDTO{
+		InstanceID:   inst.ID,
+		ServiceID:    inst.ServiceID,
+		PlanID:       inst.ServicePlanID,
+		ReleaseName:  inst.ReleaseName,
+		Namespace:    inst.Namespace,
+		Revision:     inst.ReleaseInfo.Revision,
+		DashboardURL: inst.DashboardURL,
+	}
+}
@@ -0,0 +1,301 @@
+package broker_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmErrors "helm.sh/helm/v3/pkg/storage/driver"
+	helmTime "helm.sh/helm/v3/pkg/time"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+)
+
+func TestOSBAPIImportInstance(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+	ts.enableAdminAuth()
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+	ts.upsertAddonAndChart()
+
+	rel := ts.fixImportedRelease(release.StatusDeployed, string(ts.Exp.Chart.Name), ts.Exp.Chart.Version.String())
+	ts.HelmClient.On("GetRelease", internal.ReleaseName("redis"), ts.Exp.Namespace).Return(rel, nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	// WHEN
+	resp := ts.importInstance(ts.fixImportRequest())
+	defer resp.Body.Close()
+
+	// THEN
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var dto broker.ImportInstanceSuccessResponseDTO
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&dto))
+	assert.Equal(t, broker.ImportInstanceSuccessResponseDTO{
+		InstanceID:  ts.Exp.InstanceID,
+		ServiceID:   ts.Exp.Service.ID,
+		PlanID:      ts.Exp.ServicePlan.ID,
+		ReleaseName: "redis",
+		Namespace:   ts.Exp.Namespace,
+		Revision:    4,
+	}, dto)
+
+	inst, err := ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, inst.ReleaseInfo.Revision)
+	assert.Equal(t, rel.Info.LastDeployed.Time, inst.ReleaseInfo.ReleaseTime)
+	assert.Equal(t, rel.Config, inst.ReleaseInfo.ConfigValues)
+	assert.Equal(t, rel.Config, inst.ProvisioningParameters.Data)
+	assert.Equal(t, internal.AddonVersion(ts.Exp.Addon.Version.String()), inst.AddonVersion)
+
+	op, err := ts.StorageFactory.InstanceOperation().Get(ts.Exp.InstanceID, ts.Exp.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationTypeCreate, op.Type)
+	assert.Equal(t, internal.OperationStateSucceeded, op.State)
+	assert.Equal(t, `provisioning succeeded, helm release "redis" revision 4 was imported`, *op.StateDescription)
+
+	// WHEN the imported instance is bound
+	bindResp, err := ts.OSBClient().Bind(&osb.BindRequest{
+		AcceptsIncomplete:   true,
+		BindingID:           string(ts.Exp.BindingID),
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		Context:             map[string]interface{}{"namespace": string(ts.Exp.Namespace)},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	})
+
+	// THEN
+	require.NoError(t, err)
+	require.True(t, bindResp.Async)
+	ts.AssertBindOperationState(internal.OperationStateSucceeded)
+
+	// WHEN the import is repeated
+	repeated := ts.importInstance(ts.fixImportRequest())
+	defer repeated.Body.Close()
+
+	// THEN
+	assert.Equal(t, http.StatusOK, repeated.StatusCode)
+}
+
+func TestOSBAPIImportInstanceFailure(t *testing.T) {
+	for name, tc := range map[string]struct {
+		release    *release.Release
+		releaseErr error
+		modifyReq  func(req *broker.ImportInstanceRequestDTO)
+		expStatus  int
+	}{
+		"release not found": {
+			releaseErr: helmErrors.ErrReleaseNotFound,
+			expStatus:  http.StatusNotFound,
+		},
+		"release chart name does not match": {
+			release:   fixImportedRelease(release.StatusDeployed, "postgres", "1.2.3"),
+			expStatus: http.StatusUnprocessableEntity,
+		},
+		"release chart version does not match": {
+			release:   fixImportedRelease(release.StatusDeployed, "fix-C-Name", "1.2.4"),
+			expStatus: http.StatusUnprocessableEntity,
+		},
+		"release not deployed": {
+			release:   fixImportedRelease(release.StatusFailed, "fix-C-Name", "1.2.3"),
+			expStatus: http.StatusUnprocessableEntity,
+		},
+		"plan not found": {
+			modifyReq: func(req *broker.ImportInstanceRequestDTO) { req.PlanID = "not-existing" },
+			expStatus: http.StatusBadRequest,
+		},
+		"release name missing": {
+			modifyReq: func(req *broker.ImportInstanceRequestDTO) { req.ReleaseName = "" },
+			expStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			ts := newOSBAPITestSuite(t, "2.14")
+			ts.enableAdminAuth()
+			ts.ServerRun()
+			defer ts.ServerShutdown()
+			ts.upsertAddonAndChart()
+
+			if tc.release != nil || tc.releaseErr != nil {
+				ts.HelmClient.On("GetRelease", internal.ReleaseName("redis"), ts.Exp.Namespace).Return(tc.release, tc.releaseErr).Once()
+			}
+			defer ts.HelmClient.AssertExpectations(t)
+
+			req := ts.fixImportRequest()
+			if tc.modifyReq != nil {
+				tc.modifyReq(&req)
+			}
+
+			// WHEN
+			resp := ts.importInstance(req)
+			defer resp.Body.Close()
+
+			// THEN
+			assert.Equal(t, tc.expStatus, resp.StatusCode)
+			_, err := ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+			assert.True(t, broker.IsNotFoundError(err))
+		})
+	}
+}
+
+func TestOSBAPIImportInstanceConflict(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+	ts.enableAdminAuth()
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+	ts.upsertAddonAndChart()
+
+	owner := ts.Exp.NewInstance()
+	owner.ID = "owner"
+	owner.ReleaseName = "redis"
+	require.NoError(t, ts.StorageFactory.Instance().Insert(owner))
+
+	// WHEN
+	resp := ts.importInstance(ts.fixImportRequest())
+	defer resp.Body.Close()
+
+	// THEN
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestOSBAPIImportInstanceAdminAuth(t *testing.T) {
+	for name, tc := range map[string]struct {
+		prefix    string
+		username  string
+		modifyReq func(req *broker.ImportInstanceRequestDTO)
+		expStatus int
+	}{
+		"missing token": {
+			prefix:    "/cluster",
+			expStatus: http.StatusUnauthorized,
+		},
+		"user not allowed": {
+			prefix:    "/cluster",
+			username:  "john",
+			expStatus: http.StatusForbidden,
+		},
+		"release from other namespace on namespaced broker": {
+			prefix:    "/ns/other",
+			username:  "admin",
+			expStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			ts := newOSBAPITestSuite(t, "2.14")
+			ts.enableAdminAuth()
+			ts.ServerRun()
+			defer ts.ServerShutdown()
+			ts.upsertAddonAndChart()
+			defer ts.HelmClient.AssertExpectations(t)
+
+			// WHEN
+			resp := ts.importInstanceAs(tc.prefix, tc.username, ts.fixImportRequest())
+			defer resp.Body.Close()
+
+			// THEN
+			assert.Equal(t, tc.expStatus, resp.StatusCode)
+			_, err := ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+			assert.True(t, broker.IsNotFoundError(err))
+		})
+	}
+}
+
+func TestOSBAPIImportInstanceDisabledWithoutAdminAuth(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	// WHEN
+	resp := ts.importInstance(ts.fixImportRequest())
+	defer resp.Body.Close()
+
+	// THEN
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func (ts *osbapiTestSuite) upsertAddonAndChart() {
+	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, ts.Exp.NewAddon())
+	require.NoError(ts.t, err)
+	_, err = ts.StorageFactory.Chart().Upsert(internal.ClusterWide, ts.Exp.NewChart())
+	require.NoError(ts.t, err)
+}
+
+func (ts *osbapiTestSuite) fixImportRequest() broker.ImportInstanceRequestDTO {
+	return broker.ImportInstanceRequestDTO{
+		ReleaseName: "redis",
+		Namespace:   ts.Exp.Namespace,
+		AddonID:     ts.Exp.Addon.ID,
+		PlanID:      ts.Exp.AddonPlan.ID,
+	}
+}
+
+func (ts *osbapiTestSuite) fixImportedRelease(status release.Status, chartName, chartVersion string) *release.Release {
+	rel := fixImportedRelease(status, chartName, chartVersion)
+	rel.Namespace = string(ts.Exp.Namespace)
+	return rel
+}
+
+func (ts *osbapiTestSuite) enableAdminAuth() {
+	ts.BrokerServer.WithAdminAuth(fakeAdminAuthenticator{}, &fakeAuthorizer{allowedUser: "admin"})
+}
+
+func (ts *osbapiTestSuite) importInstance(dto broker.ImportInstanceRequestDTO) *http.Response {
+	return ts.importInstanceAs("/cluster", "admin", dto)
+}
+
+// importInstanceAs sends the import request to the broker under the route prefix, authenticated as the user with the given name
+func (ts *osbapiTestSuite) importInstanceAs(prefix, username string, dto broker.ImportInstanceRequestDTO) *http.Response {
+	body, err := json.Marshal(dto)
+	require.NoError(ts.t, err)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://%s%s/admin/service_instances/%s/import", ts.ServerAddr, prefix, ts.Exp.InstanceID), bytes.NewReader(body))
+	require.NoError(ts.t, err)
+	req.Header.Set(osb.APIVersionHeader, "2.14")
+	if username != "" {
+		req.Header.Set("Authorization", "Bearer "+username)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(ts.t, err)
+	return resp
+}
+
+func fixImportedRelease(status release.Status, chartName, chartVersion string) *release.Release {
+	return &release.Release{
+		Name:    "redis",
+		Version: 4,
+		Config:  map[string]interface{}{"replicas": float64(2)},
+		Info: &release.Info{
+			Status:       status,
+			LastDeployed: helmTime.Now(),
+		},
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{Name: chartName, Version: chartVersion},
+		},
+	}
+}
+
+// fakeAdminAuthenticator authenticates the user whose name is sent as the bearer token
+type fakeAdminAuthenticator struct{}
+
+func (fakeAdminAuthenticator) AuthenticateAdmin(r *http.Request) (*broker.OriginatingUser, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return nil, nil
+	}
+	return &broker.OriginatingUser{Username: token}, nil
+}
@@ -0,0 +1,57 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+// instanceLocker provides locking per service instance.
+// Requests for the same instance are serialized, requests for different instances are processed in parallel.
+type instanceLocker struct {
+	mu    sync.Mutex
+	locks map[internal.InstanceID]*instanceLock
+}
+
+// instanceLock is the lock of a single instance with the number of requests which are holding it or waiting for it.
+type instanceLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newInstanceLocker() *instanceLocker {
+	return &instanceLocker{
+		locks: make(map[internal.InstanceID]*instanceLock),
+	}
+}
+
+// Lock locks the given instance. If the instance is already locked, the call blocks until the instance is unlocked.
+func (l *instanceLocker) Lock(iID internal.InstanceID) {
+	l.mu.Lock()
+	lock, found := l.locks[iID]
+	if !found {
+		lock = &instanceLock{}
+		l.locks[iID] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+}
+
+// Unlock unlocks the given instance. The lock is released from memory when no one is waiting for it.
+func (l *instanceLocker) Unlock(iID internal.InstanceID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, found := l.locks[iID]
+	if !found {
+		panic("unlock of unlocked instance " + string(iID))
+	}
+
+	lock.mu.Unlock()
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, iID)
+	}
+}
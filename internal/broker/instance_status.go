@@ -0,0 +1,47 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+type instanceStatusService struct {
+	instanceGetter instanceGetter
+	healthGetter   helmReleaseHealthGetter
+}
+
+// GetInstanceStatus returns the health of the resources of the service instance release
+func (svc *instanceStatusService) GetInstanceStatus(ctx context.Context, osbCtx OsbContext, iID internal.InstanceID) (*InstanceStatusSuccessResponseDTO, *osb.HTTPStatusCodeError) {
+	instance, err := svc.instanceGetter.Get(iID)
+	switch {
+	case IsNotFoundError(err):
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusNotFound, ErrorMessage: strPtr(fmt.Sprintf("service instance %q does not exist", iID))}
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting instance from storage: %v", err))}
+	}
+
+	health, err := svc.healthGetter.ReleaseHealth(instance.ReleaseName, instance.Namespace)
+	if err != nil {
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking health of release %s: %v", instance.ReleaseName, err))}
+	}
+
+	resp := InstanceStatusSuccessResponseDTO{
+		InstanceID: iID,
+		Healthy:    health.Healthy,
+		Resources:  []ResourceHealthDTO{},
+	}
+	for _, r := range health.Resources {
+		resp.Resources = append(resp.Resources, ResourceHealthDTO{
+			Kind:    r.Kind,
+			Name:    r.Name,
+			Healthy: r.Healthy,
+			Message: r.Message,
+		})
+	}
+	return &resp, nil
+}
@@ -0,0 +1,159 @@
+package broker_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/platform/ptr"
+)
+
+func TestOSBAPIInstanceStatus(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// GIVEN
+		ts := newOSBAPITestSuite(t, "2.14")
+		ts.ServerRun()
+		defer ts.ServerShutdown()
+
+		ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+		ts.HelmClient.On("ReleaseHealth", ts.Exp.ReleaseName, ts.Exp.Namespace).Return(fixUnhealthyRelease(), nil).Once()
+		defer ts.HelmClient.AssertExpectations(t)
+
+		// WHEN
+		resp := ts.getInstanceStatus()
+		defer resp.Body.Close()
+
+		// THEN
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var dto broker.InstanceStatusSuccessResponseDTO
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&dto))
+		assert.Equal(t, broker.InstanceStatusSuccessResponseDTO{
+			InstanceID: ts.Exp.InstanceID,
+			Healthy:    false,
+			Resources: []broker.ResourceHealthDTO{
+				{Kind: "Deployment", Name: "redis", Healthy: false, Message: "0/1 replicas available"},
+				{Kind: "PersistentVolumeClaim", Name: "redis-data", Healthy: true, Message: "phase Bound"},
+			},
+		}, dto)
+	})
+
+	t.Run("not existing instance", func(t *testing.T) {
+		// GIVEN
+		ts := newOSBAPITestSuite(t, "2.14")
+		ts.ServerRun()
+		defer ts.ServerShutdown()
+
+		// WHEN
+		resp := ts.getInstanceStatus()
+		defer resp.Body.Close()
+
+		// THEN
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("health check failure", func(t *testing.T) {
+		// GIVEN
+		ts := newOSBAPITestSuite(t, "2.14")
+		ts.ServerRun()
+		defer ts.ServerShutdown()
+
+		ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+		ts.HelmClient.On("ReleaseHealth", ts.Exp.ReleaseName, ts.Exp.Namespace).Return(internal.ReleaseHealth{}, errors.New("release not found")).Once()
+		defer ts.HelmClient.AssertExpectations(t)
+
+		// WHEN
+		resp := ts.getInstanceStatus()
+		defer resp.Body.Close()
+
+		// THEN
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestOSBAPILastOperationReportsReleaseHealth(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opType    internal.OperationType
+		opState   internal.OperationState
+		healthErr error
+		expDesc   *string
+	}{
+		"provisioning in progress": {
+			opType:  internal.OperationTypeCreate,
+			opState: internal.OperationStateInProgress,
+			expDesc: ptr.String("release health: Deployment redis: 0/1 replicas available"),
+		},
+		"update in progress": {
+			opType:  internal.OperationTypeUpdate,
+			opState: internal.OperationStateInProgress,
+			expDesc: ptr.String("release health: Deployment redis: 0/1 replicas available"),
+		},
+		"release not installed yet": {
+			opType:    internal.OperationTypeCreate,
+			opState:   internal.OperationStateInProgress,
+			healthErr: errors.New("release: not found"),
+		},
+		"provisioning succeeded": {
+			opType:  internal.OperationTypeCreate,
+			opState: internal.OperationStateSucceeded,
+		},
+		"deprovisioning in progress": {
+			opType:  internal.OperationTypeRemove,
+			opState: internal.OperationStateInProgress,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			ts := newOSBAPITestSuite(t, "2.14")
+			ts.ServerRun()
+			defer ts.ServerShutdown()
+
+			ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+			ts.StorageFactory.InstanceOperation().Insert(ts.Exp.NewInstanceOperation(tc.opType, tc.opState))
+			if tc.opState == internal.OperationStateInProgress && tc.opType != internal.OperationTypeRemove {
+				ts.HelmClient.On("ReleaseHealth", ts.Exp.ReleaseName, ts.Exp.Namespace).Return(fixUnhealthyRelease(), tc.healthErr).Once()
+			}
+			defer ts.HelmClient.AssertExpectations(t)
+
+			// WHEN
+			opKey := osb.OperationKey(ts.Exp.OperationID)
+			resp, err := ts.OSBClient().PollLastOperation(&osb.LastOperationRequest{
+				InstanceID:          string(ts.Exp.InstanceID),
+				OperationKey:        &opKey,
+				OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+			})
+
+			// THEN
+			require.NoError(t, err)
+			assert.EqualValues(t, tc.opState, resp.State)
+			assert.Equal(t, tc.expDesc, resp.Description)
+		})
+	}
+}
+
+func (ts *osbapiTestSuite) getInstanceStatus() *http.Response {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/cluster/v2/service_instances/%s/status", ts.ServerAddr, ts.Exp.InstanceID), nil)
+	require.NoError(ts.t, err)
+	req.Header.Set(osb.APIVersionHeader, "2.14")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(ts.t, err)
+	return resp
+}
+
+func fixUnhealthyRelease() internal.ReleaseHealth {
+	return internal.ReleaseHealth{
+		Healthy: false,
+		Resources: []internal.ResourceHealth{
+			{Kind: "Deployment", Name: "redis", Healthy: false, Message: "0/1 replicas available"},
+			{Kind: "PersistentVolumeClaim", Name: "redis-data", Healthy: true, Message: "phase Bound"},
+		},
+	}
+}
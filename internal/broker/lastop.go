@@ -2,8 +2,10 @@ package broker
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
 
@@ -11,7 +13,10 @@ import (
 )
 
 type getLastOperationService struct {
-	getter operationGetter
+	getter         operationGetter
+	instanceGetter instanceGetter
+	healthGetter   helmReleaseHealthGetter
+	log            logrus.FieldLogger
 }
 
 func (svc *getLastOperationService) GetLastOperation(ctx context.Context, osbCtx OsbContext, req *osb.LastOperationRequest) (*osb.LastOperationResponse, error) {
@@ -36,6 +41,10 @@ func (svc *getLastOperationService) GetLastOperation(ctx context.Context, osbCtx
 		descPtr = &desc
 	}
 
+	if svc.reportsHealth(op) {
+		descPtr = svc.appendReleaseHealth(iID, descPtr)
+	}
+
 	resp := osb.LastOperationResponse{
 		State:       osb.LastOperationState(op.State),
 		Description: descPtr,
@@ -43,3 +52,33 @@ func (svc *getLastOperationService) GetLastOperation(ctx context.Context, osbCtx
 
 	return &resp, nil
 }
+
+// reportsHealth returns true for the operation which is installing or upgrading the release
+func (svc *getLastOperationService) reportsHealth(op *internal.InstanceOperation) bool {
+	if svc.healthGetter == nil || op.State != internal.OperationStateInProgress {
+		return false
+	}
+	return op.Type == internal.OperationTypeCreate || op.Type == internal.OperationTypeUpdate
+}
+
+// appendReleaseHealth adds the health of the instance release to the description.
+// The description is returned unchanged when the health cannot be checked, e.g. the release is not installed yet.
+func (svc *getLastOperationService) appendReleaseHealth(iID internal.InstanceID, desc *string) *string {
+	instance, err := svc.instanceGetter.Get(iID)
+	if err != nil {
+		svc.log.Debugf("Cannot get instance %s to check its release health: %v", iID, err)
+		return desc
+	}
+
+	health, err := svc.healthGetter.ReleaseHealth(instance.ReleaseName, instance.Namespace)
+	if err != nil {
+		svc.log.Debugf("Cannot check health of release %s: %v", instance.ReleaseName, err)
+		return desc
+	}
+
+	out := fmt.Sprintf("release health: %s", health.Summary())
+	if desc != nil && *desc != "" {
+		out = fmt.Sprintf("%s; %s", *desc, out)
+	}
+	return &out
+}
@@ -0,0 +1,169 @@
+package broker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/helm-broker/internal"
+	yTime "github.com/kyma-project/helm-broker/internal/platform/time"
+)
+
+// OperationQueueConfig holds configuration of the asynchronous operations processing
+type OperationQueueConfig struct {
+	// GlobalLimit defines how many operations can be processed at the same time
+	GlobalLimit int `default:"10"`
+	// NamespaceLimit defines how many operations can be processed at the same time in a single namespace
+	NamespaceLimit int `default:"3"`
+}
+
+// asyncOperation is the operation which is processed by the operation queue.
+// The operation itself is persisted in the storage, so it can be resumed or failed after the broker restart.
+type asyncOperation struct {
+	operationID internal.OperationID
+	namespace   internal.Namespace
+	do          func(ctx context.Context)
+}
+
+// operationQueue processes asynchronous operations by the pool of workers.
+// The number of operations processed at the same time is limited globally and per namespace.
+type operationQueue struct {
+	globalLimit    int
+	namespaceLimit int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []asyncOperation
+	running map[internal.Namespace]int
+	// started holds the time when the running operations were taken from the queue
+	started map[internal.OperationID]time.Time
+	stopped bool
+
+	nowProvider yTime.NowProvider
+
+	log logrus.FieldLogger
+}
+
+func newOperationQueue(cfg OperationQueueConfig, log logrus.FieldLogger) *operationQueue {
+	q := &operationQueue{
+		globalLimit:    cfg.GlobalLimit,
+		namespaceLimit: cfg.NamespaceLimit,
+		running:        make(map[internal.Namespace]int),
+		started:        make(map[internal.OperationID]time.Time),
+		log:            log,
+	}
+	if q.globalLimit < 1 {
+		q.globalLimit = 1
+	}
+	if q.namespaceLimit < 1 || q.namespaceLimit > q.globalLimit {
+		q.namespaceLimit = q.globalLimit
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	return q
+}
+
+// Enqueue adds the operation to the queue. Operations enqueued after the queue was stopped are not processed,
+// they stay in progress in the storage and are handled on the next broker start.
+func (q *operationQueue) Enqueue(op asyncOperation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopped {
+		q.log.Warnf("Operation queue is stopped, operation %q in namespace %q will be handled on the next start", op.operationID, op.namespace)
+		return
+	}
+
+	q.pending = append(q.pending, op)
+	q.cond.Signal()
+}
+
+// Run starts workers and blocks until given context is cancelled.
+// On cancellation the queue stops taking pending operations and waits until all running operations are finished.
+func (q *operationQueue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.globalLimit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work()
+		}()
+	}
+
+	<-ctx.Done()
+
+	q.mu.Lock()
+	q.stopped = true
+	if len(q.pending) > 0 {
+		q.log.Infof("Operation queue is stopped, %d pending operations will be handled on the next start", len(q.pending))
+	}
+	q.pending = nil
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	wg.Wait()
+}
+
+func (q *operationQueue) work() {
+	for {
+		op, ok := q.next()
+		if !ok {
+			return
+		}
+
+		// running operation is not interrupted on the queue stop, it is finished before the broker exits
+		op.do(context.Background())
+
+		q.mu.Lock()
+		delete(q.started, op.operationID)
+		q.running[op.namespace]--
+		if q.running[op.namespace] == 0 {
+			delete(q.running, op.namespace)
+		}
+		// other workers may wait for the namespace released by this operation
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// next blocks until there is an operation which can be processed within the namespace limit.
+// It returns false when the queue is stopped.
+func (q *operationQueue) next() (asyncOperation, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		if q.stopped {
+			return asyncOperation{}, false
+		}
+
+		for idx, op := range q.pending {
+			// operations without namespace are limited only globally
+			if op.namespace != "" && q.running[op.namespace] >= q.namespaceLimit {
+				continue
+			}
+			q.pending = append(q.pending[:idx], q.pending[idx+1:]...)
+			q.running[op.namespace]++
+			q.started[op.operationID] = q.nowProvider.Now()
+			return op, true
+		}
+
+		q.cond.Wait()
+	}
+}
+
+// operationStatus returns true when the operation still waits in the queue, and the time when the running operation
+// was taken from the queue. The time is zero when the operation is not running in this broker, e.g. it was enqueued before the restart.
+func (q *operationQueue) operationStatus(opID internal.OperationID) (bool, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, op := range q.pending {
+		if op.operationID == opID {
+			return true, time.Time{}
+		}
+	}
+	return false, q.started[opID]
+}
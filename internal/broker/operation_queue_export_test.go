@@ -0,0 +1,29 @@
+package broker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+type OperationQueue = operationQueue
+
+func NewOperationQueue(cfg OperationQueueConfig, log logrus.FieldLogger) *operationQueue {
+	return newOperationQueue(cfg, log)
+}
+
+func (q *operationQueue) WithNowProvider(nowProvider func() time.Time) *operationQueue {
+	q.nowProvider = nowProvider
+	return q
+}
+
+func (q *operationQueue) EnqueueOperation(opID internal.OperationID, ns internal.Namespace, do func(ctx context.Context)) {
+	q.Enqueue(asyncOperation{operationID: opID, namespace: ns, do: do})
+}
+
+func (q *operationQueue) EnqueueFunc(ns internal.Namespace, do func(ctx context.Context)) {
+	q.Enqueue(asyncOperation{namespace: ns, do: do})
+}
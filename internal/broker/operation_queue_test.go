@@ -0,0 +1,121 @@
+package broker_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
+)
+
+func TestOperationQueueLimits(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		cfg         broker.OperationQueueConfig
+		namespaces  []internal.Namespace
+		expParallel int
+	}{
+		"global limit": {
+			cfg:         broker.OperationQueueConfig{GlobalLimit: 2, NamespaceLimit: 2},
+			namespaces:  []internal.Namespace{"ns-a", "ns-b", "ns-c", "ns-d"},
+			expParallel: 2,
+		},
+		"namespace limit": {
+			cfg:         broker.OperationQueueConfig{GlobalLimit: 4, NamespaceLimit: 1},
+			namespaces:  []internal.Namespace{"ns-a", "ns-a", "ns-a", "ns-a"},
+			expParallel: 1,
+		},
+		"namespace limit does not block other namespaces": {
+			cfg:         broker.OperationQueueConfig{GlobalLimit: 4, NamespaceLimit: 1},
+			namespaces:  []internal.Namespace{"ns-a", "ns-a", "ns-b", "ns-b"},
+			expParallel: 2,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			q := broker.NewOperationQueue(tc.cfg, spy.NewLogDummy())
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go q.Run(ctx)
+
+			var (
+				mu                  sync.Mutex
+				running, maxRunning int
+				wg                  sync.WaitGroup
+			)
+
+			// WHEN
+			for _, ns := range tc.namespaces {
+				wg.Add(1)
+				q.EnqueueFunc(ns, func(context.Context) {
+					defer wg.Done()
+					mu.Lock()
+					running++
+					if running > maxRunning {
+						maxRunning = running
+					}
+					mu.Unlock()
+
+					time.Sleep(20 * time.Millisecond)
+
+					mu.Lock()
+					running--
+					mu.Unlock()
+				})
+			}
+
+			// THEN
+			wg.Wait()
+			assert.Equal(t, tc.expParallel, maxRunning)
+		})
+	}
+}
+
+func TestOperationQueueDrainsOnContextCancel(t *testing.T) {
+	// GIVEN
+	q := broker.NewOperationQueue(broker.OperationQueueConfig{GlobalLimit: 1, NamespaceLimit: 1}, spy.NewLogDummy())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stopped := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(stopped)
+	}()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	finished := false
+	q.EnqueueFunc("ns", func(context.Context) {
+		close(started)
+		<-release
+		finished = true
+	})
+	pendingCalled := false
+	q.EnqueueFunc("ns", func(context.Context) {
+		pendingCalled = true
+	})
+	<-started
+
+	// WHEN
+	cancel()
+
+	// THEN
+	select {
+	case <-stopped:
+		t.Fatal("queue stopped before running operation was finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+
+	assert.True(t, finished)
+	assert.False(t, pendingCalled)
+
+	q.EnqueueFunc("ns", func(context.Context) {
+		t.Error("operation enqueued after stop must not be processed")
+	})
+}
@@ -0,0 +1,334 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/release"
+	helmErrors "helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/kyma-project/helm-broker/internal"
+	yTime "github.com/kyma-project/helm-broker/internal/platform/time"
+)
+
+// OperationReaperConfig holds configuration of the reaper which settles operations stuck in progress.
+// The timeouts are counted from the time when the operation was taken from the operation queue.
+type OperationReaperConfig struct {
+	// Interval defines how often operations in progress are checked
+	Interval time.Duration `default:"1m"`
+	// ProvisionTimeout defines after which time the provisioning operation is treated as stale,
+	// the longer helm timeout of the instance plan takes precedence
+	ProvisionTimeout time.Duration `default:"2h"`
+	// UpdateTimeout defines after which time the update operation is treated as stale,
+	// the longer helm timeout of the instance plan takes precedence
+	UpdateTimeout time.Duration `default:"2h"`
+	// DeprovisionTimeout defines after which time the deprovisioning operation is treated as stale,
+	// the longer helm timeout of the instance plan takes precedence
+	DeprovisionTimeout time.Duration `default:"1h"`
+	// BindTimeout defines after which time the binding and unbinding operations are treated as stale
+	BindTimeout time.Duration `default:"30m"`
+}
+
+// operationReaper periodically settles operations which are in progress longer than the timeout defined for their type.
+// Without it an operation interrupted e.g. by the broker pod crash blocks the instance forever,
+// because the instance state is computed from the operations in progress.
+// The instance operations are settled based on the state of the helm release, bind operations are marked as failed.
+// Operations which still wait in the operation queue are not settled.
+type operationReaper struct {
+	cfg OperationReaperConfig
+
+	instanceGetter       instanceGetter
+	instanceInserter     instanceInserter
+	operationStorage     operationStorage
+	bindOperationStorage bindOperationStorage
+	helmReleaseGetter    helmReleaseGetter
+	deprovisioner        *deprovisionService
+	instanceLocker       *instanceLocker
+	operationQueue       *operationQueue
+	nowProvider          yTime.NowProvider
+
+	log logrus.FieldLogger
+}
+
+// Run settles stale operations every configured interval until given context is cancelled.
+// The reaper is disabled when the interval is not set.
+func (r *operationReaper) Run(ctx context.Context) {
+	if r.cfg.Interval <= 0 {
+		r.log.Info("Interval is not set, stale operations are not settled")
+		return
+	}
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reap(ctx); err != nil {
+				r.log.Errorf("Cannot settle stale operations: %v", err)
+			}
+		}
+	}
+}
+
+// Reap settles all operations which are in progress longer than the timeout defined for their type.
+// Operations of the instances which were already removed are settled too.
+func (r *operationReaper) Reap(ctx context.Context) error {
+	instanceIDs, err := r.instanceIDsWithOperations()
+	if err != nil {
+		return err
+	}
+
+	for _, iID := range instanceIDs {
+		if err := r.reapInstanceOperations(ctx, iID); err != nil {
+			return errors.Wrapf(err, "while settling operations of instance %q", iID)
+		}
+		if err := r.reapBindOperations(iID); err != nil {
+			return errors.Wrapf(err, "while settling bind operations of instance %q", iID)
+		}
+	}
+
+	return nil
+}
+
+// instanceIDsWithOperations returns IDs of all instances which have instance or bind operations
+func (r *operationReaper) instanceIDsWithOperations() ([]internal.InstanceID, error) {
+	opInstanceIDs, err := r.operationStorage.GetInstanceIDs()
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting instances with operations")
+	}
+	bindOpInstanceIDs, err := r.bindOperationStorage.GetInstanceIDs()
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting instances with bind operations")
+	}
+
+	var out []internal.InstanceID
+	seen := map[internal.InstanceID]struct{}{}
+	for _, iID := range append(opInstanceIDs, bindOpInstanceIDs...) {
+		if _, found := seen[iID]; found {
+			continue
+		}
+		seen[iID] = struct{}{}
+		out = append(out, iID)
+	}
+	return out, nil
+}
+
+// reapInstanceOperations settles stale operations of the instance. The helm release is fetched without holding the instance lock,
+// so the operation state is checked again under the lock before it is settled.
+func (r *operationReaper) reapInstanceOperations(ctx context.Context, iID internal.InstanceID) error {
+	ops, err := r.operationStorage.GetAll(iID)
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+		return nil
+	default:
+		return errors.Wrap(err, "while getting instance operations")
+	}
+
+	for _, op := range ops {
+		// the helm timeout of the plan can only extend the timeout, so the instance is fetched only for the operations stale by the configured one
+		timeout := r.instanceOperationTimeout(op.Type)
+		if !r.isStale(op.State, op.OperationID, op.CreatedAt, timeout) {
+			continue
+		}
+
+		// instance is fetched again, because it can be removed by the deprovisioning operation settled in the same run
+		inst, err := r.instanceGetter.Get(iID)
+		switch {
+		case err == nil:
+		case IsNotFoundError(err):
+			r.log.Infof("Settling %s operation %q of removed instance %q which is in progress longer than %v", op.Type, op.OperationID, iID, timeout)
+			if err := r.settleRemovedInstanceOperation(iID, *op, timeout); err != nil {
+				return errors.Wrapf(err, "while settling operation %q", op.OperationID)
+			}
+			continue
+		default:
+			return errors.Wrap(err, "while getting instance")
+		}
+
+		// the operation is not stale before the helm operation of the instance plan could time out
+		if inst.HelmOptions.Timeout > timeout {
+			timeout = inst.HelmOptions.Timeout
+			if !r.isStale(op.State, op.OperationID, op.CreatedAt, timeout) {
+				continue
+			}
+		}
+
+		rel, err := r.helmReleaseGetter.GetRelease(inst.ReleaseName, inst.Namespace)
+		releaseExists := true
+		switch {
+		case err == nil:
+		case errors.Is(err, helmErrors.ErrReleaseNotFound):
+			releaseExists = false
+		default:
+			return errors.Wrapf(err, "while getting helm release %q", inst.ReleaseName)
+		}
+
+		r.log.Infof("Settling %s operation %q of instance %q which is in progress longer than %v", op.Type, op.OperationID, iID, timeout)
+		finishDeprovisioning, err := r.settleInstanceOperation(*inst, op.OperationID, timeout, rel, releaseExists)
+		if err != nil {
+			return errors.Wrapf(err, "while settling operation %q", op.OperationID)
+		}
+		if finishDeprovisioning {
+			// the release is already removed, the deprovisioning finishes removing the instance data.
+			// The operation stays in progress until then, so no other operation of the instance can be started.
+			r.deprovisioner.do(ctx, *inst, op.OperationID)
+		}
+	}
+
+	return nil
+}
+
+// settleRemovedInstanceOperation settles the operation of the instance which was already removed from the storage.
+// The deprovisioning removes the instance as the last step, so it succeeded, all other operations are marked as failed.
+func (r *operationReaper) settleRemovedInstanceOperation(iID internal.InstanceID, op internal.InstanceOperation, timeout time.Duration) error {
+	r.instanceLocker.Lock(iID)
+	defer r.instanceLocker.Unlock(iID)
+
+	current, stale, err := r.staleInstanceOperation(iID, op.OperationID, timeout)
+	if err != nil || !stale {
+		return err
+	}
+
+	if current.Type == internal.OperationTypeRemove {
+		desc := "deprovisioning succeeded, instance was removed before the operation timed out"
+		return r.operationStorage.UpdateStateDesc(iID, current.OperationID, internal.OperationStateSucceeded, &desc)
+	}
+	desc := fmt.Sprintf("%s timed out after %v, instance was not found", r.instanceOperationName(current.Type), timeout)
+	return r.operationStorage.UpdateStateDesc(iID, current.OperationID, internal.OperationStateFailed, &desc)
+}
+
+// settleInstanceOperation settles the operation based on the helm release fetched before the instance lock was taken.
+// It returns true when the deprovisioning operation must be finished, which is done without holding the lock.
+func (r *operationReaper) settleInstanceOperation(inst internal.Instance, opID internal.OperationID, timeout time.Duration, rel *release.Release, releaseExists bool) (bool, error) {
+	r.instanceLocker.Lock(inst.ID)
+	defer r.instanceLocker.Unlock(inst.ID)
+
+	op, stale, err := r.staleInstanceOperation(inst.ID, opID, timeout)
+	if err != nil || !stale {
+		return false, err
+	}
+
+	switch {
+	case op.Type == internal.OperationTypeCreate && releaseExists && rel.Info.Status == release.StatusDeployed:
+		inst.ReleaseInfo = internal.ReleaseInfo{
+			ReleaseTime:  rel.Info.LastDeployed.Time,
+			Revision:     rel.Version,
+			ConfigValues: rel.Config,
+		}
+		if _, err := r.instanceInserter.Upsert(&inst); err != nil {
+			return false, errors.Wrap(err, "while updating instance in storage")
+		}
+		desc := fmt.Sprintf("provisioning succeeded, helm release %q was found deployed after the operation timed out", inst.ReleaseName)
+		return false, r.operationStorage.UpdateStateDesc(inst.ID, op.OperationID, internal.OperationStateSucceeded, &desc)
+	case op.Type == internal.OperationTypeRemove && !releaseExists:
+		return true, nil
+	}
+
+	releaseState := "was not found"
+	if releaseExists {
+		releaseState = fmt.Sprintf("is in status %q", rel.Info.Status)
+	}
+	desc := fmt.Sprintf("%s timed out after %v, helm release %q %s", r.instanceOperationName(op.Type), timeout, inst.ReleaseName, releaseState)
+	return false, r.operationStorage.UpdateStateDesc(inst.ID, op.OperationID, internal.OperationStateFailed, &desc)
+}
+
+// staleInstanceOperation fetches the operation again and checks if it is still stale, it must be called under the instance lock
+func (r *operationReaper) staleInstanceOperation(iID internal.InstanceID, opID internal.OperationID, timeout time.Duration) (*internal.InstanceOperation, bool, error) {
+	op, err := r.operationStorage.Get(iID, opID)
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+		return nil, false, nil
+	default:
+		return nil, false, errors.Wrap(err, "while getting instance operation")
+	}
+	return op, r.isStale(op.State, op.OperationID, op.CreatedAt, timeout), nil
+}
+
+func (r *operationReaper) reapBindOperations(iID internal.InstanceID) error {
+	r.instanceLocker.Lock(iID)
+	defer r.instanceLocker.Unlock(iID)
+
+	ops, err := r.bindOperationStorage.GetAll(iID)
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+		return nil
+	default:
+		return errors.Wrap(err, "while getting bind operations")
+	}
+
+	for _, op := range ops {
+		if !r.isStale(op.State, op.OperationID, op.CreatedAt, r.cfg.BindTimeout) {
+			continue
+		}
+
+		r.log.Infof("Failing %s operation %q of binding %q which is in progress longer than %v", op.Type, op.OperationID, op.BindingID, r.cfg.BindTimeout)
+		desc := fmt.Sprintf("%s timed out after %v", r.bindOperationName(op.Type), r.cfg.BindTimeout)
+		if err := r.bindOperationStorage.UpdateStateDesc(iID, op.BindingID, op.OperationID, internal.OperationStateFailed, &desc); err != nil {
+			return errors.Wrapf(err, "while marking bind operation %q as failed", op.OperationID)
+		}
+	}
+
+	return nil
+}
+
+func (r *operationReaper) isStale(state internal.OperationState, opID internal.OperationID, createdAt time.Time, timeout time.Duration) bool {
+	if state != internal.OperationStateInProgress {
+		return false
+	}
+	startedAt, started := r.operationStart(opID, createdAt)
+	return started && r.nowProvider.Now().Sub(startedAt) >= timeout
+}
+
+// operationStart returns the time from which the operation timeout is counted, false is returned when the operation
+// still waits in the operation queue. The operations which are not in the queue, e.g. interrupted by the broker restart,
+// are timed from their creation.
+func (r *operationReaper) operationStart(opID internal.OperationID, createdAt time.Time) (time.Time, bool) {
+	if r.operationQueue == nil {
+		return createdAt, true
+	}
+	switch pending, startedAt := r.operationQueue.operationStatus(opID); {
+	case pending:
+		return time.Time{}, false
+	case startedAt.IsZero():
+		return createdAt, true
+	default:
+		return startedAt, true
+	}
+}
+
+func (r *operationReaper) instanceOperationTimeout(opType internal.OperationType) time.Duration {
+	switch opType {
+	case internal.OperationTypeCreate:
+		return r.cfg.ProvisionTimeout
+	case internal.OperationTypeUpdate:
+		return r.cfg.UpdateTimeout
+	default:
+		return r.cfg.DeprovisionTimeout
+	}
+}
+
+func (r *operationReaper) instanceOperationName(opType internal.OperationType) string {
+	switch opType {
+	case internal.OperationTypeCreate:
+		return "provisioning"
+	case internal.OperationTypeUpdate:
+		return "update"
+	default:
+		return "deprovisioning"
+	}
+}
+
+func (r *operationReaper) bindOperationName(opType internal.OperationType) string {
+	if opType == internal.OperationTypeRemove {
+		return "unbinding"
+	}
+	return "binding"
+}
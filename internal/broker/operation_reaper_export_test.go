@@ -0,0 +1,35 @@
+package broker
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func NewOperationReaper(cfg OperationReaperConfig, is instanceStorage, os operationStorage, bos bindOperationStorage, ibdr instanceBindDataRemover,
+	hc helmClient, nowProvider func() time.Time, log logrus.FieldLogger) *operationReaper {
+	return &operationReaper{
+		cfg:                  cfg,
+		instanceGetter:       is,
+		instanceInserter:     is,
+		operationStorage:     os,
+		bindOperationStorage: bos,
+		helmReleaseGetter:    hc,
+		deprovisioner: &deprovisionService{
+			instanceGetter:          is,
+			instanceRemover:         is,
+			operationUpdater:        os,
+			instanceBindDataRemover: ibdr,
+			helmDeleter:             hc,
+			log:                     log,
+		},
+		instanceLocker: newInstanceLocker(),
+		nowProvider:    nowProvider,
+		log:            log,
+	}
+}
+
+func (r *operationReaper) WithOperationQueue(q *operationQueue) *operationReaper {
+	r.operationQueue = q
+	return r
+}
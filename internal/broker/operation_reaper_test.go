@@ -0,0 +1,436 @@
+package broker_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	helmErrors "helm.sh/helm/v3/pkg/storage/driver"
+	helmTime "helm.sh/helm/v3/pkg/time"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/broker/automock"
+	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
+	"github.com/kyma-project/helm-broker/internal/storage"
+)
+
+func TestOperationReaperReapInstanceOperations(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		opType       internal.OperationType
+		release      *release.Release
+		releaseErr   error
+		expState     internal.OperationState
+		expDesc      string
+		expInstance  bool
+		expRelRevNum int
+	}{
+		"provisioning with deployed release": {
+			opType:       internal.OperationTypeCreate,
+			release:      fixReaperRelease(release.StatusDeployed),
+			expState:     internal.OperationStateSucceeded,
+			expDesc:      `provisioning succeeded, helm release "fix-release" was found deployed after the operation timed out`,
+			expInstance:  true,
+			expRelRevNum: 3,
+		},
+		"provisioning with failed release": {
+			opType:      internal.OperationTypeCreate,
+			release:     fixReaperRelease(release.StatusFailed),
+			expState:    internal.OperationStateFailed,
+			expDesc:     `provisioning timed out after 1h0m0s, helm release "fix-release" is in status "failed"`,
+			expInstance: true,
+		},
+		"provisioning without release": {
+			opType:      internal.OperationTypeCreate,
+			releaseErr:  helmErrors.ErrReleaseNotFound,
+			expState:    internal.OperationStateFailed,
+			expDesc:     `provisioning timed out after 1h0m0s, helm release "fix-release" was not found`,
+			expInstance: true,
+		},
+		"update with deployed release": {
+			opType:      internal.OperationTypeUpdate,
+			release:     fixReaperRelease(release.StatusDeployed),
+			expState:    internal.OperationStateFailed,
+			expDesc:     `update timed out after 2h0m0s, helm release "fix-release" is in status "deployed"`,
+			expInstance: true,
+		},
+		"deprovisioning with existing release": {
+			opType:      internal.OperationTypeRemove,
+			release:     fixReaperRelease(release.StatusUninstalling),
+			expState:    internal.OperationStateFailed,
+			expDesc:     `deprovisioning timed out after 3h0m0s, helm release "fix-release" is in status "uninstalling"`,
+			expInstance: true,
+		},
+		"deprovisioning without release": {
+			opType:      internal.OperationTypeRemove,
+			releaseErr:  fmt.Errorf("while getting release: %w", helmErrors.ErrReleaseNotFound),
+			expState:    internal.OperationStateSucceeded,
+			expDesc:     "deprovisioning succeeded",
+			expInstance: false,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			ts := newOperationReaperTestSuite(t)
+			ts.InsertInstanceOperation(tc.opType, internal.OperationStateInProgress)
+
+			ts.HelmClient.On("GetRelease", ts.Instance.ReleaseName, ts.Instance.Namespace).Return(tc.release, tc.releaseErr).Once()
+			if tc.opType == internal.OperationTypeRemove && tc.releaseErr != nil {
+				ts.HelmClient.On("Delete", ts.Instance.ReleaseName, ts.Instance.Namespace, ts.Instance.HelmOptions).Return(helmErrors.ErrReleaseNotFound).Once()
+			}
+			defer ts.HelmClient.AssertExpectations(t)
+
+			// WHEN
+			err := ts.Reaper(time.Hour * 4).Reap(context.Background())
+
+			// THEN
+			require.NoError(t, err)
+
+			op, err := ts.StorageFactory.InstanceOperation().Get(ts.Instance.ID, ts.OperationID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expState, op.State)
+			assert.Equal(t, tc.expDesc, *op.StateDescription)
+
+			inst, err := ts.StorageFactory.Instance().Get(ts.Instance.ID)
+			if !tc.expInstance {
+				assert.True(t, broker.IsNotFoundError(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expRelRevNum, inst.ReleaseInfo.Revision)
+		})
+	}
+}
+
+func TestOperationReaperReapOperationsOfRemovedInstance(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		opType   internal.OperationType
+		expState internal.OperationState
+		expDesc  string
+	}{
+		"deprovisioning": {
+			opType:   internal.OperationTypeRemove,
+			expState: internal.OperationStateSucceeded,
+			expDesc:  "deprovisioning succeeded, instance was removed before the operation timed out",
+		},
+		"provisioning": {
+			opType:   internal.OperationTypeCreate,
+			expState: internal.OperationStateFailed,
+			expDesc:  "provisioning timed out after 1h0m0s, instance was not found",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			ts := newOperationReaperTestSuite(t)
+			ts.InsertInstanceOperation(tc.opType, internal.OperationStateInProgress)
+			ts.InsertBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+			require.NoError(t, ts.StorageFactory.Instance().Remove(ts.Instance.ID))
+			defer ts.HelmClient.AssertExpectations(t)
+
+			// WHEN
+			err := ts.Reaper(time.Hour * 4).Reap(context.Background())
+
+			// THEN
+			require.NoError(t, err)
+
+			op, err := ts.StorageFactory.InstanceOperation().Get(ts.Instance.ID, ts.OperationID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expState, op.State)
+			assert.Equal(t, tc.expDesc, *op.StateDescription)
+
+			bOp, err := ts.StorageFactory.BindOperation().Get(ts.Instance.ID, ts.BindingID, ts.OperationID)
+			require.NoError(t, err)
+			assert.Equal(t, internal.OperationStateFailed, bOp.State)
+		})
+	}
+}
+
+func TestOperationReaperReapSkipsOperationSettledWhileGettingRelease(t *testing.T) {
+	// GIVEN
+	ts := newOperationReaperTestSuite(t)
+	ts.InsertInstanceOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+
+	desc := "provisioning succeeded"
+	ts.HelmClient.On("GetRelease", ts.Instance.ReleaseName, ts.Instance.Namespace).Return(nil, helmErrors.ErrReleaseNotFound).Once().
+		Run(func(mock.Arguments) {
+			// the provisioning finishes while the reaper waits for the release
+			require.NoError(t, ts.StorageFactory.InstanceOperation().UpdateStateDesc(ts.Instance.ID, ts.OperationID, internal.OperationStateSucceeded, &desc))
+		})
+	defer ts.HelmClient.AssertExpectations(t)
+
+	// WHEN
+	err := ts.Reaper(time.Hour * 4).Reap(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+
+	op, err := ts.StorageFactory.InstanceOperation().Get(ts.Instance.ID, ts.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationStateSucceeded, op.State)
+	assert.Equal(t, desc, *op.StateDescription)
+}
+
+func TestOperationReaperReapSkipsOperationsWithinTimeout(t *testing.T) {
+	// GIVEN
+	ts := newOperationReaperTestSuite(t)
+	ts.InsertInstanceOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	ts.InsertBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	defer ts.HelmClient.AssertExpectations(t)
+
+	// WHEN
+	err := ts.Reaper(time.Minute * 30).Reap(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+
+	op, err := ts.StorageFactory.InstanceOperation().Get(ts.Instance.ID, ts.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationStateInProgress, op.State)
+
+	bOp, err := ts.StorageFactory.BindOperation().Get(ts.Instance.ID, ts.BindingID, ts.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationStateInProgress, bOp.State)
+}
+
+func TestOperationReaperReapBindOperations(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		opType  internal.OperationType
+		expDesc string
+	}{
+		"binding": {
+			opType:  internal.OperationTypeCreate,
+			expDesc: "binding timed out after 40m0s",
+		},
+		"unbinding": {
+			opType:  internal.OperationTypeRemove,
+			expDesc: "unbinding timed out after 40m0s",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			ts := newOperationReaperTestSuite(t)
+			ts.InsertBindOperation(tc.opType, internal.OperationStateInProgress)
+			defer ts.HelmClient.AssertExpectations(t)
+
+			// WHEN
+			err := ts.Reaper(time.Hour).Reap(context.Background())
+
+			// THEN
+			require.NoError(t, err)
+
+			op, err := ts.StorageFactory.BindOperation().Get(ts.Instance.ID, ts.BindingID, ts.OperationID)
+			require.NoError(t, err)
+			assert.Equal(t, internal.OperationStateFailed, op.State)
+			assert.Equal(t, tc.expDesc, *op.StateDescription)
+		})
+	}
+}
+
+func TestOperationReaperReapSkipsOperationsPendingInQueue(t *testing.T) {
+	// GIVEN
+	ts := newOperationReaperTestSuite(t)
+	ts.InsertInstanceOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	ts.InsertBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	defer ts.HelmClient.AssertExpectations(t)
+
+	// the queue is not run, so the operation waits in it
+	queue := broker.NewOperationQueue(broker.OperationQueueConfig{GlobalLimit: 1}, spy.NewLogDummy())
+	queue.EnqueueOperation(ts.OperationID, ts.Instance.Namespace, func(context.Context) {})
+
+	// WHEN
+	err := ts.ReaperWithQueue(time.Hour*4, queue).Reap(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+
+	op, err := ts.StorageFactory.InstanceOperation().Get(ts.Instance.ID, ts.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationStateInProgress, op.State)
+
+	bOp, err := ts.StorageFactory.BindOperation().Get(ts.Instance.ID, ts.BindingID, ts.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationStateInProgress, bOp.State)
+}
+
+func TestOperationReaperReapCountsTimeoutFromOperationStart(t *testing.T) {
+	// GIVEN
+	ts := newOperationReaperTestSuite(t)
+	ts.InsertInstanceOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	ts.InsertBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	defer ts.HelmClient.AssertExpectations(t)
+
+	// the operation is taken from the queue 30 minutes before the reaper checks it
+	queue := broker.NewOperationQueue(broker.OperationQueueConfig{GlobalLimit: 1}, spy.NewLogDummy()).
+		WithNowProvider(func() time.Time { return time.Now().Add(time.Hour*3 + time.Minute*30) })
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	queue.EnqueueOperation(ts.OperationID, ts.Instance.Namespace, func(context.Context) {
+		close(started)
+		<-finish
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queueStopped := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(queueStopped)
+	}()
+	defer func() {
+		close(finish)
+		cancel()
+		<-queueStopped
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("timeout on operation started")
+	}
+
+	// WHEN
+	err := ts.ReaperWithQueue(time.Hour*4, queue).Reap(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+
+	op, err := ts.StorageFactory.InstanceOperation().Get(ts.Instance.ID, ts.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationStateInProgress, op.State)
+
+	bOp, err := ts.StorageFactory.BindOperation().Get(ts.Instance.ID, ts.BindingID, ts.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationStateInProgress, bOp.State)
+}
+
+func TestOperationReaperReapRaisesTimeoutToPlanHelmTimeout(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		elapsed  time.Duration
+		expState internal.OperationState
+	}{
+		"within helm timeout": {
+			elapsed:  time.Hour * 4,
+			expState: internal.OperationStateInProgress,
+		},
+		"after helm timeout": {
+			elapsed:  time.Hour * 6,
+			expState: internal.OperationStateFailed,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			ts := newOperationReaperTestSuite(t)
+			ts.Instance.HelmOptions.Timeout = time.Hour * 5
+			_, err := ts.StorageFactory.Instance().Upsert(ts.Instance)
+			require.NoError(t, err)
+			ts.InsertInstanceOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+
+			if tc.expState == internal.OperationStateFailed {
+				ts.HelmClient.On("GetRelease", ts.Instance.ReleaseName, ts.Instance.Namespace).Return(nil, helmErrors.ErrReleaseNotFound).Once()
+			}
+			defer ts.HelmClient.AssertExpectations(t)
+
+			// WHEN
+			err = ts.Reaper(tc.elapsed).Reap(context.Background())
+
+			// THEN
+			require.NoError(t, err)
+
+			op, err := ts.StorageFactory.InstanceOperation().Get(ts.Instance.ID, ts.OperationID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expState, op.State)
+			if tc.expState == internal.OperationStateFailed {
+				assert.Equal(t, `provisioning timed out after 5h0m0s, helm release "fix-release" was not found`, *op.StateDescription)
+			}
+		})
+	}
+}
+
+type staleOperationReaper interface {
+	Reap(ctx context.Context) error
+}
+
+type operationReaperTestSuite struct {
+	t              *testing.T
+	StorageFactory storage.Factory
+	HelmClient     *automock.HelmClient
+	Instance       *internal.Instance
+	OperationID    internal.OperationID
+	BindingID      internal.BindingID
+}
+
+func newOperationReaperTestSuite(t *testing.T) *operationReaperTestSuite {
+	sFact, err := storage.NewFactory(storage.NewConfigListAllMemory())
+	require.NoError(t, err)
+
+	ts := &operationReaperTestSuite{
+		t:              t,
+		StorageFactory: sFact,
+		HelmClient:     &automock.HelmClient{},
+		Instance: &internal.Instance{
+			ID:          "fix-instance-id",
+			ReleaseName: "fix-release",
+			Namespace:   "fix-namespace",
+		},
+		OperationID: "fix-op-id",
+		BindingID:   "fix-binding-id",
+	}
+	require.NoError(t, sFact.Instance().Insert(ts.Instance))
+
+	return ts
+}
+
+func (ts *operationReaperTestSuite) InsertInstanceOperation(tpe internal.OperationType, state internal.OperationState) {
+	require.NoError(ts.t, ts.StorageFactory.InstanceOperation().Insert(&internal.InstanceOperation{
+		InstanceID:  ts.Instance.ID,
+		OperationID: ts.OperationID,
+		Type:        tpe,
+		State:       state,
+	}))
+}
+
+func (ts *operationReaperTestSuite) InsertBindOperation(tpe internal.OperationType, state internal.OperationState) {
+	require.NoError(ts.t, ts.StorageFactory.BindOperation().Insert(&internal.BindOperation{
+		InstanceID:  ts.Instance.ID,
+		BindingID:   ts.BindingID,
+		OperationID: ts.OperationID,
+		Type:        tpe,
+		State:       state,
+	}))
+}
+
+// Reaper returns the reaper which sees the operations inserted by the suite as started given time ago
+func (ts *operationReaperTestSuite) Reaper(elapsed time.Duration) staleOperationReaper {
+	return ts.ReaperWithQueue(elapsed, nil)
+}
+
+// ReaperWithQueue returns the reaper which sees the operations inserted by the suite as created given time ago
+// and checks if they were started by the given operation queue
+func (ts *operationReaperTestSuite) ReaperWithQueue(elapsed time.Duration, queue *broker.OperationQueue) staleOperationReaper {
+	cfg := broker.OperationReaperConfig{
+		Interval:           time.Minute,
+		ProvisionTimeout:   time.Hour,
+		UpdateTimeout:      2 * time.Hour,
+		DeprovisionTimeout: 3 * time.Hour,
+		BindTimeout:        40 * time.Minute,
+	}
+	now := func() time.Time { return time.Now().Add(elapsed) }
+
+	return broker.NewOperationReaper(cfg, ts.StorageFactory.Instance(), ts.StorageFactory.InstanceOperation(), ts.StorageFactory.BindOperation(),
+		ts.StorageFactory.InstanceBindData(), ts.HelmClient, now, spy.NewLogDummy()).WithOperationQueue(queue)
+}
+
+func fixReaperRelease(status release.Status) *release.Release {
+	return &release.Release{
+		Name:    "fix-release",
+		Version: 3,
+		Info: &release.Info{
+			Status:       status,
+			LastDeployed: helmTime.Now(),
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package broker
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+const interruptedOperationDesc = "operation was interrupted by the broker restart"
+
+// operationRecoverer handles operations which were left in progress when the broker was stopped.
+// Deprovisioning and unbinding operations are idempotent and are resumed, all other operations are marked as failed,
+// because their input cannot be fully restored from the storage. The Platform can retry the failed operations.
+type operationRecoverer struct {
+	instanceGetter       instanceGetter
+	operationStorage     operationStorage
+	bindOperationStorage bindOperationStorage
+	deprovisioner        *deprovisionService
+	unbinder             *unbindService
+
+	log logrus.FieldLogger
+}
+
+// Recover resumes or fails all operations which are in progress. It must be called before the broker starts processing requests.
+func (r *operationRecoverer) Recover(ctx context.Context) error {
+	instances, err := r.instanceGetter.GetAll()
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+		return nil
+	default:
+		return errors.Wrap(err, "while getting instances")
+	}
+
+	for _, inst := range instances {
+		if err := r.recoverInstanceOperations(ctx, *inst); err != nil {
+			return errors.Wrapf(err, "while recovering operations of instance %q", inst.ID)
+		}
+		if err := r.recoverBindOperations(ctx, inst.ID); err != nil {
+			return errors.Wrapf(err, "while recovering bind operations of instance %q", inst.ID)
+		}
+	}
+
+	return nil
+}
+
+func (r *operationRecoverer) recoverInstanceOperations(ctx context.Context, inst internal.Instance) error {
+	ops, err := r.operationStorage.GetAll(inst.ID)
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+		return nil
+	default:
+		return errors.Wrap(err, "while getting instance operations")
+	}
+
+	for _, op := range ops {
+		if op.State != internal.OperationStateInProgress {
+			continue
+		}
+
+		if op.Type == internal.OperationTypeRemove {
+			r.log.Infof("Resuming deprovisioning operation %q of instance %q", op.OperationID, inst.ID)
+			r.deprovisioner.doAsync(ctx, inst, op.OperationID)
+			continue
+		}
+
+		r.log.Infof("Failing %s operation %q of instance %q interrupted by the broker restart", op.Type, op.OperationID, inst.ID)
+		desc := interruptedOperationDesc
+		if err := r.operationStorage.UpdateStateDesc(inst.ID, op.OperationID, internal.OperationStateFailed, &desc); err != nil {
+			return errors.Wrapf(err, "while marking operation %q as failed", op.OperationID)
+		}
+	}
+
+	return nil
+}
+
+func (r *operationRecoverer) recoverBindOperations(ctx context.Context, iID internal.InstanceID) error {
+	ops, err := r.bindOperationStorage.GetAll(iID)
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+		return nil
+	default:
+		return errors.Wrap(err, "while getting bind operations")
+	}
+
+	for _, op := range ops {
+		if op.State != internal.OperationStateInProgress {
+			continue
+		}
+
+		if op.Type == internal.OperationTypeRemove {
+			r.log.Infof("Resuming unbinding operation %q of binding %q", op.OperationID, op.BindingID)
+			r.unbinder.doAsync(ctx, iID, op.BindingID, op.OperationID)
+			continue
+		}
+
+		r.log.Infof("Failing %s operation %q of binding %q interrupted by the broker restart", op.Type, op.OperationID, op.BindingID)
+		desc := interruptedOperationDesc
+		if err := r.bindOperationStorage.UpdateStateDesc(iID, op.BindingID, op.OperationID, internal.OperationStateFailed, &desc); err != nil {
+			return errors.Wrapf(err, "while marking bind operation %q as failed", op.OperationID)
+		}
+	}
+
+	return nil
+}
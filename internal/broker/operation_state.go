@@ -164,6 +164,30 @@ func (svc *bindStateService) IsBindingInProgress(iID internal.InstanceID, bID in
 	return resultOpID, result, nil
 }
 
+func (svc *bindStateService) IsUnbindingInProgress(iID internal.InstanceID, bID internal.BindingID) (internal.OperationID, bool, error) {
+	result := false
+	var resultOpID internal.OperationID
+
+	ops, err := svc.bindOperationCollectionGetter.GetAll(iID)
+	switch {
+	case err == nil:
+	case IsNotFoundError(err):
+		return resultOpID, false, nil
+	default:
+		return resultOpID, false, errors.Wrap(err, "while getting bind operations from storage")
+	}
+
+	for _, op := range ops {
+		if op.Type == internal.OperationTypeRemove && op.State == internal.OperationStateInProgress && op.BindingID == bID {
+			result = true
+			resultOpID = op.OperationID
+			break
+		}
+	}
+
+	return resultOpID, result, nil
+}
+
 // IsNotFoundError check if error is NotFound one.
 func IsNotFoundError(err error) bool {
 	nfe, ok := err.(interface {
@@ -599,3 +599,80 @@ func TestBindStateServiceIsBindingInProgress(t *testing.T) {
 		assert.Zero(t, gotOpID)
 	})
 }
+
+func TestBindStateServiceIsUnbindingInProgress(t *testing.T) {
+	for sym, tc := range map[string]struct {
+		genOps        func(ts *bindStateServiceTestSuite) []*internal.BindOperation
+		expInProgress bool
+	}{
+		"true/CreateSucceededThanRemoveInProgress": {
+			genOps: func(ts *bindStateServiceTestSuite) (out []*internal.BindOperation) {
+				createOp := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+				createOp.OperationID = "fix-create-op-id"
+				out = append(out, createOp)
+				out = append(out, ts.Exp.NewBindOperation(internal.OperationTypeRemove, internal.OperationStateInProgress))
+				return out
+			},
+			expInProgress: true,
+		},
+		"false/singleCreateInProgress": {
+			genOps: func(ts *bindStateServiceTestSuite) (out []*internal.BindOperation) {
+				return append(out, ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress))
+			},
+			expInProgress: false,
+		},
+		"false/singleRemoveFailed": {
+			genOps: func(ts *bindStateServiceTestSuite) (out []*internal.BindOperation) {
+				return append(out, ts.Exp.NewBindOperation(internal.OperationTypeRemove, internal.OperationStateFailed))
+			},
+			expInProgress: false,
+		},
+		"false/NoOp": {
+			genOps:        func(ts *bindStateServiceTestSuite) (out []*internal.BindOperation) { return out },
+			expInProgress: false,
+		},
+	} {
+		t.Run(fmt.Sprintf("Success/%s", sym), func(t *testing.T) {
+			// GIVEN
+			ts := newBindStateServiceTestSuite(t)
+			ts.SetUp()
+
+			bocgMock := &automock.BindOperationStorage{}
+			defer bocgMock.AssertExpectations(t)
+			bocgMock.On("GetAll", ts.Exp.InstanceID).Return(tc.genOps(ts), nil).Once()
+
+			svc := broker.NewBindStateService(bocgMock)
+
+			// WHEN
+			gotOpID, gotInProgress, err := svc.IsUnbindingInProgress(ts.Exp.InstanceID, ts.Exp.BindingID)
+
+			// THEN
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expInProgress, gotInProgress)
+			if tc.expInProgress {
+				assert.Equal(t, ts.Exp.OperationID, gotOpID)
+			}
+		})
+	}
+
+	t.Run("Failure/GenericStorageError", func(t *testing.T) {
+		// GIVEN
+		ts := newBindStateServiceTestSuite(t)
+		ts.SetUp()
+
+		bocgMock := &automock.BindOperationStorage{}
+		defer bocgMock.AssertExpectations(t)
+		fixErr := errors.New("fix-storage-error")
+		bocgMock.On("GetAll", ts.Exp.InstanceID).Return(nil, fixErr).Once()
+
+		svc := broker.NewBindStateService(bocgMock)
+
+		// WHEN
+		gotOpID, got, err := svc.IsUnbindingInProgress(ts.Exp.InstanceID, ts.Exp.BindingID)
+
+		// THEN
+		assert.EqualError(t, err, fmt.Sprintf("while getting bind operations from storage: %s", fixErr.Error()))
+		assert.False(t, got)
+		assert.Zero(t, gotOpID)
+	})
+}
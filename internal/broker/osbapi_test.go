@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -27,7 +31,24 @@ import (
 	"helm.sh/helm/v3/pkg/release"
 )
 
-func newOSBAPITestSuite(t *testing.T) *osbapiTestSuite {
+// runForEachOSBAPIVersion runs the test once per supported version of the Open Service Broker API
+func runForEachOSBAPIVersion(t *testing.T, test func(t *testing.T, apiVersion string)) {
+	for _, apiVersion := range broker.SupportedOSBAPIVersions() {
+		t.Run(apiVersion, func(t *testing.T) {
+			test(t, apiVersion)
+		})
+	}
+}
+
+func newOSBAPITestSuite(t *testing.T, apiVersion string) *osbapiTestSuite {
+	return newOSBAPITestSuiteWithAuthorizer(t, apiVersion, nil)
+}
+
+func newOSBAPITestSuiteWithAuthorizer(t *testing.T, apiVersion string, authz broker.Authorizer) *osbapiTestSuite {
+	return newOSBAPITestSuiteWithConfig(t, apiVersion, authz, broker.Config{OperationQueue: broker.OperationQueueConfig{GlobalLimit: 10, NamespaceLimit: 10}})
+}
+
+func newOSBAPITestSuiteWithConfig(t *testing.T, apiVersion string, authz broker.Authorizer, cfg broker.Config) *osbapiTestSuite {
 	logSink := spy.NewLogSink()
 	logSink.RawLogger.Out = ioutil.Discard
 
@@ -35,10 +56,12 @@ func newOSBAPITestSuite(t *testing.T) *osbapiTestSuite {
 	require.NoError(t, err)
 
 	ts := &osbapiTestSuite{
-		t:              t,
-		StorageFactory: sFact,
-		HelmClient:     &automock.HelmClient{},
-		LogSink:        logSink,
+		t:                 t,
+		APIVersion:        apiVersion,
+		StorageFactory:    sFact,
+		HelmClient:        &automock.HelmClient{},
+		DashboardRenderer: &fakeDashboardURLRenderer{},
+		LogSink:           logSink,
 	}
 
 	ts.Exp.Populate()
@@ -56,7 +79,11 @@ func newOSBAPITestSuite(t *testing.T) *osbapiTestSuite {
 		sFact.InstanceBindData(),
 		&fakeBindTmplRenderer{},
 		&fakeBindTmplResolver{},
+		ts.DashboardRenderer,
 		ts.HelmClient,
+		nil,
+		authz,
+		cfg,
 		logSink.Logger, ts.OperationIDProvider)
 
 	return ts
@@ -65,9 +92,13 @@ func newOSBAPITestSuite(t *testing.T) *osbapiTestSuite {
 type osbapiTestSuite struct {
 	t *testing.T
 
+	// APIVersion is sent in the X-Broker-API-Version header of all requests
+	APIVersion string
+
 	BrokerServer        *broker.Server
 	StorageFactory      storage.Factory
 	HelmClient          *automock.HelmClient
+	DashboardRenderer   *fakeDashboardURLRenderer
 	LogSink             *spy.LogSink
 	OperationIDProvider func() (internal.OperationID, error)
 
@@ -75,6 +106,7 @@ type osbapiTestSuite struct {
 
 	serverWg     sync.WaitGroup
 	serverCancel func()
+	serverProxy  *httptest.Server
 	ServerAddr   string
 
 	Exp expAll
@@ -93,11 +125,27 @@ func (ts *osbapiTestSuite) ServerRun() {
 
 	// TODO: wrap in timeout
 	<-startedCh
-	ts.ServerAddr = ts.BrokerServer.Addr()
+	ts.serverProxy = ts.newAPIVersionProxy(ts.BrokerServer.Addr())
+	ts.ServerAddr = ts.serverProxy.Listener.Addr().String()
 	ts.serverCancel = cancel
 }
 
+// newAPIVersionProxy returns the proxy which sets the suite API version on requests sent to the broker,
+// because the OSB client does not support all API versions accepted by the broker.
+func (ts *osbapiTestSuite) newAPIVersionProxy(brokerAddr string) *httptest.Server {
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: brokerAddr})
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Header.Set(osb.APIVersionHeader, ts.APIVersion)
+	}
+	return httptest.NewServer(proxy)
+}
+
 func (ts *osbapiTestSuite) ServerShutdown() {
+	if ts.serverProxy != nil {
+		ts.serverProxy.Close()
+	}
 	ts.serverCancel()
 	ts.serverWg.Wait()
 }
@@ -198,9 +246,32 @@ Polling:
 	return false
 }
 
+func (ts *osbapiTestSuite) AssertBindOperationGone() bool {
+	doCheck := func() bool {
+		_, err := ts.StorageFactory.BindOperation().Get(ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID)
+		return err != nil
+	}
+
+	timeoutTotal := time.After(time.Second)
+	for !doCheck() {
+		select {
+		case <-timeoutTotal:
+			ts.t.Error("timeout on bind operation removal")
+			return false
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	return true
+}
+
 func TestOSBAPICatalogSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPICatalogSuccess)
+}
+
+func testOSBAPICatalogSuccess(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -215,11 +286,28 @@ func TestOSBAPICatalogSuccess(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestOSBAPICatalogOnUnsupportedAPIVersion(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.12")
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	// WHEN
+	_, err := ts.OSBClient().GetCatalog()
+
+	// THEN
+	assertHTTPStatusCode(t, http.StatusPreconditionFailed, err)
+}
+
 func TestOSBAPIProvisionSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIProvisionSuccess)
+}
+
+func testOSBAPIProvisionSuccess(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
-	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace).Return(&release.Release{
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{
 		Info: &release.Info{},
 	}, nil).Once()
 	defer ts.HelmClient.AssertExpectations(t)
@@ -245,7 +333,7 @@ func TestOSBAPIProvisionSuccess(t *testing.T) {
 		},
 		OrganizationGUID:    nsUID,
 		SpaceGUID:           nsUID,
-		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: `{"username": "admin", "uid": "123"}`},
 	}
 
 	// WHEN
@@ -258,11 +346,19 @@ func TestOSBAPIProvisionSuccess(t *testing.T) {
 	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
 
 	ts.AssertOperationState(internal.OperationStateSucceeded)
+
+	op, err := ts.StorageFactory.InstanceOperation().Get(ts.Exp.InstanceID, ts.Exp.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationRequester{Username: "admin", UID: "123"}, op.RequestedBy)
 }
 
 func TestOSBAPIProvisionRepeatedOnAlreadyFullyProvisionedInstance(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIProvisionRepeatedOnAlreadyFullyProvisionedInstance)
+}
+
+func testOSBAPIProvisionRepeatedOnAlreadyFullyProvisionedInstance(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -302,8 +398,14 @@ func TestOSBAPIProvisionRepeatedOnAlreadyFullyProvisionedInstance(t *testing.T)
 }
 
 func TestOSBAPIProvisionRepeatedOnProvisioningInProgress(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIProvisionRepeatedOnProvisioningInProgress)
+}
+
+func testOSBAPIProvisionRepeatedOnProvisioningInProgress(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -313,9 +415,6 @@ func TestOSBAPIProvisionRepeatedOnProvisioningInProgress(t *testing.T) {
 	fixOperation.OperationID = expOpID
 	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
 
-	ts.ServerRun()
-	defer ts.ServerShutdown()
-
 	nsUID := uuid.NewRandom().String()
 	req := &osb.ProvisionRequest{
 		AcceptsIncomplete: true,
@@ -344,8 +443,12 @@ func TestOSBAPIProvisionRepeatedOnProvisioningInProgress(t *testing.T) {
 }
 
 func TestOSBAPIProvisionConflictErrorOnAlreadyFullyProvisionedInstance(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIProvisionConflictErrorOnAlreadyFullyProvisionedInstance)
+}
+
+func testOSBAPIProvisionConflictErrorOnAlreadyFullyProvisionedInstance(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -406,8 +509,12 @@ func TestOSBAPIProvisionConflictErrorOnAlreadyFullyProvisionedInstance(t *testin
 }
 
 func TestOSBAPIDeprovisionOnAlreadyDeprovisionedInstance(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIDeprovisionOnAlreadyDeprovisionedInstance)
+}
+
+func testOSBAPIDeprovisionOnAlreadyDeprovisionedInstance(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -440,8 +547,12 @@ func TestOSBAPIDeprovisionOnAlreadyDeprovisionedInstance(t *testing.T) {
 }
 
 func TestOSBAPIDeprovisionOnAlreadyDeprovisionedAndRemovedInstance(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIDeprovisionOnAlreadyDeprovisionedAndRemovedInstance)
+}
+
+func testOSBAPIDeprovisionOnAlreadyDeprovisionedAndRemovedInstance(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	// storage does not contain any data
 
 	ts.ServerRun()
@@ -469,8 +580,14 @@ func TestOSBAPIDeprovisionOnAlreadyDeprovisionedAndRemovedInstance(t *testing.T)
 }
 
 func TestOSBAPIDeprovisionRepeatedOnDeprovisioningInProgress(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIDeprovisionRepeatedOnDeprovisioningInProgress)
+}
+
+func testOSBAPIDeprovisionRepeatedOnDeprovisioningInProgress(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -480,9 +597,6 @@ func TestOSBAPIDeprovisionRepeatedOnDeprovisioningInProgress(t *testing.T) {
 	fixOperation.OperationID = expOpID
 	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
 
-	ts.ServerRun()
-	defer ts.ServerShutdown()
-
 	req := &osb.DeprovisionRequest{
 		AcceptsIncomplete:   true,
 		InstanceID:          string(ts.Exp.InstanceID),
@@ -505,15 +619,19 @@ func TestOSBAPIDeprovisionRepeatedOnDeprovisioningInProgress(t *testing.T) {
 }
 
 func TestOSBAPIDeprovisionSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIDeprovisionSuccess)
+}
+
+func testOSBAPIDeprovisionSuccess(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
 	expOpID := internal.OperationID("fix-op-id")
 	fixOperation.OperationID = expOpID
 	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
 
-	ts.HelmClient.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace).Return(nil).Once()
+	ts.HelmClient.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(nil).Once()
 	defer ts.HelmClient.AssertExpectations(t)
 
 	ts.ServerRun()
@@ -542,9 +660,177 @@ func TestOSBAPIDeprovisionSuccess(t *testing.T) {
 	ts.AssertOperationState(internal.OperationStateSucceeded)
 }
 
+func TestOSBAPIHelmOptions(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+
+	noWait := false
+	fixOptions := internal.HelmOptions{Timeout: 2 * time.Hour, Wait: &noWait, DisableHooks: true}
+	fixAddon := ts.Exp.NewAddon()
+	plan := fixAddon.Plans[ts.Exp.AddonPlan.ID]
+	plan.HelmOptions = fixOptions
+	fixAddon.Plans[ts.Exp.AddonPlan.ID] = plan
+	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, fixAddon)
+	require.NoError(t, err)
+	ts.StorageFactory.Chart().Upsert(internal.ClusterWide, ts.Exp.NewChart())
+
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, fixOptions).Return(&release.Release{Info: &release.Info{}}, nil).Once()
+	ts.HelmClient.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace, fixOptions).Return(nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	// WHEN
+	_, err = ts.OSBClient().ProvisionInstance(&osb.ProvisionRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		Context:             map[string]interface{}{"namespace": string(ts.Exp.Namespace)},
+		OrganizationGUID:    "org",
+		SpaceGUID:           "space",
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	})
+
+	// THEN
+	require.NoError(t, err)
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+
+	gotInstance, err := ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, fixOptions, gotInstance.HelmOptions)
+
+	// WHEN
+	ts.Exp.OperationID = "deprovision-op-id"
+	_, err = ts.OSBClient().DeprovisionInstance(&osb.DeprovisionRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	})
+
+	// THEN
+	require.NoError(t, err)
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+}
+
+func TestOSBAPIUpdateSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIUpdateSuccess)
+}
+
+func testOSBAPIUpdateSuccess(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	fixOperation.OperationID = internal.OperationID("fix-op-id")
+	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
+
+	ts.HelmClient.On("Upgrade", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}}, nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	fixAddon := ts.Exp.NewAddon()
+	ts.StorageFactory.Addon().Upsert(internal.ClusterWide, fixAddon)
+
+	fixChart := ts.Exp.NewChart()
+	ts.StorageFactory.Chart().Upsert(internal.ClusterWide, fixChart)
+
+	fixInstance := ts.Exp.NewInstance()
+	ts.StorageFactory.Instance().Insert(fixInstance)
+
+	req := &osb.UpdateInstanceRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		Parameters:          map[string]interface{}{"foo": "bar"},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
+
+	// WHEN
+	resp, err := ts.OSBClient().UpdateInstance(req)
+
+	// THEN
+	require.NoError(t, err)
+
+	require.True(t, resp.Async)
+	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
+
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+
+	gotInstance, err := ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", gotInstance.ProvisioningParameters.Data["foo"])
+}
+
+func TestOSBAPIProvisionRepeatedAfterUpdate(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+
+	fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	fixOperation.OperationID = internal.OperationID("fix-op-id")
+	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
+
+	ts.HelmClient.On("Upgrade", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}}, nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	ts.StorageFactory.Addon().Upsert(internal.ClusterWide, ts.Exp.NewAddon())
+	ts.StorageFactory.Chart().Upsert(internal.ClusterWide, ts.Exp.NewChart())
+	// the instance is stored with the parameters hash of the old broker version
+	ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+
+	_, err := ts.OSBClient().UpdateInstance(&osb.UpdateInstanceRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		Parameters:          map[string]interface{}{"foo": "bar"},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	})
+	require.NoError(t, err)
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+
+	gotInstance, err := ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, broker.ParamsHash(gotInstance.ProvisioningParameters.Data), gotInstance.ParamsHash)
+
+	// WHEN
+	nsUID := uuid.NewRandom().String()
+	_, err = ts.OSBClient().ProvisionInstance(&osb.ProvisionRequest{
+		AcceptsIncomplete: true,
+		InstanceID:        string(ts.Exp.InstanceID),
+		ServiceID:         string(ts.Exp.Service.ID),
+		PlanID:            string(ts.Exp.ServicePlan.ID),
+		Context: map[string]interface{}{
+			"namespace": string(ts.Exp.Namespace),
+		},
+		OrganizationGUID:    nsUID,
+		SpaceGUID:           nsUID,
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+		Parameters:          ts.Exp.ProvisioningParameters.Data,
+	})
+
+	// THEN
+	assertHTTPStatusCode(t, http.StatusConflict, err)
+
+	gotInstance, err = ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", gotInstance.ProvisioningParameters.Data["foo"])
+}
+
 func TestOSBAPILastOperationSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPILastOperationSuccess)
+}
+
+func testOSBAPILastOperationSuccess(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -569,8 +855,12 @@ func TestOSBAPILastOperationSuccess(t *testing.T) {
 }
 
 func TestOSBAPILastOperationForNonExistingInstance(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPILastOperationForNonExistingInstance)
+}
+
+func testOSBAPILastOperationForNonExistingInstance(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -589,40 +879,255 @@ func TestOSBAPILastOperationForNonExistingInstance(t *testing.T) {
 	assert.True(t, osb.IsGoneError(err))
 }
 
-func TestOSBAPIBindFailureWithDisallowedParametersFieldInReq(t *testing.T) {
+func TestOSBAPIGetInstanceSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIGetInstanceSuccess)
+}
+
+func testOSBAPIGetInstanceSuccess(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	fixInstance := ts.Exp.NewInstance()
+	fixInstance.ProvisioningParameters = &internal.RequestParameters{
+		Data: map[string]interface{}{
+			"replicas": float64(2),
+			"password": "secret",
+		},
+	}
+	ts.StorageFactory.Instance().Insert(fixInstance)
+	ts.StorageFactory.InstanceOperation().Insert(ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded))
+
+	// WHEN
+	resp, err := ts.OSBClient().GetInstance(&osb.GetInstanceRequest{
+		InstanceID: string(ts.Exp.InstanceID),
+	})
+
+	// THEN
+	if !broker.SupportsInstanceRetrieval(apiVersion) {
+		assertHTTPStatusCode(t, http.StatusBadRequest, err)
+		return
+	}
+	require.NoError(t, err)
+	assert.Equal(t, string(ts.Exp.Service.ID), resp.ServiceID)
+	assert.Equal(t, string(ts.Exp.ServicePlan.ID), resp.PlanID)
+	assert.Equal(t, map[string]interface{}{"replicas": float64(2), "password": "******"}, resp.Parameters)
+}
+
+func TestOSBAPIDashboardURL(t *testing.T) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, "2.14")
+
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{
+		Info:   &release.Info{},
+		Config: map[string]interface{}{"host": "console.example.com"},
+	}, nil).Once()
+	ts.HelmClient.On("Upgrade", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{
+		Info:   &release.Info{},
+		Config: map[string]interface{}{"host": "updated.example.com"},
+	}, nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
 	fixAddon := ts.Exp.NewAddon()
+	plan := fixAddon.Plans[ts.Exp.AddonPlan.ID]
+	plan.DashboardURLTemplate = internal.AddonPlanDashboardURLTemplate("https://{{ .Values.host }}/{{ .Release.Name }}")
+	plan.ChartValues = internal.ChartValues{"host": "plan.example.com"}
+	fixAddon.Plans[ts.Exp.AddonPlan.ID] = plan
 	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, fixAddon)
 	require.NoError(t, err)
+	ts.StorageFactory.Chart().Upsert(internal.ClusterWide, ts.Exp.NewChart())
 
 	// WHEN
-	req := &osb.BindRequest{
+	provisionResp, err := ts.OSBClient().ProvisionInstance(&osb.ProvisionRequest{
 		AcceptsIncomplete: true,
-		BindingID:         "bind-id",
-		InstanceID:        "instance-id",
-		ServiceID:         "svc-id",
-		PlanID:            "bind-id",
-		Parameters: map[string]interface{}{
-			"params": "set-but-not-allowed",
-		},
+		InstanceID:        string(ts.Exp.InstanceID),
+		ServiceID:         string(ts.Exp.Service.ID),
+		PlanID:            string(ts.Exp.ServicePlan.ID),
+		Context:           map[string]interface{}{"namespace": string(ts.Exp.Namespace)},
+		OrganizationGUID:  "org",
+		SpaceGUID:         "space",
+	})
+
+	// THEN
+	require.NoError(t, err)
+	require.NotNil(t, provisionResp.DashboardURL, "the URL rendered with the plan values must be returned before the release is installed")
+	assert.Equal(t, fmt.Sprintf("https://plan.example.com/%s", ts.Exp.ReleaseName), *provisionResp.DashboardURL)
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+
+	// WHEN
+	getResp, err := ts.OSBClient().GetInstance(&osb.GetInstanceRequest{InstanceID: string(ts.Exp.InstanceID)})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("https://console.example.com/%s", ts.Exp.ReleaseName), getResp.DashboardURL)
+
+	// WHEN
+	ts.Exp.OperationID = "update-op-id"
+	updateResp, err := ts.OSBClient().UpdateInstance(&osb.UpdateInstanceRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		Parameters:          map[string]interface{}{"host": "updated.example.com"},
 		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	})
+
+	// THEN
+	require.NoError(t, err)
+	require.NotNil(t, updateResp.DashboardURL)
+	assert.Equal(t, fmt.Sprintf("https://updated.example.com/%s", ts.Exp.ReleaseName), *updateResp.DashboardURL)
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+}
+
+func TestOSBAPIDashboardURLPreviewRenderedWithoutInstanceLock(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+
+	// the operations wait until the preview is checked, so the preview is rendered first
+	previewChecked := make(chan struct{})
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}}, nil).Once().
+		Run(func(mock.Arguments) { <-previewChecked })
+	ts.HelmClient.On("Upgrade", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}}, nil).Once().
+		Run(func(mock.Arguments) { <-previewChecked })
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	fixAddon := ts.Exp.NewAddon()
+	plan := fixAddon.Plans[ts.Exp.AddonPlan.ID]
+	plan.DashboardURLTemplate = internal.AddonPlanDashboardURLTemplate("https://{{ .Values.host }}/{{ .Release.Name }}")
+	fixAddon.Plans[ts.Exp.AddonPlan.ID] = plan
+	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, fixAddon)
+	require.NoError(t, err)
+	ts.StorageFactory.Chart().Upsert(internal.ClusterWide, ts.Exp.NewChart())
+
+	provisionReq := &osb.ProvisionRequest{
+		AcceptsIncomplete: true,
+		InstanceID:        string(ts.Exp.InstanceID),
+		ServiceID:         string(ts.Exp.Service.ID),
+		PlanID:            string(ts.Exp.ServicePlan.ID),
+		Context:           map[string]interface{}{"namespace": string(ts.Exp.Namespace)},
+		OrganizationGUID:  "org",
+		SpaceGUID:         "space",
 	}
-	_, err = ts.OSBClient().Bind(req)
+	updateReq := &osb.UpdateInstanceRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
+
+	// the repeated request is served only when the instance is not locked by the request which renders the preview
+	sendRepeatedOnPreview := func(send func() error) <-chan error {
+		repeated := make(chan error, 1)
+		var once sync.Once
+		ts.DashboardRenderer.onRender = func() {
+			once.Do(func() {
+				defer close(previewChecked)
+				served := make(chan error, 1)
+				go func() { served <- send() }()
+				select {
+				case err := <-served:
+					repeated <- err
+				case <-time.After(time.Second):
+					repeated <- fmt.Errorf("repeated request is blocked by the instance lock")
+				}
+			})
+		}
+		return repeated
+	}
+
+	// WHEN
+	repeated := sendRepeatedOnPreview(func() error {
+		_, err := ts.OSBClient().ProvisionInstance(provisionReq)
+		return err
+	})
+	_, err = ts.OSBClient().ProvisionInstance(provisionReq)
 
 	// THEN
-	require.Error(t, err)
-	castedErr, ok := osb.IsHTTPError(err)
-	require.True(t, ok)
-	assert.Equal(t, http.StatusBadRequest, castedErr.StatusCode)
+	require.NoError(t, err)
+	assert.NoError(t, <-repeated)
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+
+	// WHEN
+	previewChecked = make(chan struct{})
+	ts.Exp.OperationID = "update-op-id"
+	repeated = sendRepeatedOnPreview(func() error {
+		_, err := ts.OSBClient().UpdateInstance(updateReq)
+		return err
+	})
+	_, err = ts.OSBClient().UpdateInstance(updateReq)
+
+	// THEN
+	require.NoError(t, err)
+	_, served := osb.IsHTTPError(<-repeated)
+	assert.True(t, served, "the repeated update must be rejected without waiting for the instance lock")
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+}
+
+func TestOSBAPIGetInstanceOnProvisioningInProgress(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIGetInstanceOnProvisioningInProgress)
+}
+
+func testOSBAPIGetInstanceOnProvisioningInProgress(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+	ts.StorageFactory.InstanceOperation().Insert(ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateInProgress))
+
+	// WHEN
+	_, err := ts.OSBClient().GetInstance(&osb.GetInstanceRequest{
+		InstanceID: string(ts.Exp.InstanceID),
+	})
+
+	// THEN
+	if !broker.SupportsInstanceRetrieval(apiVersion) {
+		assertHTTPStatusCode(t, http.StatusBadRequest, err)
+		return
+	}
+	assertHTTPStatusCode(t, http.StatusNotFound, err)
+}
+
+func TestOSBAPIGetInstanceOnDeprovisioningInProgress(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIGetInstanceOnDeprovisioningInProgress)
+}
+
+func testOSBAPIGetInstanceOnDeprovisioningInProgress(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+	ts.StorageFactory.InstanceOperation().Insert(ts.Exp.NewInstanceOperation(internal.OperationTypeRemove, internal.OperationStateInProgress))
+
+	// WHEN
+	_, err := ts.OSBClient().GetInstance(&osb.GetInstanceRequest{
+		InstanceID: string(ts.Exp.InstanceID),
+	})
+
+	// THEN
+	if !broker.SupportsInstanceRetrieval(apiVersion) {
+		assertHTTPStatusCode(t, http.StatusBadRequest, err)
+		return
+	}
+	assertHTTPStatusCode(t, http.StatusUnprocessableEntity, err)
 }
 
 func TestOSBAPIBindSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindSuccess)
+}
+
+func testOSBAPIBindSuccess(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	ts.ServerRun()
 	defer ts.ServerShutdown()
@@ -646,7 +1151,7 @@ func TestOSBAPIBindSuccess(t *testing.T) {
 		Context: map[string]interface{}{
 			"namespace": string(ts.Exp.Namespace),
 		},
-		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: `{"username": "admin", "uid": "123"}`},
 	}
 
 	// when
@@ -659,11 +1164,19 @@ func TestOSBAPIBindSuccess(t *testing.T) {
 	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
 
 	ts.AssertBindOperationState(internal.OperationStateSucceeded)
+
+	op, err := ts.StorageFactory.BindOperation().Get(ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, internal.OperationRequester{Username: "admin", UID: "123"}, op.RequestedBy)
 }
 
 func TestOSBAPIBindRepeatedOnAlreadyExistingBinding(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindRepeatedOnAlreadyExistingBinding)
+}
+
+func testOSBAPIBindRepeatedOnAlreadyExistingBinding(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Upsert(fixInstance)
@@ -710,19 +1223,149 @@ func TestOSBAPIBindRepeatedOnAlreadyExistingBinding(t *testing.T) {
 	}, resp.Credentials)
 }
 
-func TestOSBAPIBindRepeatedOnBindingInProgress(t *testing.T) {
+func TestOSBAPIBindRepeatedAfterBindingSucceeded(t *testing.T) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, "2.14")
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	fixAddon := ts.Exp.NewAddon()
+	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, fixAddon)
+	require.NoError(t, err)
+
+	fixChart := ts.Exp.NewChart()
+	ts.StorageFactory.Chart().Upsert(internal.ClusterWide, fixChart)
 
 	fixInstance := ts.Exp.NewInstance()
-	ts.StorageFactory.Instance().Insert(fixInstance)
+	ts.StorageFactory.Instance().Upsert(fixInstance)
 
-	fixOperation := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	req := &osb.BindRequest{
+		AcceptsIncomplete: true,
+		BindingID:         string(ts.Exp.BindingID),
+		InstanceID:        string(ts.Exp.InstanceID),
+		ServiceID:         string(ts.Exp.Service.ID),
+		PlanID:            string(ts.Exp.ServicePlan.ID),
+		Context: map[string]interface{}{
+			"namespace": string(ts.Exp.Namespace),
+		},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
+
+	resp, err := ts.OSBClient().Bind(req)
+	require.NoError(t, err)
+	require.True(t, resp.Async)
+	ts.AssertBindOperationState(internal.OperationStateSucceeded)
+
+	for _, step := range []string{"repeated bind", "bind repeated after getting the binding"} {
+		// when
+		resp, err = ts.OSBClient().Bind(req)
+
+		// then
+		require.NoError(t, err, step)
+		assert.False(t, resp.Async, step)
+		assert.EqualValues(t, map[string]interface{}{"password": "secret"}, resp.Credentials, step)
+
+		// the repeated bind must not run the bind operation again
+		assert.Never(t, func() bool {
+			op, err := ts.StorageFactory.BindOperation().Get(ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID)
+			require.NoError(t, err)
+			_, ibdErr := ts.StorageFactory.InstanceBindData().Get(ts.Exp.InstanceID, ts.Exp.BindingID)
+			return op.State != internal.OperationStateSucceeded || ibdErr != nil
+		}, 200*time.Millisecond, 10*time.Millisecond, step)
+
+		getResp, err := ts.OSBClient().GetBinding(&osb.GetBindingRequest{
+			InstanceID: string(ts.Exp.InstanceID),
+			BindingID:  string(ts.Exp.BindingID),
+		})
+		require.NoError(t, err, step)
+		assert.EqualValues(t, map[string]interface{}{"password": "secret"}, getResp.Credentials, step)
+	}
+
+	ops, err := ts.StorageFactory.BindOperation().GetAll(ts.Exp.InstanceID)
+	require.NoError(t, err)
+	assert.Len(t, ops, 1)
+}
+
+func TestOSBAPIBindConflictErrorOnAlreadyExistingBinding(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindConflictErrorOnAlreadyExistingBinding)
+}
+
+func testOSBAPIBindConflictErrorOnAlreadyExistingBinding(t *testing.T, apiVersion string) {
+	// given
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	fixInstance := ts.Exp.NewInstance()
+	ts.StorageFactory.Instance().Upsert(fixInstance)
+
+	fixAddon := ts.Exp.NewAddon()
+	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, fixAddon)
+	require.NoError(t, err)
+
+	fixChart := ts.Exp.NewChart()
+	ts.StorageFactory.Chart().Upsert(internal.ClusterWide, fixChart)
+
+	fixBindParams := map[string]interface{}{"username": "fix-user"}
+	fixOperation := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	fixOperation.Parameters = &internal.RequestParameters{Data: fixBindParams}
+	fixOperation.ParamsHash = broker.ParamsHash(fixBindParams)
 	ts.StorageFactory.BindOperation().Insert(fixOperation)
 
+	fixCreds := *ts.Exp.NewInstanceCredentials()
+	fixIbd := ts.Exp.NewInstanceBindData(fixCreds)
+	ts.StorageFactory.InstanceBindData().Insert(fixIbd)
+
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
+	req := &osb.BindRequest{
+		BindingID:         string(ts.Exp.BindingID),
+		InstanceID:        string(ts.Exp.InstanceID),
+		AcceptsIncomplete: true,
+		ServiceID:         string(ts.Exp.Service.ID),
+		PlanID:            string(ts.Exp.ServicePlan.ID),
+		Context: map[string]interface{}{
+			"namespace": string(ts.Exp.Namespace),
+		},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+		Parameters:          map[string]interface{}{"username": "other-user"},
+	}
+
+	// when
+	resp, err := ts.OSBClient().Bind(req)
+
+	// then
+	assert.Nil(t, resp)
+	assertHTTPStatusCode(t, http.StatusConflict, err)
+
+	// when
+	req.Parameters = fixBindParams
+	resp, err = ts.OSBClient().Bind(req)
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, resp.Async)
+	assert.EqualValues(t, map[string]interface{}{
+		"password": "secret",
+	}, resp.Credentials)
+}
+
+func TestOSBAPIBindRepeatedOnBindingInProgress(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindRepeatedOnBindingInProgress)
+}
+
+func testOSBAPIBindRepeatedOnBindingInProgress(t *testing.T, apiVersion string) {
+	// given
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	fixInstance := ts.Exp.NewInstance()
+	ts.StorageFactory.Instance().Insert(fixInstance)
+
+	fixOperation := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	ts.StorageFactory.BindOperation().Insert(fixOperation)
+
 	req := &osb.BindRequest{
 		BindingID:         string(ts.Exp.BindingID),
 		InstanceID:        string(ts.Exp.InstanceID),
@@ -745,9 +1388,13 @@ func TestOSBAPIBindRepeatedOnBindingInProgress(t *testing.T) {
 	assert.EqualValues(t, fixOperation.OperationID, *resp.OperationKey)
 }
 
-func TestOSBAPIBindingLastOperationSuccess(t *testing.T) {
+func TestOSBAPIBindingLastOperationSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindingLastOperationSuccess)
+}
+
+func testOSBAPIBindingLastOperationSuccess(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -774,8 +1421,12 @@ func TestOSBAPIBindingLastOperationSuccess(t *testing.T) {
 }
 
 func TestOSBAPIBindingLastOperationFailure(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindingLastOperationFailure)
+}
+
+func testOSBAPIBindingLastOperationFailure(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -801,39 +1452,115 @@ func TestOSBAPIBindingLastOperationFailure(t *testing.T) {
 
 }
 
-func TestOSBAPIBindFailureWithDisallowedParametersFieldInReqNS(t *testing.T) {
+func TestOSBAPIUnbindSuccess(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIUnbindSuccess)
+}
+
+func testOSBAPIUnbindSuccess(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	fixBindOp := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	fixBindOp.OperationID = internal.OperationID("fix-create-op-id")
+	require.NoError(t, ts.StorageFactory.BindOperation().Insert(fixBindOp))
+
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
-	fixAddon := ts.Exp.NewAddon()
-	ts.StorageFactory.Addon().Upsert(testNs, fixAddon)
+	req := &osb.UnbindRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		BindingID:           string(ts.Exp.BindingID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
 
 	// WHEN
-	req := &osb.BindRequest{
-		AcceptsIncomplete: true,
-		BindingID:         "bind-id",
-		InstanceID:        "instance-id",
-		ServiceID:         "svc-id",
-		PlanID:            "bind-id",
-		Parameters: map[string]interface{}{
-			"params": "set-but-not-allowed",
-		},
+	resp, err := ts.OSBClient().Unbind(req)
+
+	// THEN
+	require.NoError(t, err)
+	require.True(t, resp.Async)
+	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
+
+	ts.AssertBindOperationGone()
+
+	opKey := osb.OperationKey(ts.Exp.OperationID)
+	_, err = ts.OSBClient().PollBindingLastOperation(&osb.BindingLastOperationRequest{
+		InstanceID:   string(ts.Exp.InstanceID),
+		BindingID:    string(ts.Exp.BindingID),
+		OperationKey: &opKey,
+	})
+	assert.True(t, osb.IsGoneError(err))
+}
+
+func TestOSBAPIUnbindOnNotExistingBinding(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIUnbindOnNotExistingBinding)
+}
+
+func testOSBAPIUnbindOnNotExistingBinding(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	req := &osb.UnbindRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		BindingID:           string(ts.Exp.BindingID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
 		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
 	}
-	_, err := ts.OSBClientNS().Bind(req)
+
+	// WHEN
+	resp, err := ts.OSBClient().Unbind(req)
 
 	// THEN
-	require.Error(t, err)
-	castedErr, ok := osb.IsHTTPError(err)
-	require.True(t, ok)
-	assert.Equal(t, http.StatusBadRequest, castedErr.StatusCode)
+	require.NoError(t, err)
+	assert.False(t, resp.Async)
+}
+
+func TestOSBAPIUnbindOnBindingInProgress(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIUnbindOnBindingInProgress)
+}
+
+func testOSBAPIUnbindOnBindingInProgress(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	fixBindOp := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	require.NoError(t, ts.StorageFactory.BindOperation().Insert(fixBindOp))
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	req := &osb.UnbindRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		BindingID:           string(ts.Exp.BindingID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
+
+	// WHEN
+	_, err := ts.OSBClient().Unbind(req)
+
+	// THEN
+	assertHTTPStatusCode(t, http.StatusUnprocessableEntity, err)
+	ts.AssertBindOperationState(internal.OperationStateInProgress)
 }
 
 func TestOSBAPICatalogSuccessNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPICatalogSuccessNS)
+}
+
+func testOSBAPICatalogSuccessNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -848,10 +1575,14 @@ func TestOSBAPICatalogSuccessNS(t *testing.T) {
 }
 
 func TestOSBAPIProvisionSuccessNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIProvisionSuccessNS)
+}
+
+func testOSBAPIProvisionSuccessNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
-	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace).Return(&release.Release{Info: &release.Info{}}, nil).Once()
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}}, nil).Once()
 	defer ts.HelmClient.AssertExpectations(t)
 
 	ts.ServerRun()
@@ -891,8 +1622,12 @@ func TestOSBAPIProvisionSuccessNS(t *testing.T) {
 }
 
 func TestOSBAPIProvisionRepeatedOnAlreadyFullyProvisionedInstanceNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIProvisionRepeatedOnAlreadyFullyProvisionedInstanceNS)
+}
+
+func testOSBAPIProvisionRepeatedOnAlreadyFullyProvisionedInstanceNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -932,8 +1667,14 @@ func TestOSBAPIProvisionRepeatedOnAlreadyFullyProvisionedInstanceNS(t *testing.T
 }
 
 func TestOSBAPIProvisionRepeatedOnProvisioningInProgressNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIProvisionRepeatedOnProvisioningInProgressNS)
+}
+
+func testOSBAPIProvisionRepeatedOnProvisioningInProgressNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -943,9 +1684,6 @@ func TestOSBAPIProvisionRepeatedOnProvisioningInProgressNS(t *testing.T) {
 	fixOperation.OperationID = expOpID
 	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
 
-	ts.ServerRun()
-	defer ts.ServerShutdown()
-
 	nsUID := uuid.NewRandom().String()
 	req := &osb.ProvisionRequest{
 		AcceptsIncomplete: true,
@@ -977,8 +1715,12 @@ func TestOSBAPIProvisionRepeatedOnProvisioningInProgressNS(t *testing.T) {
 }
 
 func TestOSBAPIProvisionConflictErrorOnAlreadyFullyProvisionedInstanceNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIProvisionConflictErrorOnAlreadyFullyProvisionedInstanceNS)
+}
+
+func testOSBAPIProvisionConflictErrorOnAlreadyFullyProvisionedInstanceNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -1035,8 +1777,12 @@ func TestOSBAPIProvisionConflictErrorOnAlreadyFullyProvisionedInstanceNS(t *test
 }
 
 func TestOSBAPIDeprovisionOnAlreadyDeprovisionedInstanceNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIDeprovisionOnAlreadyDeprovisionedInstanceNS)
+}
+
+func testOSBAPIDeprovisionOnAlreadyDeprovisionedInstanceNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -1069,8 +1815,12 @@ func TestOSBAPIDeprovisionOnAlreadyDeprovisionedInstanceNS(t *testing.T) {
 }
 
 func TestOSBAPIDeprovisionOnAlreadyDeprovisionedAndRemovedInstanceNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIDeprovisionOnAlreadyDeprovisionedAndRemovedInstanceNS)
+}
+
+func testOSBAPIDeprovisionOnAlreadyDeprovisionedAndRemovedInstanceNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	// storage does not contain any data
 
 	ts.ServerRun()
@@ -1098,8 +1848,14 @@ func TestOSBAPIDeprovisionOnAlreadyDeprovisionedAndRemovedInstanceNS(t *testing.
 }
 
 func TestOSBAPIDeprovisionRepeatedOnDeprovisioningInProgressNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIDeprovisionRepeatedOnDeprovisioningInProgressNS)
+}
+
+func testOSBAPIDeprovisionRepeatedOnDeprovisioningInProgressNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -1109,9 +1865,6 @@ func TestOSBAPIDeprovisionRepeatedOnDeprovisioningInProgressNS(t *testing.T) {
 	fixOperation.OperationID = expOpID
 	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
 
-	ts.ServerRun()
-	defer ts.ServerShutdown()
-
 	req := &osb.DeprovisionRequest{
 		AcceptsIncomplete:   true,
 		InstanceID:          string(ts.Exp.InstanceID),
@@ -1134,15 +1887,19 @@ func TestOSBAPIDeprovisionRepeatedOnDeprovisioningInProgressNS(t *testing.T) {
 }
 
 func TestOSBAPIDeprovisionSuccessNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIDeprovisionSuccessNS)
+}
+
+func testOSBAPIDeprovisionSuccessNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
 	expOpID := internal.OperationID("fix-op-id")
 	fixOperation.OperationID = expOpID
 	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
 
-	ts.HelmClient.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace).Return(nil).Once()
+	ts.HelmClient.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(nil).Once()
 	defer ts.HelmClient.AssertExpectations(t)
 
 	ts.ServerRun()
@@ -1171,9 +1928,64 @@ func TestOSBAPIDeprovisionSuccessNS(t *testing.T) {
 	ts.AssertOperationState(internal.OperationStateSucceeded)
 }
 
+func TestOSBAPIUpdateSuccessNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIUpdateSuccessNS)
+}
+
+func testOSBAPIUpdateSuccessNS(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	fixOperation.OperationID = internal.OperationID("fix-op-id")
+	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
+
+	ts.HelmClient.On("Upgrade", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}}, nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	fixAddon := ts.Exp.NewAddon()
+	ts.StorageFactory.Addon().Upsert(testNs, fixAddon)
+
+	fixChart := ts.Exp.NewChart()
+	ts.StorageFactory.Chart().Upsert(testNs, fixChart)
+
+	fixInstance := ts.Exp.NewInstance()
+	ts.StorageFactory.Instance().Insert(fixInstance)
+
+	req := &osb.UpdateInstanceRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		Parameters:          map[string]interface{}{"foo": "bar"},
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
+
+	// WHEN
+	resp, err := ts.OSBClientNS().UpdateInstance(req)
+
+	// THEN
+	require.NoError(t, err)
+
+	require.True(t, resp.Async)
+	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
+
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+
+	gotInstance, err := ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", gotInstance.ProvisioningParameters.Data["foo"])
+}
+
 func TestOSBAPILastOperationSuccessNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPILastOperationSuccessNS)
+}
+
+func testOSBAPILastOperationSuccessNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -1198,8 +2010,12 @@ func TestOSBAPILastOperationSuccessNS(t *testing.T) {
 }
 
 func TestOSBAPILastOperationForNonExistingInstanceNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPILastOperationForNonExistingInstanceNS)
+}
+
+func testOSBAPILastOperationForNonExistingInstanceNS(t *testing.T, apiVersion string) {
 	// GIVEN
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -1219,8 +2035,12 @@ func TestOSBAPILastOperationForNonExistingInstanceNS(t *testing.T) {
 }
 
 func TestOSBAPIBindSuccessNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindSuccessNS)
+}
+
+func testOSBAPIBindSuccessNS(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	ts.ServerRun()
 	defer ts.ServerShutdown()
@@ -1259,8 +2079,12 @@ func TestOSBAPIBindSuccessNS(t *testing.T) {
 }
 
 func TestOSBAPIBindRepeatedOnAlreadyExistingBindingNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindRepeatedOnAlreadyExistingBindingNS)
+}
+
+func testOSBAPIBindRepeatedOnAlreadyExistingBindingNS(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -1307,8 +2131,14 @@ func TestOSBAPIBindRepeatedOnAlreadyExistingBindingNS(t *testing.T) {
 }
 
 func TestOSBAPIBindRepeatedOnBindingInProgressNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindRepeatedOnBindingInProgressNS)
+}
+
+func testOSBAPIBindRepeatedOnBindingInProgressNS(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
+	ts.ServerRun()
+	defer ts.ServerShutdown()
 
 	fixInstance := ts.Exp.NewInstance()
 	ts.StorageFactory.Instance().Insert(fixInstance)
@@ -1316,9 +2146,6 @@ func TestOSBAPIBindRepeatedOnBindingInProgressNS(t *testing.T) {
 	fixOperation := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
 	ts.StorageFactory.BindOperation().Insert(fixOperation)
 
-	ts.ServerRun()
-	defer ts.ServerShutdown()
-
 	req := &osb.BindRequest{
 		BindingID:         string(ts.Exp.BindingID),
 		InstanceID:        string(ts.Exp.InstanceID),
@@ -1342,8 +2169,12 @@ func TestOSBAPIBindRepeatedOnBindingInProgressNS(t *testing.T) {
 }
 
 func TestOSBAPIBindingLastOperationSuccessNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindingLastOperationSuccessNS)
+}
+
+func testOSBAPIBindingLastOperationSuccessNS(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -1370,8 +2201,12 @@ func TestOSBAPIBindingLastOperationSuccessNS(t *testing.T) {
 }
 
 func TestOSBAPIBindingLastOperationFailureNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIBindingLastOperationFailureNS)
+}
+
+func testOSBAPIBindingLastOperationFailureNS(t *testing.T, apiVersion string) {
 	// given
-	ts := newOSBAPITestSuite(t)
+	ts := newOSBAPITestSuite(t, apiVersion)
 	ts.ServerRun()
 	defer ts.ServerShutdown()
 
@@ -1397,18 +2232,169 @@ func TestOSBAPIBindingLastOperationFailureNS(t *testing.T) {
 
 }
 
+func TestOSBAPIUnbindSuccessNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIUnbindSuccessNS)
+}
+
+func testOSBAPIUnbindSuccessNS(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	fixBindOp := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	fixBindOp.OperationID = internal.OperationID("fix-create-op-id")
+	require.NoError(t, ts.StorageFactory.BindOperation().Insert(fixBindOp))
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	req := &osb.UnbindRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		BindingID:           string(ts.Exp.BindingID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
+
+	// WHEN
+	resp, err := ts.OSBClientNS().Unbind(req)
+
+	// THEN
+	require.NoError(t, err)
+	require.True(t, resp.Async)
+	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
+
+	ts.AssertBindOperationGone()
+
+	opKey := osb.OperationKey(ts.Exp.OperationID)
+	_, err = ts.OSBClientNS().PollBindingLastOperation(&osb.BindingLastOperationRequest{
+		InstanceID:   string(ts.Exp.InstanceID),
+		BindingID:    string(ts.Exp.BindingID),
+		OperationKey: &opKey,
+	})
+	assert.True(t, osb.IsGoneError(err))
+}
+
+func TestOSBAPIUnbindOnNotExistingBindingNS(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIUnbindOnNotExistingBindingNS)
+}
+
+func testOSBAPIUnbindOnNotExistingBindingNS(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	req := &osb.UnbindRequest{
+		AcceptsIncomplete:   true,
+		InstanceID:          string(ts.Exp.InstanceID),
+		BindingID:           string(ts.Exp.BindingID),
+		ServiceID:           string(ts.Exp.Service.ID),
+		PlanID:              string(ts.Exp.ServicePlan.ID),
+		OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+	}
+
+	// WHEN
+	resp, err := ts.OSBClientNS().Unbind(req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.False(t, resp.Async)
+}
+
 type fakeBindTmplRenderer struct{}
 
-func (fakeBindTmplRenderer) Render(bindTemplate internal.AddonPlanBindTemplate, instance *internal.Instance, chart *chart.Chart) (bind.RenderedBindYAML, error) {
+func (fakeBindTmplRenderer) Render(bindTemplate internal.AddonPlanBindTemplate, instance *internal.Instance, bindingID internal.BindingID, bindParams map[string]interface{}, chart *chart.Chart) (bind.RenderedBindYAML, error) {
 	return []byte(`fake`), nil
 }
 
+// fakeDashboardURLRenderer returns the dashboard URL template with the release name and the host value substituted
+type fakeDashboardURLRenderer struct {
+	// onRender is called before the URL is rendered, e.g. to send other requests while the broker renders the URL
+	onRender func()
+}
+
+func (r *fakeDashboardURLRenderer) RenderDashboardURL(dashboardURLTemplate internal.AddonPlanDashboardURLTemplate, instance *internal.Instance, chart *chart.Chart) (string, error) {
+	if r.onRender != nil {
+		r.onRender()
+	}
+	return strings.NewReplacer(
+		"{{ .Release.Name }}", string(instance.ReleaseName),
+		"{{ .Values.host }}", fmt.Sprint(instance.ReleaseInfo.ConfigValues["host"]),
+	).Replace(string(dashboardURLTemplate)), nil
+}
+
 type fakeBindTmplResolver struct{}
 
 func (fakeBindTmplResolver) Resolve(bindYAML bind.RenderedBindYAML, ns internal.Namespace) (*bind.ResolveOutput, error) {
-	return &bind.ResolveOutput{}, nil
+	return &bind.ResolveOutput{Credentials: internal.InstanceCredentials{"password": "secret"}}, nil
+}
+
+func assertHTTPStatusCode(t *testing.T, exp int, err error) {
+	t.Helper()
+	httpErr, ok := osb.IsHTTPError(err)
+	require.True(t, ok, "expected HTTP error, got: %v", err)
+	assert.Equal(t, exp, httpErr.StatusCode)
+}
+
+func ptrBool(b bool) *bool {
+	return &b
 }
 
 func ptrStr(str string) *string {
 	return &str
 }
+
+func TestOSBAPIServerRunFailsOperationInterruptedByRestart(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIServerRunFailsOperationInterruptedByRestart)
+}
+
+func testOSBAPIServerRunFailsOperationInterruptedByRestart(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	fixInstance := ts.Exp.NewInstance()
+	ts.StorageFactory.Instance().Insert(fixInstance)
+
+	fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateInProgress)
+	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
+
+	// WHEN
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	// THEN
+	ts.AssertOperationState(internal.OperationStateFailed)
+	op, err := ts.StorageFactory.InstanceOperation().Get(ts.Exp.InstanceID, ts.Exp.OperationID)
+	require.NoError(t, err)
+	assert.Equal(t, "operation was interrupted by the broker restart", *op.StateDescription)
+
+	// No activity should happen
+	defer ts.HelmClient.AssertExpectations(t)
+}
+
+func TestOSBAPIServerRunResumesDeprovisioningInterruptedByRestart(t *testing.T) {
+	runForEachOSBAPIVersion(t, testOSBAPIServerRunResumesDeprovisioningInterruptedByRestart)
+}
+
+func testOSBAPIServerRunResumesDeprovisioningInterruptedByRestart(t *testing.T, apiVersion string) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, apiVersion)
+
+	fixInstance := ts.Exp.NewInstance()
+	ts.StorageFactory.Instance().Insert(fixInstance)
+
+	fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeRemove, internal.OperationStateInProgress)
+	ts.StorageFactory.InstanceOperation().Insert(fixOperation)
+
+	ts.HelmClient.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	// WHEN
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+
+	// THEN
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+}
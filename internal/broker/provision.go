@@ -7,7 +7,7 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
-	"sync"
+	"time"
 
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 
@@ -15,6 +15,7 @@ import (
 	"github.com/kyma-project/helm-broker/internal"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	helmErrors "helm.sh/helm/v3/pkg/storage/driver"
 	rls "k8s.io/helm/pkg/proto/hapi/services"
 )
 
@@ -33,54 +34,107 @@ type provisionService struct {
 	operationUpdater    operationUpdater
 	operationIDProvider func() (internal.OperationID, error)
 	helmInstaller       helmInstaller
-	mu                  sync.Mutex
+	helmTester          helmTester
+	instanceLocker      *instanceLocker
+	operationQueue      *operationQueue
+
+	// atomicProvisioning enables the cleanup of failed provisioning, it can be overridden by the plan
+	atomicProvisioning bool
+	// synchronousTimeout defines how long the provisioning of the plan which allows for synchronous operations is awaited
+	synchronousTimeout time.Duration
+	helmDeleter        helmDeleter
+	instanceRemover    instanceRemover
+
+	dashboardRenderer dashboardURLRenderer
 
 	log *logrus.Entry
 
 	testHookAsyncCalled func(internal.OperationID)
 }
 
+// Provision provisions the instance asynchronously. When the Platform does not accept the asynchronous operation mode
+// and the plan allows for synchronous operations, the provisioning is awaited. The response is not asynchronous
+// and has the operation key when the provisioning is finished within the timeout.
 func (svc *provisionService) Provision(ctx context.Context, osbCtx OsbContext, req *osb.ProvisionRequest) (*osb.ProvisionResponse, *osb.HTTPStatusCodeError) {
-	if !req.AcceptsIncomplete {
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr("asynchronous operation mode required")}
+	resp, result, preview, err := svc.provision(ctx, osbCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if preview != nil {
+		if dashboardURL, err := preview(); err != nil {
+			svc.log.Errorf("Cannot render dashboard URL of instance %s: %v", req.InstanceID, err)
+		} else if dashboardURL != "" {
+			resp.DashboardURL = &dashboardURL
+		}
 	}
 
-	// Single provisioning is supported concurrently.
-	// TODO: switch to lock per instanceID
-	svc.mu.Lock()
-	defer svc.mu.Unlock()
+	if result == nil {
+		return resp, nil
+	}
 
-	svc.log.Infof("Triggered provisioning %+v", req)
+	res, finished := waitForOperation(ctx, result, svc.synchronousTimeout)
+	switch {
+	case !finished:
+		svc.log.Infof("Provisioning of instance %s was not finished within %v, it is continued asynchronously", req.InstanceID, svc.synchronousTimeout)
+		return resp, nil
+	case res.state == internal.OperationStateFailed:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(res.description)}
+	}
 
+	resp.Async = false
+	if instance, err := svc.instanceGetter.Get(internal.InstanceID(req.InstanceID)); err == nil && instance.DashboardURL != "" {
+		resp.DashboardURL = &instance.DashboardURL
+	}
+	return resp, nil
+}
+
+// provision starts the provisioning, the result channel is returned when the provisioning must be awaited.
+// The preview of the dashboard URL is returned for the started provisioning.
+func (svc *provisionService) provision(ctx context.Context, osbCtx OsbContext, req *osb.ProvisionRequest) (*osb.ProvisionResponse, <-chan operationResult, dashboardURLPreview, *osb.HTTPStatusCodeError) {
 	iID := internal.InstanceID(req.InstanceID)
+
+	svc.instanceLocker.Lock(iID)
+	defer svc.instanceLocker.Unlock(iID)
+
+	svc.log.Infof("Triggered provisioning %+v", req)
+
 	requestedProvisioningParameters := internal.RequestParameters{
 		Data: req.Parameters,
 	}
 
 	switch alreadyProvisioned, err := svc.instanceStateGetter.IsProvisioned(iID); {
 	case err != nil:
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if instance is already provisioned: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if instance is already provisioned: %v", err))}
 	case alreadyProvisioned:
-		switch conflictOccurred, err := svc.requestedParametersAreDifferent(iID, requestedProvisioningParameters); {
+		instance, conflictOccurred, err := svc.requestedParametersAreDifferent(iID, requestedProvisioningParameters)
+		switch {
 		case err != nil:
-			return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while comparing provisioning parameters %v: %v", req.Parameters, err))}
+			return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while comparing provisioning parameters %v: %v", req.Parameters, err))}
 		case conflictOccurred:
-			return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusConflict, ErrorMessage: strPtr(fmt.Sprintf("service instance exists with different parameters: %v", req.Parameters))}
+			return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusConflict, ErrorMessage: strPtr(fmt.Sprintf("service instance exists with different parameters: %v", req.Parameters))}
+		}
+		resp := &osb.ProvisionResponse{Async: false}
+		if instance.DashboardURL != "" {
+			resp.DashboardURL = &instance.DashboardURL
 		}
-		return &osb.ProvisionResponse{Async: false}, nil
+		return resp, nil, nil, nil
 	}
 
 	switch opIDInProgress, inProgress, err := svc.instanceStateGetter.IsProvisioningInProgress(iID); {
 	case err != nil:
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if instance provisioning is in progress: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if instance provisioning is in progress: %v", err))}
+	case inProgress && !req.AcceptsIncomplete:
+		// the provisioning in progress can be reported only asynchronously, also for the plans with synchronous operations
+		return nil, nil, nil, asyncRequiredError()
 	case inProgress:
 		opKeyInProgress := osb.OperationKey(opIDInProgress)
-		return &osb.ProvisionResponse{Async: true, OperationKey: &opKeyInProgress}, nil
+		return &osb.ProvisionResponse{Async: true, OperationKey: &opKeyInProgress}, nil, nil, nil
 	}
 
 	namespace, err := getNamespaceFromContext(req.Context)
 	if err != nil {
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting namespace from context: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting namespace from context: %v", err))}
 	}
 
 	svc.log.Infof("Provisioning %v in namespace [%s]", req.Parameters, req.Context["namespace"])
@@ -91,26 +145,46 @@ func (svc *provisionService) Provision(ctx context.Context, osbCtx OsbContext, r
 	addon, err := svc.addonIDGetter.GetByID(osbCtx.BrokerNamespace, addonID)
 	switch {
 	case IsNotFoundError(err):
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting addon: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting addon: %v", err))}
 	case err != nil:
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting addon: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting addon: %v", err))}
 	}
 
 	instances, err := svc.instanceGetter.GetAll()
 	switch {
 	case IsNotFoundError(err):
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting instance collection: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting instance collection: %v", err))}
 	case err != nil:
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting instance collection: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting instance collection: %v", err))}
 	}
 	if !addon.IsProvisioningAllowed(namespace, instances) {
 		svc.log.Infof("addon with name: %q (id: %s) and flag 'provisionOnlyOnce' in namespace %q will be not provisioned because his instance already exist", addon.Name, addon.ID, namespace)
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon with name: %q (id: %s) and flag 'provisionOnlyOnce' in namespace %q will be not provisioned because his instance already exist", addon.Name, addon.ID, namespace))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon with name: %q (id: %s) and flag 'provisionOnlyOnce' in namespace %q will be not provisioned because his instance already exist", addon.Name, addon.ID, namespace))}
+	}
+
+	svcPlanID := internal.ServicePlanID(req.PlanID)
+
+	// addonPlanID is in 1:1 match with servicePlanID (from service catalog)
+	addonPlanID := internal.AddonPlanID(svcPlanID)
+	addonPlan, found := addon.Plans[addonPlanID]
+	if !found {
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon does not contain requested plan (planID: %s)", addonPlanID))}
+	}
+
+	if !req.AcceptsIncomplete && !addonPlan.SynchronousOperations {
+		return nil, nil, nil, asyncRequiredError()
+	}
+
+	switch err := validateParameters(addonPlan, internal.SchemaTypeProvision, req.Parameters); {
+	case IsParametersValidationError(err):
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("provisioning parameters are invalid: %v", err))}
+	case err != nil:
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while validating provisioning parameters: %v", err))}
 	}
 
 	opID, err := svc.operationIDProvider()
 	if err != nil {
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while generating operation ID: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while generating operation ID: %v", err))}
 	}
 
 	op := internal.InstanceOperation{
@@ -119,20 +193,13 @@ func (svc *provisionService) Provision(ctx context.Context, osbCtx OsbContext, r
 		Type:                   internal.OperationTypeCreate,
 		State:                  internal.OperationStateInProgress,
 		ProvisioningParameters: &requestedProvisioningParameters,
+		RequestedBy:            osbCtx.requester(),
 	}
 
 	if err := svc.operationInserter.Insert(&op); err != nil {
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while inserting instance operation to storage: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while inserting instance operation to storage: %v", err))}
 	}
 
-	svcPlanID := internal.ServicePlanID(req.PlanID)
-
-	// addonPlanID is in 1:1 match with servicePlanID (from service catalog)
-	addonPlanID := internal.AddonPlanID(svcPlanID)
-	addonPlan, found := addon.Plans[addonPlanID]
-	if !found {
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon does not contain requested plan (planID: %s): %v", err, addonPlanID))}
-	}
 	releaseName := createReleaseName(addon.Name, addonPlan.Name, iID)
 
 	i := internal.Instance{
@@ -143,18 +210,20 @@ func (svc *provisionService) Provision(ctx context.Context, osbCtx OsbContext, r
 		ReleaseName:            releaseName,
 		ReleaseInfo:            internal.ReleaseInfo{},
 		ProvisioningParameters: &requestedProvisioningParameters,
+		AddonVersion:           internal.AddonVersion(addon.Version.String()),
+		HelmOptions:            addonPlan.HelmOptions,
 	}
 
+	chartOverrides := internal.ChartValues(requestedProvisioningParameters.Data)
+
 	exist, err := svc.instanceInserter.Upsert(&i)
 	if err != nil {
-		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while inserting instance to storage: %v", err))}
+		return nil, nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while inserting instance to storage: %v", err))}
 	}
 	if exist {
 		svc.log.Infof("Instance %s already existed in storage, instance was replaced", i.ID)
 	}
 
-	chartOverrides := internal.ChartValues(requestedProvisioningParameters.Data)
-
 	provisionInput := provisioningInput{
 		instanceID:          iID,
 		operationID:         opID,
@@ -166,9 +235,17 @@ func (svc *provisionService) Provision(ctx context.Context, osbCtx OsbContext, r
 		addonsRepositoryURL: addon.RepositoryURL,
 		chartOverrides:      chartOverrides,
 		instanceToUpdate:    &i,
+		atomic:              svc.isAtomic(addonPlan),
 	}
 
-	svc.doAsync(ctx, provisionInput)
+	// the instance is modified by the operation, so the preview gets its copy before the operation is started
+	var preview dashboardURLPreview
+	if values, err := releaseValues(addonPlan, chartOverrides, addon.RepositoryURL); err != nil {
+		svc.log.Errorf("Cannot render dashboard URL of instance %s: %v", iID, err)
+	} else {
+		preview = newDashboardURLPreview(svc.chartGetter, svc.dashboardRenderer, osbCtx.BrokerNamespace, addonPlan, i, values)
+	}
+	result := svc.doAsync(ctx, provisionInput)
 
 	opKey := osb.OperationKey(op.OperationID)
 	resp := &osb.ProvisionResponse{
@@ -176,7 +253,10 @@ func (svc *provisionService) Provision(ctx context.Context, osbCtx OsbContext, r
 		Async:        true,
 	}
 
-	return resp, nil
+	if req.AcceptsIncomplete {
+		return resp, nil, preview, nil
+	}
+	return resp, result, preview, nil
 }
 
 // provisioningInput holds all information required to provision a given instance
@@ -191,17 +271,24 @@ type provisioningInput struct {
 	chartOverrides      internal.ChartValues
 	addonsRepositoryURL string
 	instanceToUpdate    *internal.Instance
+	atomic              bool
 }
 
-func (svc *provisionService) doAsync(ctx context.Context, input provisioningInput) {
+func (svc *provisionService) doAsync(ctx context.Context, input provisioningInput) <-chan operationResult {
 	if svc.testHookAsyncCalled != nil {
 		svc.testHookAsyncCalled(input.operationID)
 	}
-	go svc.do(ctx, input)
+	result := make(chan operationResult, 1)
+	svc.operationQueue.Enqueue(asyncOperation{
+		operationID: input.operationID,
+		namespace:   input.namespace,
+		do:          func(ctx context.Context) { result <- svc.do(ctx, input) },
+	})
+	return result
 }
 
 // do is called asynchronously
-func (svc *provisionService) do(ctx context.Context, input provisioningInput) {
+func (svc *provisionService) do(ctx context.Context, input provisioningInput) operationResult {
 
 	fDo := func() error {
 
@@ -210,19 +297,15 @@ func (svc *provisionService) do(ctx context.Context, input provisioningInput) {
 			return errors.Wrap(err, "while getting chart from storage")
 		}
 
-		out, err := deepCopy(input.addonPlan.ChartValues)
+		out, err := releaseValues(input.addonPlan, input.chartOverrides, input.addonsRepositoryURL)
 		if err != nil {
-			return errors.Wrap(err, "while coping plan values")
+			return err
 		}
 
-		out = mergeValues(out, input.chartOverrides)
-
-		out[addonsRepositoryURLName] = input.addonsRepositoryURL
-
 		svc.log.Infof("Merging values for operation [%s], releaseName [%s], namespace [%s], addonPlan [%s]. Plan values are: [%v], overrides: [%v], merged: [%v] ",
 			input.operationID, input.releaseName, input.namespace, input.addonPlan.Name, input.addonPlan.ChartValues, input.chartOverrides, out)
 
-		resp, err := svc.helmInstaller.Install(c, out, input.releaseName, input.namespace)
+		resp, err := svc.helmInstaller.Install(c, out, input.releaseName, input.namespace, input.addonPlan.HelmOptions)
 		if err != nil {
 			cause := errors.Cause(err)
 			if apiErrors.IsForbidden(cause) {
@@ -242,6 +325,13 @@ func (svc *provisionService) do(ctx context.Context, input provisioningInput) {
 		updatedInstance := input.instanceToUpdate
 		updatedInstance.ReleaseInfo = relInfo
 
+		// the dashboard URL rendered before the installation is kept when the rendering fails
+		if url, err := renderDashboardURL(svc.dashboardRenderer, input.addonPlan, updatedInstance, c); err != nil {
+			svc.log.Errorf("Cannot render dashboard URL of instance %s: %v", updatedInstance.ID, err)
+		} else {
+			updatedInstance.DashboardURL = url
+		}
+
 		exist, err := svc.instanceInserter.Upsert(updatedInstance)
 		if err != nil {
 			return &osb.HTTPStatusCodeError{StatusCode: http.StatusConflict, ErrorMessage: strPtr(fmt.Sprintf("while updating instance in storage: %v", err))}
@@ -250,7 +340,7 @@ func (svc *provisionService) do(ctx context.Context, input provisioningInput) {
 			svc.log.Infof("Instance %s already existed in storage, instance was replaced on update", updatedInstance.ID)
 		}
 
-		return nil
+		return runChartTests(svc.helmTester, input.addonPlan, input.releaseName, input.namespace)
 	}
 
 	opState := internal.OperationStateSucceeded
@@ -261,37 +351,82 @@ func (svc *provisionService) do(ctx context.Context, input provisioningInput) {
 	if err != nil {
 		opState = internal.OperationStateFailed
 		opDesc = fmt.Sprintf("provisioning failed on error: %s", err.Error())
+		if input.atomic {
+			opDesc = fmt.Sprintf("%s; %s", opDesc, svc.cleanUpFailedProvisioning(input))
+		}
 	}
 
 	if err := svc.operationUpdater.UpdateStateDesc(input.instanceID, input.operationID, opState, &opDesc); err != nil {
 		svc.log.Errorf("State description was not updated, got error: %v", err)
 	}
+
+	return operationResult{state: opState, description: opDesc}
 }
 
-func (svc *provisionService) requestedParametersAreDifferent(iID internal.InstanceID, requestedParams internal.RequestParameters) (bool, error) {
+func (svc *provisionService) isAtomic(plan internal.AddonPlan) bool {
+	if plan.AtomicProvisioning != nil {
+		return *plan.AtomicProvisioning
+	}
+	return svc.atomicProvisioning
+}
+
+// cleanUpFailedProvisioning removes the partially installed release and the instance, so the Platform does not need
+// to deprovision the instance which was not provisioned. It returns the description of the cleanup result.
+func (svc *provisionService) cleanUpFailedProvisioning(input provisioningInput) string {
+	svc.log.Infof("Cleaning up failed provisioning of instance [%s], releaseName [%s], namespace [%s]", input.instanceID, input.releaseName, input.namespace)
+
+	err := svc.helmDeleter.Delete(input.releaseName, input.namespace, input.addonPlan.HelmOptions)
+	if err != nil && !errors.Is(err, helmErrors.ErrReleaseNotFound) {
+		return fmt.Sprintf("cleanup failed on error: while deleting helm release %q: %s", input.releaseName, err.Error())
+	}
+
+	err = svc.instanceRemover.Remove(input.instanceID)
+	switch {
+	case err == nil, IsNotFoundError(err):
+	default:
+		return fmt.Sprintf("cleanup failed on error: while removing instance entity from storage: %s", err.Error())
+	}
+
+	return fmt.Sprintf("helm release %q and instance were removed", input.releaseName)
+}
+
+// requestedParametersAreDifferent compares the parameters of the provisioned instance with the requested ones, it returns the provisioned instance
+func (svc *provisionService) requestedParametersAreDifferent(iID internal.InstanceID, requestedParams internal.RequestParameters) (*internal.Instance, bool, error) {
 	instance, err := svc.instanceGetter.Get(iID)
 	if err != nil {
-		return false, errors.Wrapf(err, "while getting instance %s from storage", iID)
+		return nil, false, errors.Wrapf(err, "while getting instance %s from storage", iID)
 	}
 
-	if instance.ParamsHash != "" {
+	// the hash stored by the old versions of the broker does not match the stored parameters, they are replaced by the requested ones.
+	// The hash of the parameters stored by the update is kept.
+	if instance.ParamsHash != "" && !paramsHashMatches(instance) {
 		instance.ParamsHash = ""
 		instance.ProvisioningParameters = &requestedParams
 		if _, err := svc.instanceInserter.Upsert(instance); err != nil {
-			return false, errors.Wrapf(err, "while saving instance %s to storage", iID)
+			return nil, false, errors.Wrapf(err, "while saving instance %s to storage", iID)
 		}
-		return false, nil
+		return instance, false, nil
 	}
 
 	if instance.ProvisioningParameters == nil && len(requestedParams.Data) == 0 { // instance with given ID has empty parameters and request parameters are also empty
-		return false, nil
+		return instance, false, nil
 	}
 
 	if instance.ProvisioningParameters != nil && reflect.DeepEqual(instance.ProvisioningParameters.Data, requestedParams.Data) { // OR instance parameters are identical to request parameters
-		return false, nil
+		return instance, false, nil
 	}
 
-	return true, nil
+	return instance, true, nil
+}
+
+// paramsHashMatches returns true when the instance parameters hash was computed from the stored provisioning parameters
+func paramsHashMatches(instance *internal.Instance) bool {
+	var data map[string]interface{}
+	if instance.ProvisioningParameters != nil {
+		data = instance.ProvisioningParameters.Data
+	}
+	hash, err := requestParamsHash(data)
+	return err == nil && hash == instance.ParamsHash
 }
 
 func getNamespaceFromContext(contextProfile map[string]interface{}) (internal.Namespace, error) {
@@ -310,10 +445,14 @@ func normalize(name string) string {
 	return strings.Trim(name, "-")
 }
 
+// releaseNamePrefix is the prefix of names of all releases created by the broker
+const releaseNamePrefix = "hb-"
+
 func createReleaseName(name internal.AddonName, planName internal.AddonPlanName, iID internal.InstanceID) internal.ReleaseName {
 	// max name length 53 = 36(GUID) + 4(pre + special char) + 12 (6 for name, 6 for plan) + 1 extra char
 	releaseName := fmt.Sprintf(
-		"hb-%s-%s-%s",
+		"%s%s-%s-%s",
+		releaseNamePrefix,
 		normalize(string(name)),
 		normalize(string(planName)),
 		iID)
@@ -321,6 +460,20 @@ func createReleaseName(name internal.AddonName, planName internal.AddonPlanName,
 	return internal.ReleaseName(releaseName)
 }
 
+// releaseValues returns the plan values merged with the overrides, the release of the plan is installed or upgraded with them
+func releaseValues(plan internal.AddonPlan, overrides internal.ChartValues, addonsRepositoryURL string) (internal.ChartValues, error) {
+	out, err := deepCopy(plan.ChartValues)
+	if err != nil {
+		return nil, errors.Wrap(err, "while coping plan values")
+	}
+
+	out = mergeValues(out, overrides)
+
+	out[addonsRepositoryURLName] = addonsRepositoryURL
+
+	return out, nil
+}
+
 // to work correctly, https://github.com/ghodss/yaml has to be used
 func mergeValues(dest map[string]interface{}, src map[string]interface{}) map[string]interface{} {
 	for k, v := range src {
@@ -21,9 +21,18 @@ func NewProvisionService(bg addonIDGetter, cg chartGetter, is instanceStorage, i
 		operationIDProvider: oIDProv,
 		helmInstaller:       hi,
 		log:                 log,
+		instanceLocker:      newInstanceLocker(),
+		operationQueue:      newStartedOperationQueue(),
 	}
 }
 
+func (svc *provisionService) WithAtomicProvisioning(enabled bool, hd helmDeleter, ir instanceRemover) *provisionService {
+	svc.atomicProvisioning = enabled
+	svc.helmDeleter = hd
+	svc.instanceRemover = ir
+	return svc
+}
+
 func (svc *provisionService) WithTestHookOnAsyncCalled(h func(internal.OperationID)) *provisionService {
 	svc.testHookAsyncCalled = h
 	return svc
@@ -3,6 +3,8 @@ package broker_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -106,7 +108,7 @@ func TestProvisionServiceProvisionSuccessAsyncInstall(t *testing.T) {
 	expChartOverrides := internal.ChartValues{
 		"addonsRepositoryURL": expAddon.RepositoryURL,
 	}
-	hiMock.On("Install", &expChart, expChartOverrides, ts.Exp.ReleaseName, ts.Exp.Namespace).Return(releaseResp, nil).Once()
+	hiMock.On("Install", &expChart, expChartOverrides, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(releaseResp, nil).Once()
 
 	oipFake := func() (internal.OperationID, error) {
 		return ts.Exp.OperationID, nil
@@ -221,6 +223,101 @@ func TestProvisionServiceProvisionFailureAsync(t *testing.T) {
 	}
 }
 
+func TestProvisionServiceProvisionFailureAsyncAtomic(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		brokerAtomic bool
+		planAtomic   *bool
+		expCleanup   bool
+	}{
+		"cleanup enabled by broker": {
+			brokerAtomic: true,
+			expCleanup:   true,
+		},
+		"cleanup enabled by plan": {
+			brokerAtomic: false,
+			planAtomic:   ptrBool(true),
+			expCleanup:   true,
+		},
+		"cleanup disabled by plan": {
+			brokerAtomic: true,
+			planAtomic:   ptrBool(false),
+			expCleanup:   false,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			ts := newProvisionServiceTestSuite(t)
+			ts.SetUp()
+
+			isgMock := &automock.InstanceStateGetter{}
+			defer isgMock.AssertExpectations(t)
+			isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(false, nil).Once()
+			isgMock.On("IsProvisioningInProgress", ts.Exp.InstanceID).Return(internal.OperationID(""), false, nil).Once()
+
+			bgMock := &automock.AddonStorage{}
+			defer bgMock.AssertExpectations(t)
+			expAddon := ts.FixAddon()
+			plan := expAddon.Plans[ts.Exp.AddonPlan.ID]
+			plan.AtomicProvisioning = tc.planAtomic
+			expAddon.Plans[ts.Exp.AddonPlan.ID] = plan
+			bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+
+			cgMock := &automock.ChartGetter{}
+			defer cgMock.AssertExpectations(t)
+			expChart := ts.FixChart()
+			cgMock.On("Get", internal.ClusterWide, ts.Exp.Chart.Name, ts.Exp.Chart.Version).Return(&expChart, nil).Once()
+
+			iiMock := &automock.InstanceStorage{}
+			defer iiMock.AssertExpectations(t)
+			expInstance := ts.FixInstance()
+			expInstance.ParamsHash = ""
+			iiMock.On("Upsert", &expInstance).Return(true, nil)
+			iiMock.On("GetAll").Return(ts.FixInstanceCollection(), nil)
+
+			hcMock := &automock.HelmClient{}
+			defer hcMock.AssertExpectations(t)
+			hcMock.On("Install", &expChart, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(nil, errors.New("fake-install-error")).Once()
+
+			expDesc := "provisioning failed on error: while installing helm release: fake-install-error"
+			if tc.expCleanup {
+				hcMock.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(nil).Once()
+				iiMock.On("Remove", ts.Exp.InstanceID).Return(nil).Once()
+				expDesc = fmt.Sprintf("%s; helm release %q and instance were removed", expDesc, ts.Exp.ReleaseName)
+			}
+
+			ioMock := &automock.OperationStorage{}
+			defer ioMock.AssertExpectations(t)
+			expInstOp := ts.FixInstanceOperation()
+			ioMock.On("Insert", &expInstOp).Return(nil).Once()
+			operationFailed := make(chan struct{})
+			ioMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.OperationID, internal.OperationStateFailed, &expDesc).Return(nil).Once().
+				Run(func(mock.Arguments) { close(operationFailed) })
+
+			oipFake := func() (internal.OperationID, error) {
+				return ts.Exp.OperationID, nil
+			}
+
+			svc := broker.NewProvisionService(bgMock, cgMock, iiMock, isgMock, ioMock, ioMock, hcMock, oipFake, spy.NewLogDummy()).
+				WithAtomicProvisioning(tc.brokerAtomic, hcMock, iiMock)
+
+			req := ts.FixProvisionRequest()
+
+			// WHEN
+			resp, err := svc.Provision(context.Background(), *broker.NewOSBContext("", "v1"), &req)
+
+			// THEN
+			assert.Nil(t, err)
+			assert.True(t, resp.Async)
+
+			select {
+			case <-operationFailed:
+			case <-time.After(time.Millisecond * 100):
+				t.Fatal("timeout on operation failed")
+			}
+		})
+	}
+}
+
 func TestProvisionServiceProvisionSuccessRepeatedOnAlreadyFullyProvisionedInstance(t *testing.T) {
 	// GIVEN
 	ts := newProvisionServiceTestSuite(t)
@@ -389,3 +486,96 @@ func TestProvisionServiceProvisionSuccessRepeatedOnProvisioningInProgress(t *tes
 	default:
 	}
 }
+
+func TestProvisionServiceProvisionFailureAsyncRequiredOnProvisioningInProgress(t *testing.T) {
+	// GIVEN
+	ts := newProvisionServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(false, nil).Once()
+	isgMock.On("IsProvisioningInProgress", ts.Exp.InstanceID).Return(internal.OperationID("exp-op-id"), true, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+
+	cgMock := &automock.ChartGetter{}
+	defer cgMock.AssertExpectations(t)
+
+	iiMock := &automock.InstanceStorage{}
+	ioMock := &automock.OperationStorage{}
+	defer ioMock.AssertExpectations(t)
+
+	hiMock := &automock.HelmClient{}
+	defer hiMock.AssertExpectations(t)
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewProvisionService(bgMock, cgMock, iiMock, isgMock, ioMock, ioMock, hiMock, oipFake, spy.NewLogDummy())
+
+	ctx := context.Background()
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixProvisionRequest()
+	req.AcceptsIncomplete = false
+
+	// WHEN
+	resp, err := svc.Provision(ctx, osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	assertHTTPStatusCode(t, http.StatusUnprocessableEntity, err)
+	assert.Equal(t, "AsyncRequired", *err.ErrorMessage)
+}
+
+func TestProvisionServiceProvisionFailureOnInvalidParameters(t *testing.T) {
+	// GIVEN
+	ts := newProvisionServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(false, nil).Once()
+	isgMock.On("IsProvisioningInProgress", ts.Exp.InstanceID).Return(internal.OperationID(""), false, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	expAddon := ts.Exp.NewAddonWithPlanSchemas()
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(expAddon, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	defer cgMock.AssertExpectations(t)
+
+	iiMock := &automock.InstanceStorage{}
+	defer iiMock.AssertExpectations(t)
+	iiMock.On("GetAll").Return(ts.FixInstanceCollection(), nil)
+
+	ioMock := &automock.OperationStorage{}
+	defer ioMock.AssertExpectations(t)
+
+	hiMock := &automock.HelmClient{}
+	defer hiMock.AssertExpectations(t)
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewProvisionService(bgMock, cgMock, iiMock, isgMock, ioMock, ioMock, hiMock, oipFake, spy.NewLogDummy())
+
+	ctx := context.Background()
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixProvisionRequest()
+	req.Parameters = map[string]interface{}{"replicas": "two"}
+
+	// WHEN
+	resp, err := svc.Provision(ctx, osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	assert.Contains(t, *err.ErrorMessage, "replicas: Invalid type. Expected: integer, given: string")
+}
@@ -2,11 +2,14 @@ package broker
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -26,13 +29,17 @@ import (
 
 type (
 	catalogGetter interface {
-		GetCatalog(ctx context.Context, osbCtx OsbContext) (*osb.CatalogResponse, error)
+		GetCatalog(ctx context.Context, osbCtx OsbContext) (*CatalogSuccessResponseDTO, error)
 	}
 
 	provisioner interface {
 		Provision(ctx context.Context, osbCtx OsbContext, req *osb.ProvisionRequest) (*osb.ProvisionResponse, *osb.HTTPStatusCodeError)
 	}
 
+	updater interface {
+		Update(ctx context.Context, osbCtx OsbContext, req *UpdateInstanceRequest) (*osb.UpdateInstanceResponse, *osb.HTTPStatusCodeError)
+	}
+
 	deprovisioner interface {
 		Deprovision(ctx context.Context, osbCtx OsbContext, req *osb.DeprovisionRequest) (*osb.DeprovisionResponse, error)
 	}
@@ -44,56 +51,92 @@ type (
 	}
 
 	unbinder interface {
-		Unbind(ctx context.Context, osbCtx OsbContext, req *osb.UnbindRequest) (*osb.UnbindResponse, error)
+		Unbind(ctx context.Context, osbCtx OsbContext, req *osb.UnbindRequest) (*osb.UnbindResponse, *osb.HTTPStatusCodeError)
 	}
 
 	lastOpGetter interface {
 		GetLastOperation(ctx context.Context, osbCtx OsbContext, req *osb.LastOperationRequest) (*osb.LastOperationResponse, error)
 	}
+
+	instanceFetcher interface {
+		GetInstance(ctx context.Context, osbCtx OsbContext, req *osb.GetInstanceRequest) (*osb.GetInstanceResponse, *osb.HTTPStatusCodeError)
+	}
+
+	instanceStatusGetter interface {
+		GetInstanceStatus(ctx context.Context, osbCtx OsbContext, iID internal.InstanceID) (*InstanceStatusSuccessResponseDTO, *osb.HTTPStatusCodeError)
+	}
+
+	instanceImporter interface {
+		Import(ctx context.Context, osbCtx OsbContext, iID internal.InstanceID, req *ImportInstanceRequestDTO) (*ImportInstanceSuccessResponseDTO, bool, *osb.HTTPStatusCodeError)
+	}
 )
 
 // Server implements HTTP server used to serve OSB API for helm broker.
 type Server struct {
-	catalogGetter catalogGetter
-	provisioner   provisioner
-	deprovisioner deprovisioner
-	binder        binder
-	unbinder      unbinder
-	lastOpGetter  lastOpGetter
-	logger        *logrus.Entry
-	addr          string
+	catalogGetter    catalogGetter
+	provisioner      provisioner
+	updater          updater
+	deprovisioner    deprovisioner
+	binder           binder
+	unbinder         unbinder
+	lastOpGetter     lastOpGetter
+	instFetcher      instanceFetcher
+	instStatusGetter instanceStatusGetter
+	instImporter     instanceImporter
+	authenticator    Authenticator
+	authorizer       *requestAuthorizer
+	adminAuthn       AdminAuthenticator
+	adminAuthz       Authorizer
+	logger           *logrus.Entry
+
+	// addrMu guards addr, which is set by the listening goroutine and read by Addr
+	addrMu sync.RWMutex
+	addr   string
+
+	operationQueue     *operationQueue
+	operationRecoverer *operationRecoverer
+	operationReaper    *operationReaper
+	driftReconciler    *driftReconciler
+}
+
+// WithAdminAuth exposes the admin API, e.g. the import of helm releases, for users authenticated by the admin authenticator
+// and allowed by the admin authorizer in the target namespace. The admin API is not exposed without them.
+func (srv *Server) WithAdminAuth(authn AdminAuthenticator, authz Authorizer) *Server {
+	srv.adminAuthn = authn
+	srv.adminAuthz = authz
+	return srv
 }
 
 // Addr returns address server is listening on.
 // Its use is targeted for cases when address is not known, e.g. tests.
 func (srv *Server) Addr() string {
-	if srv.addr == "" {
-		timer := time.NewTicker(time.Millisecond)
-	waitLoop:
-		for {
-			<-timer.C
-
-			if srv.addr != "" {
-				break waitLoop
-			}
+	if addr := srv.listenAddr(); addr != "" {
+		return addr
+	}
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for range ticker.C {
+		if addr := srv.listenAddr(); addr != "" {
+			return addr
 		}
 	}
+	return ""
+}
 
+func (srv *Server) listenAddr() string {
+	srv.addrMu.RLock()
+	defer srv.addrMu.RUnlock()
 	return srv.addr
 }
 
 // Run is starting HTTP server
 func (srv *Server) Run(ctx context.Context, addr string, startedCh chan struct{}) error {
 	listenAndServe := func(httpSrv *http.Server) error {
-		ln, err := net.Listen("tcp", addr)
+		ln, err := srv.listen(addr, startedCh)
 		if err != nil {
 			return err
 		}
-		close(startedCh)
-		lnTCP := ln.(*net.TCPListener)
-
-		srv.addr = lnTCP.Addr().String()
-
 		// TODO: add support for tcpKeepAliveListener
 		return httpSrv.Serve(ln)
 	}
@@ -101,9 +144,56 @@ func (srv *Server) Run(ctx context.Context, addr string, startedCh chan struct{}
 	return srv.run(ctx, addr, listenAndServe)
 }
 
-// RunTLS is starting TLS server
-func RunTLS(ctx context.Context, addr string, cert string, key string) error {
-	return errors.New("TLS is not yet implemented")
+// RunTLS is starting TLS server. The certificate is reloaded when the certificate files change.
+func (srv *Server) RunTLS(ctx context.Context, addr string, cfg TLSConfig, startedCh chan struct{}) error {
+	reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile, cfg.ReloadInterval, srv.logger.WithField("service", "cert-reloader"))
+	if err != nil {
+		return errors.Wrap(err, "while loading certificate")
+	}
+	tlsCfg, err := newServerTLSConfig(cfg, reloader)
+	if err != nil {
+		return errors.Wrap(err, "while creating TLS configuration")
+	}
+	go reloader.Run(ctx)
+
+	listenAndServe := func(httpSrv *http.Server) error {
+		ln, err := srv.listen(addr, startedCh)
+		if err != nil {
+			return err
+		}
+		return httpSrv.Serve(tls.NewListener(ln, tlsCfg))
+	}
+
+	return srv.run(ctx, addr, listenAndServe)
+}
+
+func (srv *Server) listen(addr string, startedCh chan struct{}) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	lnTCP := ln.(*net.TCPListener)
+
+	// the address is set before the start is signalled, so it is known to everyone who waits for it
+	srv.addrMu.Lock()
+	srv.addr = lnTCP.Addr().String()
+	srv.addrMu.Unlock()
+	close(startedCh)
+
+	return ln, nil
+}
+
+// ProcessOperations resumes or fails operations left in progress by the previous broker run,
+// processes asynchronous operations, settles stale ones and reconciles the drift of helm releases until the context is cancelled.
+// It is required only when the handler created by CreateHandler is served without the Run method.
+func (srv *Server) ProcessOperations(ctx context.Context) error {
+	if err := srv.operationRecoverer.Recover(ctx); err != nil {
+		return errors.Wrap(err, "while recovering operations in progress")
+	}
+	go srv.operationReaper.Run(ctx)
+	go srv.driftReconciler.Run(ctx)
+	srv.operationQueue.Run(ctx)
+	return nil
 }
 
 // TODO: rewrite to go-sdk implementation with app and services
@@ -112,6 +202,19 @@ func (srv *Server) run(ctx context.Context, addr string, listenAndServe func(srv
 		Addr:    addr,
 		Handler: srv.CreateHandler(),
 	}
+
+	// operations left in progress must be handled before new requests are accepted
+	if err := srv.operationRecoverer.Recover(ctx); err != nil {
+		return errors.Wrap(err, "while recovering operations in progress")
+	}
+	queueDrained := make(chan struct{})
+	go func() {
+		srv.operationQueue.Run(ctx)
+		close(queueDrained)
+	}()
+	go srv.operationReaper.Run(ctx)
+	go srv.driftReconciler.Run(ctx)
+
 	go func() {
 		<-ctx.Done()
 		c, cancel := context.WithTimeout(context.Background(), 1*time.Second)
@@ -120,8 +223,15 @@ func (srv *Server) run(ctx context.Context, addr string, listenAndServe func(srv
 			httpSrv.Close()
 		}
 	}()
-	return listenAndServe(httpSrv)
+	err := listenAndServe(httpSrv)
 
+	select {
+	case <-ctx.Done():
+		// operations which are already running are finished before the broker exits
+		<-queueDrained
+	default:
+	}
+	return err
 }
 
 // CreateHandler creates an http handler
@@ -141,6 +251,17 @@ func (srv *Server) CreateHandler() http.Handler {
 	}
 
 	n := negroni.New(negroni.NewRecovery(), logMiddleware)
+	if srv.authenticator != nil {
+		authMiddleware := NewAuthMiddleware(srv.authenticator, srv.logger.WithField("service", "auth-middleware"))
+		// the admin API is not called by the Platform, so it does not accept the OSB API credentials and has its own authentication
+		n.Use(negroni.HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			if isAdminRequest(r) {
+				next(rw, r)
+				return
+			}
+			authMiddleware.ServeHTTP(rw, r, next)
+		}))
+	}
 	n.UseHandler(rtr)
 	return n
 }
@@ -152,23 +273,36 @@ func (srv *Server) handleRouter(router *mux.Router) {
 	// sync operations
 	router.Path("/v2/catalog").Methods(http.MethodGet).
 		Handler(negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.catalogAction)))
+	router.Path("/v2/service_instances/{instance_id}").Methods(http.MethodGet).
+		Handler(negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.getServiceInstanceAction)))
 	router.Path("/v2/service_instances/{instance_id}/last_operation").Methods(http.MethodGet).
 		Handler(negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.getServiceInstanceLastOperationAction)))
+	router.Path("/v2/service_instances/{instance_id}/status").Methods(http.MethodGet).
+		Handler(negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.getServiceInstanceStatusAction)))
 	router.Path("/v2/service_instances/{instance_id}/service_bindings/{binding_id}").Methods(http.MethodGet).
 		Handler(negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.getServiceBinding)))
-	router.Path("/v2/service_instances/{instance_id}/service_bindings/{binding_id}").Methods(http.MethodDelete).
-		Handler(negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.unBindAction)))
 	router.Path("/v2/service_instances/{instance_id}/service_bindings/{binding_id}/last_operation").Methods(http.MethodGet).
 		Handler(negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.getServiceBindingLastOperationAction)))
 
-	// async operations
+	// async operations, the provisioning and deprovisioning can be synchronous if the plan allows for it
 	router.Path("/v2/service_instances/{instance_id}").Methods(http.MethodPut).Handler(
-		negroni.New(osbContextMiddleware, reqAsyncMiddleware, negroni.WrapFunc(srv.provisionAction)))
+		negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.provisionAction)))
+	router.Path("/v2/service_instances/{instance_id}").Methods(http.MethodPatch).Handler(
+		negroni.New(osbContextMiddleware, reqAsyncMiddleware, negroni.WrapFunc(srv.updateAction)))
 	router.Path("/v2/service_instances/{instance_id}").Methods(http.MethodDelete).Handler(
-		negroni.New(osbContextMiddleware, reqAsyncMiddleware, negroni.WrapFunc(srv.deprovisionAction)),
+		negroni.New(osbContextMiddleware, negroni.WrapFunc(srv.deprovisionAction)),
 	)
 	router.Path("/v2/service_instances/{instance_id}/service_bindings/{binding_id}").Methods(http.MethodPut).
 		Handler(negroni.New(osbContextMiddleware, reqAsyncMiddleware, negroni.WrapFunc(srv.bindAction)))
+	router.Path("/v2/service_instances/{instance_id}/service_bindings/{binding_id}").Methods(http.MethodDelete).
+		Handler(negroni.New(osbContextMiddleware, reqAsyncMiddleware, negroni.WrapFunc(srv.unBindAction)))
+
+	// admin operations, not defined by the Open Service Broker API
+	if srv.adminAuthn != nil && srv.adminAuthz != nil {
+		adminAuthMiddleware := NewAdminAuthMiddleware(srv.adminAuthn, srv.logger.WithField("service", "admin-auth-middleware"))
+		router.Path("/admin/service_instances/{instance_id}/import").Methods(http.MethodPut).
+			Handler(negroni.New(adminAuthMiddleware, osbContextMiddleware, negroni.WrapFunc(srv.importAction)))
+	}
 
 }
 
@@ -208,7 +342,7 @@ func (srv *Server) provisionAction(w http.ResponseWriter, r *http.Request) {
 	instanceID := mux.Vars(r)["instance_id"]
 
 	sReq := osb.ProvisionRequest{
-		AcceptsIncomplete: true,
+		AcceptsIncomplete: r.URL.Query().Get("accepts_incomplete") == "true",
 		InstanceID:        string(instanceID),
 		ServiceID:         string(inDTO.ServiceID),
 		PlanID:            string(inDTO.PlanID),
@@ -220,6 +354,11 @@ func (srv *Server) provisionAction(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	if err := srv.authorizer.authorizeNamespace(osbCtx, inDTO.Context.Namespace); err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
 	sResp, err := srv.provisioner.Provision(r.Context(), osbCtx, &sReq)
 	if err != nil {
 		var errMsg string
@@ -246,13 +385,100 @@ func (srv *Server) provisionAction(w http.ResponseWriter, r *http.Request) {
 
 	if !sResp.Async {
 		logResp(logRespFields)
-		srv.writeResponse(w, http.StatusOK, map[string]interface{}{})
+		// the operation key is returned only for the instance which was provisioned synchronously by this request
+		status := http.StatusOK
+		if sResp.OperationKey != nil {
+			status = http.StatusCreated
+		}
+		srv.writeResponse(w, status, ProvisionSuccessResponseDTO{DashboardURL: sResp.DashboardURL})
 		return
 	}
 
 	opID := internal.OperationID(*sResp.OperationKey)
 	egDTO := ProvisionSuccessResponseDTO{
-		Operation: &opID,
+		DashboardURL: sResp.DashboardURL,
+		Operation:    &opID,
+	}
+
+	logRespFields["resp:operation:id"] = opID
+	logResp(logRespFields)
+
+	srv.writeResponse(w, http.StatusAccepted, egDTO)
+}
+
+func (srv *Server) updateAction(w http.ResponseWriter, r *http.Request) {
+	osbCtx, _ := osbContextFromContext(r.Context())
+
+	var inDTO UpdateRequestDTO
+
+	if err := httpBodyToDTO(r, &inDTO); err != nil {
+		srv.writeErrorResponse(w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+
+	if err := inDTO.Validate(); err != nil {
+		srv.writeErrorResponse(w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+
+	instanceID := srv.sanitizeParameter(mux.Vars(r)["instance_id"])
+
+	sReq := UpdateInstanceRequest{
+		UpdateInstanceRequest: osb.UpdateInstanceRequest{
+			AcceptsIncomplete: true,
+			InstanceID:        instanceID,
+			ServiceID:         string(inDTO.ServiceID),
+			Parameters:        inDTO.Parameters,
+			Context: map[string]interface{}{
+				"namespace": string(inDTO.Context.Namespace),
+			},
+		},
+		MaintenanceInfo: inDTO.MaintenanceInfo,
+	}
+	if inDTO.PlanID != nil {
+		planID := string(*inDTO.PlanID)
+		sReq.PlanID = &planID
+	}
+
+	if err := srv.authorizer.authorizeInstance(osbCtx, internal.InstanceID(instanceID)); err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
+	sResp, err := srv.updater.Update(r.Context(), osbCtx, &sReq)
+	if err != nil {
+		var errMsg string
+		var errDesc string
+		if err.ErrorMessage != nil {
+			errMsg = *err.ErrorMessage
+		}
+		if err.Description != nil {
+			errDesc = *err.Description
+		}
+		srv.writeErrorResponse(w, err.StatusCode, errMsg, errDesc)
+		return
+	}
+
+	logRespFields := logrus.Fields{
+		"action":     "update",
+		"resp:async": sResp.Async,
+	}
+	logResp := func(fields logrus.Fields) {
+		if srv.logger != nil {
+			srv.logger.WithFields(fields).Info("action response")
+		}
+	}
+
+	if !sResp.Async {
+		logResp(logRespFields)
+		srv.writeResponse(w, http.StatusOK, map[string]interface{}{})
+		return
+	}
+
+	opID := internal.OperationID(*sResp.OperationKey)
+	egDTO := UpdateSuccessResponseDTO{
+		DashboardURL: sResp.DashboardURL,
+		Operation:    &opID,
 	}
 
 	logRespFields["resp:operation:id"] = opID
@@ -278,17 +504,26 @@ func (srv *Server) deprovisionAction(w http.ResponseWriter, r *http.Request) {
 	svcIDRaw := srv.sanitizeParameter(q.Get("service_id"))
 	planIDRaw := srv.sanitizeParameter(q.Get("plan_id"))
 	sReq := osb.DeprovisionRequest{
-		AcceptsIncomplete: true,
+		AcceptsIncomplete: q.Get("accepts_incomplete") == "true",
 		InstanceID:        instanceID,
 		ServiceID:         svcIDRaw,
 		PlanID:            planIDRaw,
 	}
 
+	if err := srv.authorizer.authorizeInstance(osbCtx, internal.InstanceID(instanceID)); err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
 	sResp, err := srv.deprovisioner.Deprovision(r.Context(), osbCtx, &sReq)
+	var httpErr *osb.HTTPStatusCodeError
 	switch {
 	case IsNotFoundError(err):
 		srv.writeResponse(w, http.StatusGone, map[string]interface{}{})
 		return
+	case errors.As(err, &httpErr):
+		srv.writeHTTPStatusCodeError(w, httpErr)
+		return
 	case err != nil:
 		srv.writeErrorResponse(w, http.StatusBadRequest, err.Error(), "")
 		return
@@ -306,7 +541,12 @@ func (srv *Server) deprovisionAction(w http.ResponseWriter, r *http.Request) {
 
 	if !sResp.Async {
 		logResp(logRespFields)
-		srv.writeResponse(w, http.StatusGone, map[string]interface{}{})
+		// the operation key is returned only for the instance which was deprovisioned synchronously by this request
+		status := http.StatusGone
+		if sResp.OperationKey != nil {
+			status = http.StatusOK
+		}
+		srv.writeResponse(w, status, map[string]interface{}{})
 		return
 	}
 
@@ -321,6 +561,127 @@ func (srv *Server) deprovisionAction(w http.ResponseWriter, r *http.Request) {
 	srv.writeResponse(w, http.StatusAccepted, egDTO)
 }
 
+func (srv *Server) importAction(w http.ResponseWriter, r *http.Request) {
+	osbCtx, _ := osbContextFromContext(r.Context())
+
+	var inDTO ImportInstanceRequestDTO
+	if err := httpBodyToDTO(r, &inDTO); err != nil {
+		srv.writeErrorResponse(w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+	if err := inDTO.Validate(); err != nil {
+		srv.writeErrorResponse(w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+
+	instanceID := internal.InstanceID(srv.sanitizeParameter(mux.Vars(r)["instance_id"]))
+
+	// the broker of the namespace manages only releases from its own namespace
+	if osbCtx.BrokerNamespace != internal.ClusterWide && inDTO.Namespace != osbCtx.BrokerNamespace {
+		srv.writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("the broker of the namespace %q cannot import the release from the namespace %q", osbCtx.BrokerNamespace, inDTO.Namespace), "")
+		return
+	}
+
+	admin, _ := adminUserFromContext(r.Context())
+	if err := authorizeAdmin(srv.adminAuthz, admin, inDTO.Namespace); err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
+	resp, created, err := srv.instImporter.Import(r.Context(), osbCtx, instanceID, &inDTO)
+	if err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
+	if srv.logger != nil {
+		srv.logger.WithFields(logrus.Fields{
+			"action":       "import",
+			"instance:id":  instanceID,
+			"release:name": resp.ReleaseName,
+			"resp:created": created,
+		}).Info("action response")
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	srv.writeResponse(w, status, resp)
+}
+
+func (srv *Server) getServiceInstanceAction(w http.ResponseWriter, r *http.Request) {
+	osbCtx, _ := osbContextFromContext(r.Context())
+
+	instanceID := srv.sanitizeParameter(mux.Vars(r)["instance_id"])
+
+	if err := srv.authorizer.authorizeInstance(osbCtx, internal.InstanceID(instanceID)); err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
+	sReq := osb.GetInstanceRequest{
+		InstanceID: instanceID,
+	}
+
+	sResp, err := srv.instFetcher.GetInstance(r.Context(), osbCtx, &sReq)
+	if err != nil {
+		var errMsg string
+		var errDesc string
+		if err.ErrorMessage != nil {
+			errMsg = *err.ErrorMessage
+		}
+		if err.Description != nil {
+			errDesc = *err.Description
+		}
+		srv.writeErrorResponse(w, err.StatusCode, errMsg, errDesc)
+		return
+	}
+
+	if srv.logger != nil {
+		srv.logger.WithFields(logrus.Fields{
+			"action":      "getServiceInstance",
+			"instance:id": instanceID,
+		}).Info("action response")
+	}
+
+	egDTO := GetInstanceSuccessResponseDTO{
+		ServiceID:  internal.ServiceID(sResp.ServiceID),
+		PlanID:     internal.ServicePlanID(sResp.PlanID),
+		Parameters: sResp.Parameters,
+	}
+	if sResp.DashboardURL != "" {
+		egDTO.DashboardURL = &sResp.DashboardURL
+	}
+	srv.writeResponse(w, http.StatusOK, egDTO)
+}
+
+func (srv *Server) getServiceInstanceStatusAction(w http.ResponseWriter, r *http.Request) {
+	osbCtx, _ := osbContextFromContext(r.Context())
+
+	instanceID := internal.InstanceID(srv.sanitizeParameter(mux.Vars(r)["instance_id"]))
+
+	if err := srv.authorizer.authorizeInstance(osbCtx, instanceID); err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
+	resp, err := srv.instStatusGetter.GetInstanceStatus(r.Context(), osbCtx, instanceID)
+	if err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
+	if srv.logger != nil {
+		srv.logger.WithFields(logrus.Fields{
+			"action":       "getServiceInstanceStatus",
+			"instance:id":  instanceID,
+			"resp:healthy": resp.Healthy,
+		}).Info("action response")
+	}
+	srv.writeResponse(w, http.StatusOK, resp)
+}
+
 func (srv *Server) getServiceInstanceLastOperationAction(w http.ResponseWriter, r *http.Request) {
 	osbCtx, _ := osbContextFromContext(r.Context())
 
@@ -410,6 +771,11 @@ func (srv *Server) bindAction(w http.ResponseWriter, r *http.Request) {
 			"namespace": string(params.Context.Namespace),
 		},
 	}
+	if err := srv.authorizer.authorizeInstance(osbCtx, internal.InstanceID(instanceID)); err != nil {
+		srv.writeHTTPStatusCodeError(w, err)
+		return
+	}
+
 	sResp, sErr := srv.binder.Bind(r.Context(), osbCtx, &sReq)
 	if sErr != nil {
 		var errMsg string
@@ -452,7 +818,61 @@ func (srv *Server) bindAction(w http.ResponseWriter, r *http.Request) {
 }
 
 func (srv *Server) unBindAction(w http.ResponseWriter, r *http.Request) {
-	srv.writeResponse(w, http.StatusGone, map[string]interface{}{})
+	osbCtx, _ := osbContextFromContext(r.Context())
+
+	instanceID := srv.sanitizeParameter(mux.Vars(r)["instance_id"])
+	bindingID := srv.sanitizeParameter(mux.Vars(r)["binding_id"])
+
+	q := r.URL.Query()
+
+	sReq := osb.UnbindRequest{
+		AcceptsIncomplete: true,
+		InstanceID:        instanceID,
+		BindingID:         bindingID,
+		ServiceID:         srv.sanitizeParameter(q.Get("service_id")),
+		PlanID:            srv.sanitizeParameter(q.Get("plan_id")),
+	}
+
+	sResp, sErr := srv.unbinder.Unbind(r.Context(), osbCtx, &sReq)
+	switch {
+	case sErr != nil && sErr.StatusCode == http.StatusGone:
+		srv.writeResponse(w, http.StatusGone, map[string]interface{}{})
+		return
+	case sErr != nil:
+		var errMsg string
+		var errDesc string
+		if sErr.ErrorMessage != nil {
+			errMsg = *sErr.ErrorMessage
+		}
+		if sErr.Description != nil {
+			errDesc = *sErr.Description
+		}
+		srv.writeErrorResponse(w, sErr.StatusCode, errMsg, errDesc)
+		return
+	}
+
+	logRespFields := logrus.Fields{
+		"action":      "unbind",
+		"instance:id": instanceID,
+		"binding:id":  bindingID,
+		"resp:async":  sResp.Async,
+	}
+
+	if !sResp.Async {
+		if srv.logger != nil {
+			srv.logger.WithFields(logRespFields).Info("action response")
+		}
+		srv.writeResponse(w, http.StatusOK, map[string]interface{}{})
+		return
+	}
+
+	opID := internal.OperationID(*sResp.OperationKey)
+	logRespFields["resp:operation:id"] = opID
+	if srv.logger != nil {
+		srv.logger.WithFields(logRespFields).Info("action response")
+	}
+
+	srv.writeResponse(w, http.StatusAccepted, UnbindSuccessResponseDTO{Operation: &opID})
 }
 
 func (srv *Server) getServiceBinding(w http.ResponseWriter, r *http.Request) {
@@ -581,6 +1001,18 @@ func (srv *Server) writeErrorResponse(w http.ResponseWriter, code int, errorMsg,
 	writeErrorResponse(w, code, errorMsg, desc)
 }
 
+func (srv *Server) writeHTTPStatusCodeError(w http.ResponseWriter, err *osb.HTTPStatusCodeError) {
+	var errMsg string
+	var errDesc string
+	if err.ErrorMessage != nil {
+		errMsg = *err.ErrorMessage
+	}
+	if err.Description != nil {
+		errDesc = *err.Description
+	}
+	srv.writeErrorResponse(w, err.StatusCode, errMsg, errDesc)
+}
+
 // writeErrorResponse writes error response compatible with OpenServiceBroker API specification.
 func writeErrorResponse(w http.ResponseWriter, code int, errorMsg, desc string) {
 	dto := struct {
@@ -0,0 +1,38 @@
+package broker
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+// operationResult holds the state in which the operation processed by the operation queue was finished
+type operationResult struct {
+	state       internal.OperationState
+	description string
+}
+
+// waitForOperation waits for the result of the operation. False is returned when the operation is not finished
+// within the timeout or the request is cancelled, the operation is continued asynchronously then.
+func waitForOperation(ctx context.Context, result <-chan operationResult, timeout time.Duration) (operationResult, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-result:
+		return res, true
+	case <-timer.C:
+		return operationResult{}, false
+	case <-ctx.Done():
+		return operationResult{}, false
+	}
+}
+
+func asyncRequiredError() *osb.HTTPStatusCodeError {
+	// message and desc as defined in https://github.com/openservicebrokerapi/servicebroker/blob/master/spec.md#response-2
+	return &osb.HTTPStatusCodeError{StatusCode: http.StatusUnprocessableEntity, ErrorMessage: strPtr("AsyncRequired"), Description: strPtr("This service plan requires client support for asynchronous service operations.")}
+}
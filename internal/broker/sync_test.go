@@ -0,0 +1,181 @@
+package broker_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+)
+
+func TestOSBAPIProvisionSynchronous(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{
+		Info:    &release.Info{},
+		Version: 1,
+	}, nil).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+	ts.upsertAddonWithSynchronousPlan(true)
+
+	// WHEN
+	resp, err := ts.OSBClient().ProvisionInstance(ts.fixSynchronousProvisionRequest())
+
+	// THEN
+	require.NoError(t, err)
+	assert.False(t, resp.Async)
+	assert.Nil(t, resp.OperationKey)
+
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+	instance, err := ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, instance.ReleaseInfo.Revision, "the response must be sent after the release is installed")
+}
+
+func TestOSBAPIProvisionSynchronousFallbackToAsyncOnTimeout(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuiteWithConfig(t, "2.14", nil, broker.Config{
+		OperationQueue:              broker.OperationQueueConfig{GlobalLimit: 10, NamespaceLimit: 10},
+		SynchronousOperationTimeout: 10 * time.Millisecond,
+	})
+	installReleased := make(chan struct{})
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{
+		Info: &release.Info{},
+	}, nil).Run(func(mock.Arguments) { <-installReleased }).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+	ts.upsertAddonWithSynchronousPlan(true)
+
+	// WHEN
+	_, err := ts.OSBClient().ProvisionInstance(ts.fixSynchronousProvisionRequest())
+
+	// THEN
+	assertHTTPStatusCode(t, http.StatusAccepted, err)
+
+	close(installReleased)
+	ts.AssertOperationState(internal.OperationStateSucceeded)
+}
+
+func TestOSBAPIProvisionSynchronousFailure(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+	ts.HelmClient.On("Install", mock.Anything, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(nil, errors.New("image pull backoff")).Once()
+	defer ts.HelmClient.AssertExpectations(t)
+
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+	ts.upsertAddonWithSynchronousPlan(true)
+
+	// WHEN
+	_, err := ts.OSBClient().ProvisionInstance(ts.fixSynchronousProvisionRequest())
+
+	// THEN
+	assertHTTPStatusCode(t, http.StatusInternalServerError, err)
+	ts.AssertOperationState(internal.OperationStateFailed)
+}
+
+func TestOSBAPIProvisionAsyncRequired(t *testing.T) {
+	// GIVEN
+	ts := newOSBAPITestSuite(t, "2.14")
+	ts.ServerRun()
+	defer ts.ServerShutdown()
+	ts.upsertAddonWithSynchronousPlan(false)
+
+	// WHEN
+	_, err := ts.OSBClient().ProvisionInstance(ts.fixSynchronousProvisionRequest())
+
+	// THEN
+	assertHTTPStatusCode(t, http.StatusUnprocessableEntity, err)
+	assert.True(t, osb.IsAsyncRequiredError(err))
+	_, err = ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+	assert.True(t, broker.IsNotFoundError(err), "instance must not be created")
+}
+
+func TestOSBAPIDeprovisionSynchronous(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		synchronousPlan bool
+		expStatusCode   int
+	}{
+		"plan allows for synchronous operations": {
+			synchronousPlan: true,
+			expStatusCode:   http.StatusOK,
+		},
+		"plan requires asynchronous operations": {
+			synchronousPlan: false,
+			expStatusCode:   http.StatusUnprocessableEntity,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			ts := newOSBAPITestSuite(t, "2.14")
+			ts.StorageFactory.Instance().Insert(ts.Exp.NewInstance())
+			fixOperation := ts.Exp.NewInstanceOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+			fixOperation.OperationID = "fix-op-id"
+			ts.StorageFactory.InstanceOperation().Insert(fixOperation)
+			if tc.synchronousPlan {
+				ts.HelmClient.On("Delete", ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(nil).Once()
+			}
+			defer ts.HelmClient.AssertExpectations(t)
+
+			ts.ServerRun()
+			defer ts.ServerShutdown()
+			ts.upsertAddonWithSynchronousPlan(tc.synchronousPlan)
+
+			// WHEN
+			resp, err := ts.OSBClient().DeprovisionInstance(&osb.DeprovisionRequest{
+				AcceptsIncomplete:   false,
+				InstanceID:          string(ts.Exp.InstanceID),
+				ServiceID:           string(ts.Exp.Service.ID),
+				PlanID:              string(ts.Exp.ServicePlan.ID),
+				OriginatingIdentity: &osb.OriginatingIdentity{Platform: osb.PlatformKubernetes, Value: "{}"},
+			})
+
+			// THEN
+			if tc.expStatusCode != http.StatusOK {
+				assertHTTPStatusCode(t, tc.expStatusCode, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.False(t, resp.Async)
+			_, err = ts.StorageFactory.Instance().Get(ts.Exp.InstanceID)
+			assert.True(t, broker.IsNotFoundError(err), "instance must be removed before the response is sent")
+		})
+	}
+}
+
+func (ts *osbapiTestSuite) upsertAddonWithSynchronousPlan(synchronous bool) {
+	addon := ts.Exp.NewAddon()
+	plan := addon.Plans[ts.Exp.AddonPlan.ID]
+	plan.SynchronousOperations = synchronous
+	addon.Plans[ts.Exp.AddonPlan.ID] = plan
+
+	_, err := ts.StorageFactory.Addon().Upsert(internal.ClusterWide, addon)
+	require.NoError(ts.t, err)
+	_, err = ts.StorageFactory.Chart().Upsert(internal.ClusterWide, ts.Exp.NewChart())
+	require.NoError(ts.t, err)
+}
+
+func (ts *osbapiTestSuite) fixSynchronousProvisionRequest() *osb.ProvisionRequest {
+	return &osb.ProvisionRequest{
+		AcceptsIncomplete: false,
+		InstanceID:        string(ts.Exp.InstanceID),
+		ServiceID:         string(ts.Exp.Service.ID),
+		PlanID:            string(ts.Exp.ServicePlan.ID),
+		Context:           map[string]interface{}{"namespace": string(ts.Exp.Namespace)},
+		OrganizationGUID:  "org",
+		SpaceGUID:         "space",
+	}
+}
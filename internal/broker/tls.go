@@ -0,0 +1,157 @@
+package broker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// TLSConfig holds configuration of serving the OSB API over TLS
+type TLSConfig struct {
+	// CertFile defines the path to the PEM encoded server certificate. The TLS is disabled when it is not set.
+	CertFile string
+	// KeyFile defines the path to the PEM encoded server private key
+	KeyFile string
+	// ClientCAFile defines the path to the PEM encoded CA bundle. When it is set, clients must present a certificate signed by one of the CAs.
+	ClientCAFile string
+	// ReloadInterval defines how often the certificate files are checked for changes
+	ReloadInterval time.Duration `default:"30s"`
+}
+
+// Enabled returns true if the OSB API should be served over TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// certReloader holds the server certificate and reloads it when the certificate or key file is changed,
+// e.g. rotated by the cert-manager
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	certStat fileStat
+	keyStat  fileStat
+
+	log logrus.FieldLogger
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration, log logrus.FieldLogger) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		log:      log,
+	}
+	if _, err := r.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, it implements the tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Run checks the certificate files every configured interval until given context is cancelled.
+// The previous certificate is served when the changed files cannot be loaded.
+func (r *certReloader) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		r.log.Info("Reload interval is not set, certificate is not reloaded")
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reloadIfChanged()
+			switch {
+			case err != nil:
+				r.log.Errorf("Cannot reload certificate: %v", err)
+			case reloaded:
+				r.log.Infof("Certificate reloaded from %s", r.certFile)
+			}
+		}
+	}
+}
+
+func (r *certReloader) reloadIfChanged() (bool, error) {
+	certStat, err := statFile(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyStat, err := statFile(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	changed := r.cert == nil || certStat != r.certStat || keyStat != r.keyStat
+	r.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "while loading certificate and key")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.certStat = certStat
+	r.keyStat = keyStat
+	return true, nil
+}
+
+func statFile(path string) (fileStat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}, errors.Wrapf(err, "while checking file %s", path)
+	}
+	return fileStat{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+func newServerTLSConfig(cfg TLSConfig, reloader *certReloader) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if cfg.ClientCAFile == "" {
+		return tlsCfg, nil
+	}
+
+	caBundle, err := ioutil.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "while reading client CA bundle")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return nil, errors.Errorf("client CA bundle %s does not contain any PEM encoded certificate", cfg.ClientCAFile)
+	}
+	tlsCfg.ClientCAs = pool
+	tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsCfg, nil
+}
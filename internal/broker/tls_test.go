@@ -0,0 +1,146 @@
+package broker_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/helm-broker/internal/broker"
+)
+
+func TestServerRunTLSReloadsCertificate(t *testing.T) {
+	// GIVEN
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	firstCert, firstKey := fixCertificate(t, "first")
+	writeCertificate(t, certFile, keyFile, firstCert, firstKey)
+
+	ts := newOSBAPITestSuite(t, "2.14")
+	addr := runTLSServer(t, ts, broker.TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: 10 * time.Millisecond})
+	defer ts.ServerShutdown()
+
+	// WHEN
+	assert.Equal(t, "first", servedCertificateCN(t, addr))
+
+	secondCert, secondKey := fixCertificate(t, "second-rotated")
+	writeCertificate(t, certFile, keyFile, secondCert, secondKey)
+
+	// THEN
+	assert.Eventually(t, func() bool {
+		return servedCertificateCN(t, addr) == "second-rotated"
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestServerRunTLSRequiresClientCertificate(t *testing.T) {
+	// GIVEN
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, clientCAFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	serverCert, serverKey := fixCertificate(t, "server")
+	writeCertificate(t, certFile, keyFile, serverCert, serverKey)
+	clientCert, clientKey := fixCertificate(t, "client")
+	require.NoError(t, ioutil.WriteFile(clientCAFile, clientCert, 0600))
+
+	ts := newOSBAPITestSuite(t, "2.14")
+	addr := runTLSServer(t, ts, broker.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: clientCAFile})
+	defer ts.ServerShutdown()
+
+	clientPair, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+
+	// WHEN
+	_, errWithoutCert := tlsClient(nil).Get(fmt.Sprintf("https://%s/cluster/v2/catalog", addr))
+	respWithCert, errWithCert := tlsClient(&clientPair).Get(fmt.Sprintf("https://%s/cluster/v2/catalog", addr))
+
+	// THEN
+	assert.Error(t, errWithoutCert)
+	require.NoError(t, errWithCert)
+	defer respWithCert.Body.Close()
+	assert.Equal(t, http.StatusPreconditionFailed, respWithCert.StatusCode, "request without the API version header must reach the broker")
+}
+
+func runTLSServer(t *testing.T, ts *osbapiTestSuite, cfg broker.TLSConfig) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	ts.serverWg.Add(1)
+	startedCh := make(chan struct{})
+
+	go func() {
+		assert.Equal(t, http.ErrServerClosed, ts.BrokerServer.RunTLS(ctx, "127.0.0.1:0", cfg, startedCh))
+		ts.serverWg.Done()
+	}()
+
+	select {
+	case <-startedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("TLS server was not started")
+	}
+	ts.serverCancel = cancel
+	return ts.BrokerServer.Addr()
+}
+
+func servedCertificateCN(t *testing.T, addr string) string {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func tlsClient(clientCert *tls.Certificate) *http.Client {
+	cfg := &tls.Config{InsecureSkipVerify: true}
+	if clientCert != nil {
+		cfg.Certificates = []tls.Certificate{*clientCert}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+}
+
+func writeCertificate(t *testing.T, certFile, keyFile string, cert, key []byte) {
+	require.NoError(t, ioutil.WriteFile(keyFile, key, 0600))
+	require.NoError(t, ioutil.WriteFile(certFile, cert, 0600))
+}
+
+// fixCertificate returns the self-signed certificate which can be used by both the server and the client
+func fixCertificate(t *testing.T, commonName string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
@@ -2,12 +2,154 @@ package broker
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
 	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/helm-broker/internal"
 )
 
-type unbindService struct{}
+type unbindService struct {
+	bindStateGetter         bindStateUnbindingGetter
+	bindOperationStorage    bindOperationStorage
+	instanceBindDataRemover instanceBindDataRemover
+	operationIDProvider     func() (internal.OperationID, error)
+	instanceLocker          *instanceLocker
+	operationQueue          *operationQueue
+
+	log logrus.FieldLogger
+
+	testHookAsyncCalled func(internal.OperationID)
+}
+
+func (svc *unbindService) Unbind(ctx context.Context, osbCtx OsbContext, req *osb.UnbindRequest) (*osb.UnbindResponse, *osb.HTTPStatusCodeError) {
+	if !req.AcceptsIncomplete {
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr("asynchronous operation mode required")}
+	}
+
+	iID := internal.InstanceID(req.InstanceID)
+	bID := internal.BindingID(req.BindingID)
+
+	if iID.IsZero() || bID.IsZero() {
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("instance id and binding id must be set. InstanceID: %q | BindingID: %q", iID, bID))}
+	}
+
+	svc.instanceLocker.Lock(iID)
+	defer svc.instanceLocker.Unlock(iID)
+
+	switch opIDInProgress, inProgress, err := svc.bindStateGetter.IsUnbindingInProgress(iID, bID); true {
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if service binding is being removed: %v", err))}
+	case inProgress:
+		opKeyInProgress := osb.OperationKey(opIDInProgress)
+		return &osb.UnbindResponse{Async: true, OperationKey: &opKeyInProgress}, nil
+	}
+
+	// the binding which is being created is not bound yet, but it exists, so the request is rejected as the concurrent one
+	switch _, inProgress, err := svc.bindStateGetter.IsBindingInProgress(iID, bID); true {
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if service binding is being created: %v", err))}
+	case inProgress:
+		// message as defined in https://github.com/openservicebrokerapi/servicebroker/blob/v2.14/spec.md#broker-errors
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusUnprocessableEntity, ErrorMessage: strPtr("ConcurrencyError"), Description: strPtr("The service binding is being created.")}
+	}
+
+	switch _, bound, err := svc.bindStateGetter.IsBound(iID, bID); true {
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if service binding exists: %v", err))}
+	case !bound:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusGone, ErrorMessage: strPtr(fmt.Sprintf("service binding %q does not exist", bID))}
+	}
+
+	opID, err := svc.operationIDProvider()
+	if err != nil {
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while generating operation ID: %v", err))}
+	}
+
+	op := internal.BindOperation{
+		InstanceID:  iID,
+		BindingID:   bID,
+		OperationID: opID,
+		Type:        internal.OperationTypeRemove,
+		State:       internal.OperationStateInProgress,
+		RequestedBy: osbCtx.requester(),
+	}
+
+	err = svc.bindOperationStorage.Insert(&op)
+	switch {
+	case IsActiveOperationInProgressError(err):
+		// message as defined in https://github.com/openservicebrokerapi/servicebroker/blob/v2.14/spec.md#broker-errors
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusUnprocessableEntity, ErrorMessage: strPtr("ConcurrencyError"), Description: strPtr("Another operation for this service binding is in progress.")}
+	case err != nil:
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while inserting bind operation to storage: %v", err))}
+	}
+
+	svc.doAsync(ctx, iID, bID, opID)
+
+	opKey := osb.OperationKey(opID)
+	resp := &osb.UnbindResponse{
+		OperationKey: &opKey,
+		Async:        true,
+	}
+
+	return resp, nil
+}
+
+func (svc *unbindService) doAsync(ctx context.Context, iID internal.InstanceID, bID internal.BindingID, opID internal.OperationID) {
+	if svc.testHookAsyncCalled != nil {
+		svc.testHookAsyncCalled(opID)
+	}
+	// unbinding touches only the broker storage, so it is not assigned to any namespace
+	svc.operationQueue.Enqueue(asyncOperation{
+		operationID: opID,
+		do:          func(ctx context.Context) { svc.do(ctx, iID, bID, opID) },
+	})
+}
+
+// do is called asynchronously
+func (svc *unbindService) do(ctx context.Context, iID internal.InstanceID, bID internal.BindingID, opID internal.OperationID) {
+	fDo := func() error {
+		err := svc.instanceBindDataRemover.Remove(iID, bID)
+		switch {
+		// bind data is not stored for the binding of the plan without the bind template, so NotFound error is also in happy path
+		case err == nil, IsNotFoundError(err):
+		default:
+			return errors.Wrap(err, "while removing instance bind data from storage")
+		}
+
+		ops, err := svc.bindOperationStorage.GetAll(iID)
+		switch {
+		case err == nil, IsNotFoundError(err):
+		default:
+			return errors.Wrap(err, "while getting bind operations from storage")
+		}
+
+		for _, op := range ops {
+			if op.BindingID != bID || op.OperationID == opID {
+				continue
+			}
+			if err := svc.bindOperationStorage.Remove(iID, bID, op.OperationID); err != nil && !IsNotFoundError(err) {
+				return errors.Wrapf(err, "while removing bind operation %q from storage", op.OperationID)
+			}
+		}
+
+		return nil
+	}
+
+	if err := fDo(); err != nil {
+		opDesc := fmt.Sprintf("unbinding failed on error: %s", err.Error())
+		if err := svc.bindOperationStorage.UpdateStateDesc(iID, bID, opID, internal.OperationStateFailed, &opDesc); err != nil {
+			svc.log.Errorf("State description was not updated, got error: %v", err)
+		}
+		return
+	}
 
-func (svc *unbindService) Unbind(ctx context.Context, osbCtx OsbContext, req *osb.UnbindRequest) (*osb.UnbindResponse, error) {
-	return nil, nil
+	// The binding is gone together with its last operation, so the OSB last_operation endpoint responds
+	// with 410 Gone which the Platform must treat as a successful unbinding.
+	if err := svc.bindOperationStorage.Remove(iID, bID, opID); err != nil {
+		svc.log.Errorf("Cannot remove unbind operation [%s] for binding [%s]: [%v]", opID, bID, err)
+	}
 }
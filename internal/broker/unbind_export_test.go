@@ -0,0 +1,24 @@
+package broker
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+func NewUnbindService(bsg bindStateGetter, bos bindOperationStorage, ibdr instanceBindDataRemover, oIDProv func() (internal.OperationID, error), log *logrus.Entry) *unbindService {
+	return &unbindService{
+		bindStateGetter:         bsg,
+		bindOperationStorage:    bos,
+		instanceBindDataRemover: ibdr,
+		operationIDProvider:     oIDProv,
+		log:                     log,
+		instanceLocker:          newInstanceLocker(),
+		operationQueue:          newStartedOperationQueue(),
+	}
+}
+
+func (svc *unbindService) WithTestHookOnAsyncCalled(h func(internal.OperationID)) *unbindService {
+	svc.testHookAsyncCalled = h
+	return svc
+}
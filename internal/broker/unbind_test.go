@@ -0,0 +1,311 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/broker/automock"
+	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
+)
+
+func newUnbindServiceTestSuite(t *testing.T) *unbindServiceTestSuite {
+	return &unbindServiceTestSuite{t: t}
+}
+
+type unbindServiceTestSuite struct {
+	t   *testing.T
+	Exp expAll
+}
+
+func (ts *unbindServiceTestSuite) SetUp() {
+	ts.Exp.Populate()
+}
+
+func (ts *unbindServiceTestSuite) FixUnbindRequest() osb.UnbindRequest {
+	return osb.UnbindRequest{
+		InstanceID:        string(ts.Exp.InstanceID),
+		BindingID:         string(ts.Exp.BindingID),
+		ServiceID:         string(ts.Exp.Service.ID),
+		PlanID:            string(ts.Exp.ServicePlan.ID),
+		AcceptsIncomplete: true,
+	}
+}
+
+func (ts *unbindServiceTestSuite) FixCreateBindOperation() *internal.BindOperation {
+	op := ts.Exp.NewBindOperation(internal.OperationTypeCreate, internal.OperationStateSucceeded)
+	op.OperationID = "fix-create-op-id"
+	return op
+}
+
+func TestUnbindServiceUnbindSuccessAsync(t *testing.T) {
+	// GIVEN
+	ts := newUnbindServiceTestSuite(t)
+	ts.SetUp()
+
+	createOp := ts.FixCreateBindOperation()
+	otherBindingOp := &internal.BindOperation{
+		InstanceID:  ts.Exp.InstanceID,
+		BindingID:   "other-binding-id",
+		OperationID: "other-op-id",
+		Type:        internal.OperationTypeCreate,
+		State:       internal.OperationStateSucceeded,
+	}
+
+	bsgMock := &automock.BindStateGetter{}
+	defer bsgMock.AssertExpectations(t)
+	bsgMock.On("IsUnbindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(*createOp, true, nil).Once()
+
+	ibdrMock := &automock.InstanceBindDataRemover{}
+	defer ibdrMock.AssertExpectations(t)
+	ibdrMock.On("Remove", ts.Exp.InstanceID, ts.Exp.BindingID).Return(nil).Once()
+
+	bosMock := &automock.BindOperationStorage{}
+	defer bosMock.AssertExpectations(t)
+	expRemoveOp := ts.Exp.NewBindOperation(internal.OperationTypeRemove, internal.OperationStateInProgress)
+	bosMock.On("Insert", expRemoveOp).Return(nil).Once()
+	bosMock.On("GetAll", ts.Exp.InstanceID).Return([]*internal.BindOperation{createOp, otherBindingOp, expRemoveOp}, nil).Once()
+	bosMock.On("Remove", ts.Exp.InstanceID, ts.Exp.BindingID, createOp.OperationID).Return(nil).Once()
+	operationRemoved := make(chan struct{})
+	bosMock.On("Remove", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID).Return(nil).Once().
+		Run(func(mock.Arguments) { close(operationRemoved) })
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
+	}
+
+	testHookCalled := make(chan struct{})
+
+	svc := broker.NewUnbindService(bsgMock, bosMock, ibdrMock, oipFake, spy.NewLogDummy()).
+		WithTestHookOnAsyncCalled(func(opID internal.OperationID) {
+			assert.Equal(t, ts.Exp.OperationID, opID)
+			close(testHookCalled)
+		})
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUnbindRequest()
+
+	// WHEN
+	resp, err := svc.Unbind(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.Async)
+	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
+
+	select {
+	case <-operationRemoved:
+	case <-time.After(time.Millisecond * 100):
+		t.Fatal("timeout on unbind operation removal")
+	}
+
+	select {
+	case <-testHookCalled:
+	default:
+		t.Fatal("async test hook not called")
+	}
+}
+
+func TestUnbindServiceUnbindFailureAsyncOnBindDataRemove(t *testing.T) {
+	// GIVEN
+	ts := newUnbindServiceTestSuite(t)
+	ts.SetUp()
+
+	createOp := ts.FixCreateBindOperation()
+
+	bsgMock := &automock.BindStateGetter{}
+	defer bsgMock.AssertExpectations(t)
+	bsgMock.On("IsUnbindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(*createOp, true, nil).Once()
+
+	ibdrMock := &automock.InstanceBindDataRemover{}
+	defer ibdrMock.AssertExpectations(t)
+	fixErr := errors.New("fake storage error")
+	ibdrMock.On("Remove", ts.Exp.InstanceID, ts.Exp.BindingID).Return(fixErr).Once()
+
+	bosMock := &automock.BindOperationStorage{}
+	defer bosMock.AssertExpectations(t)
+	expRemoveOp := ts.Exp.NewBindOperation(internal.OperationTypeRemove, internal.OperationStateInProgress)
+	bosMock.On("Insert", expRemoveOp).Return(nil).Once()
+	expDesc := fmt.Sprintf("unbinding failed on error: while removing instance bind data from storage: %s", fixErr)
+	operationFailed := make(chan struct{})
+	bosMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.BindingID, ts.Exp.OperationID, internal.OperationStateFailed, &expDesc).Return(nil).Once().
+		Run(func(mock.Arguments) { close(operationFailed) })
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUnbindService(bsgMock, bosMock, ibdrMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUnbindRequest()
+
+	// WHEN
+	resp, err := svc.Unbind(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.Async)
+
+	select {
+	case <-operationFailed:
+	case <-time.After(time.Millisecond * 100):
+		t.Fatal("timeout on operation failed")
+	}
+}
+
+func TestUnbindServiceUnbindSuccessAsyncWhenUnbindingInProgress(t *testing.T) {
+	// GIVEN
+	ts := newUnbindServiceTestSuite(t)
+	ts.SetUp()
+
+	bsgMock := &automock.BindStateGetter{}
+	defer bsgMock.AssertExpectations(t)
+	bsgMock.On("IsUnbindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(ts.Exp.OperationID, true, nil).Once()
+
+	bosMock := &automock.BindOperationStorage{}
+	defer bosMock.AssertExpectations(t)
+	ibdrMock := &automock.InstanceBindDataRemover{}
+	defer ibdrMock.AssertExpectations(t)
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUnbindService(bsgMock, bosMock, ibdrMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUnbindRequest()
+
+	// WHEN
+	resp, err := svc.Unbind(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.Async)
+	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
+}
+
+func TestUnbindServiceUnbindFailureWhenNotBound(t *testing.T) {
+	// GIVEN
+	ts := newUnbindServiceTestSuite(t)
+	ts.SetUp()
+
+	bsgMock := &automock.BindStateGetter{}
+	defer bsgMock.AssertExpectations(t)
+	bsgMock.On("IsUnbindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.BindOperation{}, false, nil).Once()
+
+	bosMock := &automock.BindOperationStorage{}
+	defer bosMock.AssertExpectations(t)
+	ibdrMock := &automock.InstanceBindDataRemover{}
+	defer ibdrMock.AssertExpectations(t)
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUnbindService(bsgMock, bosMock, ibdrMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUnbindRequest()
+
+	// WHEN
+	resp, err := svc.Unbind(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusGone, err.StatusCode)
+}
+
+func TestUnbindServiceUnbindFailureWhenBindingInProgress(t *testing.T) {
+	// GIVEN
+	ts := newUnbindServiceTestSuite(t)
+	ts.SetUp()
+
+	bsgMock := &automock.BindStateGetter{}
+	defer bsgMock.AssertExpectations(t)
+	bsgMock.On("IsUnbindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(ts.Exp.OperationID, true, nil).Once()
+
+	bosMock := &automock.BindOperationStorage{}
+	defer bosMock.AssertExpectations(t)
+	ibdrMock := &automock.InstanceBindDataRemover{}
+	defer ibdrMock.AssertExpectations(t)
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUnbindService(bsgMock, bosMock, ibdrMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUnbindRequest()
+
+	// WHEN
+	resp, err := svc.Unbind(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.Equal(t, "ConcurrencyError", *err.ErrorMessage)
+}
+
+func TestUnbindServiceUnbindFailureOnOperationInProgress(t *testing.T) {
+	// GIVEN
+	ts := newUnbindServiceTestSuite(t)
+	ts.SetUp()
+
+	bsgMock := &automock.BindStateGetter{}
+	defer bsgMock.AssertExpectations(t)
+	bsgMock.On("IsUnbindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBindingInProgress", ts.Exp.InstanceID, ts.Exp.BindingID).Return(internal.OperationID(""), false, nil).Once()
+	bsgMock.On("IsBound", ts.Exp.InstanceID, ts.Exp.BindingID).Return(*ts.FixCreateBindOperation(), true, nil).Once()
+
+	bosMock := &automock.BindOperationStorage{}
+	defer bosMock.AssertExpectations(t)
+	bosMock.On("Insert", mock.Anything).Return(activeOperationInProgressError{}).Once()
+
+	ibdrMock := &automock.InstanceBindDataRemover{}
+	defer ibdrMock.AssertExpectations(t)
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUnbindService(bsgMock, bosMock, ibdrMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUnbindRequest()
+
+	// WHEN
+	resp, err := svc.Unbind(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+}
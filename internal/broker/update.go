@@ -0,0 +1,342 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Masterminds/semver"
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+type updateService struct {
+	addonGetter         addonGetter
+	addonIDGetter       addonIDGetter
+	chartGetter         chartGetter
+	instanceInserter    instanceInserter
+	instanceGetter      instanceGetter
+	instanceStateGetter instanceStateProvisionGetter
+	operationInserter   operationInserter
+	operationUpdater    operationUpdater
+	operationIDProvider func() (internal.OperationID, error)
+	helmUpgrader        helmUpgrader
+	helmTester          helmTester
+	instanceLocker      *instanceLocker
+	operationQueue      *operationQueue
+
+	dashboardRenderer dashboardURLRenderer
+
+	log *logrus.Entry
+
+	testHookAsyncCalled func(internal.OperationID)
+}
+
+// UpdateInstanceRequest extends the osb.UpdateInstanceRequest with the fields which are not supported by the OSB client
+type UpdateInstanceRequest struct {
+	osb.UpdateInstanceRequest
+	// MaintenanceInfo is set when the Platform requests the upgrade of the instance to the addon version from the catalog
+	MaintenanceInfo *MaintenanceInfoDTO
+}
+
+func (svc *updateService) Update(ctx context.Context, osbCtx OsbContext, req *UpdateInstanceRequest) (*osb.UpdateInstanceResponse, *osb.HTTPStatusCodeError) {
+	if !req.AcceptsIncomplete {
+		return nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr("asynchronous operation mode required")}
+	}
+
+	resp, preview, err := svc.update(ctx, osbCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if preview != nil {
+		if dashboardURL, err := preview(); err != nil {
+			svc.log.Errorf("Cannot render dashboard URL of instance %s: %v", req.InstanceID, err)
+		} else if dashboardURL != "" {
+			resp.DashboardURL = &dashboardURL
+		}
+	}
+
+	return resp, nil
+}
+
+// update starts the update of the instance, the preview of the dashboard URL is returned when it can be rendered
+func (svc *updateService) update(ctx context.Context, osbCtx OsbContext, req *UpdateInstanceRequest) (*osb.UpdateInstanceResponse, dashboardURLPreview, *osb.HTTPStatusCodeError) {
+	iID := internal.InstanceID(req.InstanceID)
+
+	svc.instanceLocker.Lock(iID)
+	defer svc.instanceLocker.Unlock(iID)
+
+	svc.log.Infof("Triggered update %+v", req)
+
+	switch provisioned, err := svc.instanceStateGetter.IsProvisioned(iID); {
+	case err != nil:
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while checking if instance is provisioned: %v", err))}
+	case !provisioned:
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("instance %q is not provisioned", iID))}
+	}
+
+	instance, err := svc.instanceGetter.Get(iID)
+	switch {
+	case IsNotFoundError(err):
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting instance: %v", err))}
+	case err != nil:
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting instance: %v", err))}
+	}
+
+	svcID := internal.ServiceID(req.ServiceID)
+	if svcID != instance.ServiceID {
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("service id %q does not match the service id %q of the instance", svcID, instance.ServiceID))}
+	}
+
+	// addonID is in 1:1 match with serviceID (from service catalog)
+	addonID := internal.AddonID(instance.ServiceID)
+	addon, err := svc.addonIDGetter.GetByID(osbCtx.BrokerNamespace, addonID)
+	switch {
+	case IsNotFoundError(err):
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while getting addon: %v", err))}
+	case err != nil:
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting addon: %v", err))}
+	}
+
+	// the instance is upgraded to the current version of the addon only when the maintenance info sent by the Platform
+	// points to that version, the maintenance info of the instance version does not upgrade it
+	upgrade := false
+	if req.MaintenanceInfo != nil && osbCtx.supports(osbAPIFeatureMaintenanceInfo) {
+		switch req.MaintenanceInfo.Version {
+		case string(instance.AddonVersion):
+		case addon.Version.String():
+			upgrade = true
+		default:
+			// message as defined in https://github.com/openservicebrokerapi/servicebroker/blob/v2.15/spec.md#service-broker-errors
+			return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusUnprocessableEntity, ErrorMessage: strPtr("MaintenanceInfoConflict"), Description: strPtr(fmt.Sprintf("The maintenance info version %q does not match the addon version %q, fetch the catalog and retry.", req.MaintenanceInfo.Version, addon.Version.String()))}
+		}
+	}
+
+	// without the upgrade the instance stays on its addon version, the instances provisioned before
+	// the addon version was stored are updated with the current version of the addon
+	if !upgrade && instance.AddonVersion != "" && string(instance.AddonVersion) != addon.Version.String() {
+		instanceAddon, found, err := svc.getAddonInVersion(osbCtx.BrokerNamespace, addon.Name, instance.AddonVersion)
+		switch {
+		case err != nil:
+			return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while getting addon in version of the instance: %v", err))}
+		case !found:
+			return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon %q in version %q of the instance is not available, the instance must be upgraded to the version %q with the maintenance info", addon.Name, instance.AddonVersion, addon.Version.String()))}
+		}
+		addon = instanceAddon
+	}
+
+	servicePlanID := instance.ServicePlanID
+	if req.PlanID != nil && internal.ServicePlanID(*req.PlanID) != instance.ServicePlanID {
+		if addon.PlanUpdatable == nil || !*addon.PlanUpdatable {
+			return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon %q does not support changing the plan of the instance", addon.Name))}
+		}
+		servicePlanID = internal.ServicePlanID(*req.PlanID)
+	}
+
+	// addonPlanID is in 1:1 match with servicePlanID (from service catalog)
+	addonPlanID := internal.AddonPlanID(servicePlanID)
+	addonPlan, found := addon.Plans[addonPlanID]
+	if !found {
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("addon does not contain requested plan (planID: %s)", addonPlanID))}
+	}
+
+	switch err := validateParameters(addonPlan, internal.SchemaTypeUpdate, req.Parameters); {
+	case IsParametersValidationError(err):
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("update parameters are invalid: %v", err))}
+	case err != nil:
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while validating update parameters: %v", err))}
+	}
+
+	var storedParams map[string]interface{}
+	if instance.ProvisioningParameters != nil {
+		storedParams = instance.ProvisioningParameters.Data
+	}
+	mergedParams, err := deepCopy(storedParams)
+	if err != nil {
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while coping instance parameters: %v", err))}
+	}
+	mergedParams = mergeValues(mergedParams, req.Parameters)
+	updatedParameters := internal.RequestParameters{Data: mergedParams}
+
+	opID, err := svc.operationIDProvider()
+	if err != nil {
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError, ErrorMessage: strPtr(fmt.Sprintf("while generating operation ID: %v", err))}
+	}
+
+	op := internal.InstanceOperation{
+		InstanceID:             iID,
+		OperationID:            opID,
+		Type:                   internal.OperationTypeUpdate,
+		State:                  internal.OperationStateInProgress,
+		ProvisioningParameters: &updatedParameters,
+		RequestedBy:            osbCtx.requester(),
+	}
+
+	err = svc.operationInserter.Insert(&op)
+	switch {
+	case IsActiveOperationInProgressError(err):
+		// message as defined in https://github.com/openservicebrokerapi/servicebroker/blob/v2.14/spec.md#broker-errors
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusUnprocessableEntity, ErrorMessage: strPtr("ConcurrencyError"), Description: strPtr("Another operation for this service instance is in progress.")}
+	case err != nil:
+		return nil, nil, &osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest, ErrorMessage: strPtr(fmt.Sprintf("while inserting instance operation to storage: %v", err))}
+	}
+
+	// the instance is modified by the operation, so the preview gets its copy before the operation is started.
+	// The current dashboard URL is returned when the preview cannot be prepared.
+	dashboardURL := instance.DashboardURL
+	var preview dashboardURLPreview
+	if values, err := releaseValues(addonPlan, updatedParameters.Data, addon.RepositoryURL); err != nil {
+		svc.log.Errorf("Cannot render dashboard URL of instance %s: %v", iID, err)
+	} else {
+		preview = newDashboardURLPreview(svc.chartGetter, svc.dashboardRenderer, osbCtx.BrokerNamespace, addonPlan, *instance, values)
+	}
+
+	updateInput := updatingInput{
+		instanceID:          iID,
+		operationID:         opID,
+		namespace:           instance.Namespace,
+		brokerNamespace:     osbCtx.BrokerNamespace,
+		releaseName:         instance.ReleaseName,
+		servicePlanID:       servicePlanID,
+		addonPlan:           addonPlan,
+		addonsRepositoryURL: addon.RepositoryURL,
+		addonVersion:        internal.AddonVersion(addon.Version.String()),
+		parameters:          updatedParameters,
+		instanceToUpdate:    instance,
+	}
+
+	svc.doAsync(ctx, updateInput)
+
+	opKey := osb.OperationKey(op.OperationID)
+	resp := &osb.UpdateInstanceResponse{
+		OperationKey: &opKey,
+		Async:        true,
+	}
+	if preview == nil && dashboardURL != "" {
+		resp.DashboardURL = &dashboardURL
+	}
+
+	return resp, preview, nil
+}
+
+// getAddonInVersion returns the addon in the given version if the storage still contains it
+func (svc *updateService) getAddonInVersion(namespace internal.Namespace, name internal.AddonName, version internal.AddonVersion) (*internal.Addon, bool, error) {
+	ver, err := semver.NewVersion(string(version))
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "while parsing addon version %q", version)
+	}
+	addon, err := svc.addonGetter.Get(namespace, name, *ver)
+	switch {
+	case IsNotFoundError(err):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, errors.Wrap(err, "while getting addon from storage")
+	}
+	// the storage keeps one version of the addon with the given ID, so the replaced version can resolve to the new one
+	if !addon.Version.Equal(ver) {
+		return nil, false, nil
+	}
+	return addon, true, nil
+}
+
+// updatingInput holds all information required to update a given instance
+type updatingInput struct {
+	instanceID          internal.InstanceID
+	operationID         internal.OperationID
+	namespace           internal.Namespace
+	brokerNamespace     internal.Namespace
+	releaseName         internal.ReleaseName
+	servicePlanID       internal.ServicePlanID
+	addonPlan           internal.AddonPlan
+	addonsRepositoryURL string
+	addonVersion        internal.AddonVersion
+	parameters          internal.RequestParameters
+	instanceToUpdate    *internal.Instance
+}
+
+func (svc *updateService) doAsync(ctx context.Context, input updatingInput) {
+	if svc.testHookAsyncCalled != nil {
+		svc.testHookAsyncCalled(input.operationID)
+	}
+	svc.operationQueue.Enqueue(asyncOperation{
+		operationID: input.operationID,
+		namespace:   input.namespace,
+		do:          func(ctx context.Context) { svc.do(ctx, input) },
+	})
+}
+
+// do is called asynchronously
+func (svc *updateService) do(ctx context.Context, input updatingInput) {
+
+	fDo := func() error {
+		c, err := svc.chartGetter.Get(input.brokerNamespace, input.addonPlan.ChartRef.Name, input.addonPlan.ChartRef.Version)
+		if err != nil {
+			return errors.Wrap(err, "while getting chart from storage")
+		}
+
+		out, err := releaseValues(input.addonPlan, input.parameters.Data, input.addonsRepositoryURL)
+		if err != nil {
+			return err
+		}
+
+		svc.log.Infof("Merging values for operation [%s], releaseName [%s], namespace [%s], addonPlan [%s]. Plan values are: [%v], overrides: [%v], merged: [%v] ",
+			input.operationID, input.releaseName, input.namespace, input.addonPlan.Name, input.addonPlan.ChartValues, input.parameters.Data, out)
+
+		resp, err := svc.helmUpgrader.Upgrade(c, out, input.releaseName, input.namespace, input.addonPlan.HelmOptions)
+		if err != nil {
+			cause := errors.Cause(err)
+			if apiErrors.IsForbidden(cause) {
+				return errors.Wrap(cause, "user has no sufficient permissions to update a service")
+			}
+			return errors.Wrap(err, "while upgrading helm release")
+		}
+
+		svc.log.Infof("Triggered helm upgrade: %s %d", resp.Name, resp.Version)
+
+		paramsHash, err := requestParamsHash(input.parameters.Data)
+		if err != nil {
+			return errors.Wrap(err, "while computing hash of instance parameters")
+		}
+
+		updatedInstance := input.instanceToUpdate
+		updatedInstance.ServicePlanID = input.servicePlanID
+		updatedInstance.ProvisioningParameters = &input.parameters
+		updatedInstance.ParamsHash = paramsHash
+		updatedInstance.AddonVersion = input.addonVersion
+		updatedInstance.HelmOptions = input.addonPlan.HelmOptions
+		updatedInstance.ReleaseInfo = internal.ReleaseInfo{
+			ReleaseTime:  resp.Info.LastDeployed.Time,
+			Revision:     resp.Version,
+			ConfigValues: resp.Config,
+		}
+
+		if url, err := renderDashboardURL(svc.dashboardRenderer, input.addonPlan, updatedInstance, c); err != nil {
+			svc.log.Errorf("Cannot render dashboard URL of instance %s: %v", updatedInstance.ID, err)
+		} else {
+			updatedInstance.DashboardURL = url
+		}
+
+		if _, err := svc.instanceInserter.Upsert(updatedInstance); err != nil {
+			return errors.Wrap(err, "while updating instance in storage")
+		}
+
+		return runChartTests(svc.helmTester, input.addonPlan, input.releaseName, input.namespace)
+	}
+
+	opState := internal.OperationStateSucceeded
+	opDesc := "update succeeded"
+
+	if err := fDo(); err != nil {
+		opState = internal.OperationStateFailed
+		opDesc = fmt.Sprintf("update failed on error: %s", err.Error())
+	}
+
+	if err := svc.operationUpdater.UpdateStateDesc(input.instanceID, input.operationID, opState, &opDesc); err != nil {
+		svc.log.Errorf("State description was not updated, got error: %v", err)
+	}
+}
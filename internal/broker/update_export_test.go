@@ -0,0 +1,30 @@
+package broker
+
+import (
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/sirupsen/logrus"
+)
+
+func NewUpdateService(as addonStorage, cg chartGetter, is instanceStorage, isg instanceStateGetter, oi operationInserter, ou operationUpdater,
+	hu helmUpgrader, oIDProv func() (internal.OperationID, error), log *logrus.Entry) *updateService {
+	return &updateService{
+		addonGetter:         as,
+		addonIDGetter:       as,
+		chartGetter:         cg,
+		instanceGetter:      is,
+		instanceInserter:    is,
+		instanceStateGetter: isg,
+		operationInserter:   oi,
+		operationUpdater:    ou,
+		operationIDProvider: oIDProv,
+		helmUpgrader:        hu,
+		log:                 log,
+		instanceLocker:      newInstanceLocker(),
+		operationQueue:      newStartedOperationQueue(),
+	}
+}
+
+func (svc *updateService) WithTestHookOnAsyncCalled(h func(internal.OperationID)) *updateService {
+	svc.testHookAsyncCalled = h
+	return svc
+}
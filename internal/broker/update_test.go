@@ -0,0 +1,751 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver"
+	osb "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/broker"
+	"github.com/kyma-project/helm-broker/internal/broker/automock"
+	"github.com/kyma-project/helm-broker/internal/platform/logger/spy"
+)
+
+func newUpdateServiceTestSuite(t *testing.T) *updateServiceTestSuite {
+	return &updateServiceTestSuite{t: t}
+}
+
+type updateServiceTestSuite struct {
+	t   *testing.T
+	Exp expAll
+}
+
+func (ts *updateServiceTestSuite) SetUp() {
+	ts.Exp.Populate()
+}
+
+func (ts *updateServiceTestSuite) FixAddon() internal.Addon {
+	return *ts.Exp.NewAddon()
+}
+
+func (ts *updateServiceTestSuite) FixChart() chart.Chart {
+	return *ts.Exp.NewChart()
+}
+
+func (ts *updateServiceTestSuite) FixInstance() internal.Instance {
+	return *ts.Exp.NewInstance()
+}
+
+func (ts *updateServiceTestSuite) FixUpdateParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"foo": "bar",
+	}
+}
+
+func (ts *updateServiceTestSuite) FixMergedParameters() *internal.RequestParameters {
+	return &internal.RequestParameters{
+		Data: map[string]interface{}{
+			"addonsRepositoryURL": ts.Exp.Addon.RepositoryURL,
+			"foo":                 "bar",
+		},
+	}
+}
+
+func (ts *updateServiceTestSuite) FixInstanceOperation() internal.InstanceOperation {
+	return internal.InstanceOperation{
+		InstanceID:             ts.Exp.InstanceID,
+		OperationID:            ts.Exp.OperationID,
+		Type:                   internal.OperationTypeUpdate,
+		State:                  internal.OperationStateInProgress,
+		ProvisioningParameters: ts.FixMergedParameters(),
+	}
+}
+
+func (ts *updateServiceTestSuite) FixUpdateRequest() broker.UpdateInstanceRequest {
+	return broker.UpdateInstanceRequest{
+		UpdateInstanceRequest: osb.UpdateInstanceRequest{
+			InstanceID:        string(ts.Exp.InstanceID),
+			ServiceID:         string(ts.Exp.Service.ID),
+			Parameters:        ts.FixUpdateParameters(),
+			AcceptsIncomplete: true,
+		},
+	}
+}
+
+func TestUpdateServiceUpdateSuccessAsyncUpgrade(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	expAddon := ts.FixAddon()
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	defer cgMock.AssertExpectations(t)
+	expChart := ts.FixChart()
+	cgMock.On("Get", internal.ClusterWide, ts.Exp.Chart.Name, ts.Exp.Chart.Version).Return(&expChart, nil).Once()
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	fixInstance := ts.FixInstance()
+	isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+	expInstance := ts.FixInstance()
+	expInstance.ProvisioningParameters = ts.FixMergedParameters()
+	expInstance.ParamsHash = broker.ParamsHash(ts.FixMergedParameters().Data)
+	expInstance.ReleaseInfo = internal.ReleaseInfo{Revision: 2}
+	isMock.On("Upsert", &expInstance).Return(true, nil).Once()
+
+	ioMock := &automock.OperationStorage{}
+	defer ioMock.AssertExpectations(t)
+	expInstOp := ts.FixInstanceOperation()
+	ioMock.On("Insert", &expInstOp).Return(nil).Once()
+	operationSucceeded := make(chan struct{})
+	ioMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.OperationID, internal.OperationStateSucceeded, mock.Anything).Return(nil).Once().
+		Run(func(mock.Arguments) { close(operationSucceeded) })
+
+	huMock := &automock.HelmClient{}
+	defer huMock.AssertExpectations(t)
+	expValues := internal.ChartValues{
+		"addonsRepositoryURL": expAddon.RepositoryURL,
+		"foo":                 "bar",
+	}
+	huMock.On("Upgrade", &expChart, expValues, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}, Version: 2}, nil).Once()
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
+	}
+
+	testHookCalled := make(chan struct{})
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy()).
+		WithTestHookOnAsyncCalled(func(opID internal.OperationID) {
+			assert.Equal(t, ts.Exp.OperationID, opID)
+			close(testHookCalled)
+		})
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUpdateRequest()
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.Async)
+	assert.EqualValues(t, ts.Exp.OperationID, *resp.OperationKey)
+
+	select {
+	case <-operationSucceeded:
+	case <-time.After(time.Millisecond * 100):
+		t.Fatal("timeout on operation succeeded")
+	}
+
+	select {
+	case <-testHookCalled:
+	default:
+		t.Fatal("async test hook not called")
+	}
+}
+
+func TestUpdateServiceUpdateFailureAsyncOnUpgrade(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	expAddon := ts.FixAddon()
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	defer cgMock.AssertExpectations(t)
+	expChart := ts.FixChart()
+	cgMock.On("Get", internal.ClusterWide, ts.Exp.Chart.Name, ts.Exp.Chart.Version).Return(&expChart, nil).Once()
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	fixInstance := ts.FixInstance()
+	isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+
+	ioMock := &automock.OperationStorage{}
+	defer ioMock.AssertExpectations(t)
+	expInstOp := ts.FixInstanceOperation()
+	ioMock.On("Insert", &expInstOp).Return(nil).Once()
+	fixErr := errors.New("fake upgrade error")
+	expDesc := fmt.Sprintf("update failed on error: while upgrading helm release: %s", fixErr)
+	operationFailed := make(chan struct{})
+	ioMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.OperationID, internal.OperationStateFailed, &expDesc).Return(nil).Once().
+		Run(func(mock.Arguments) { close(operationFailed) })
+
+	huMock := &automock.HelmClient{}
+	defer huMock.AssertExpectations(t)
+	huMock.On("Upgrade", &expChart, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(nil, fixErr).Once()
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUpdateRequest()
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.Async)
+
+	select {
+	case <-operationFailed:
+	case <-time.After(time.Millisecond * 100):
+		t.Fatal("timeout on operation failed")
+	}
+}
+
+func TestUpdateServiceUpdateFailureOnOperationInProgress(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	expAddon := ts.FixAddon()
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	fixInstance := ts.FixInstance()
+	isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+
+	ioMock := &automock.OperationStorage{}
+	defer ioMock.AssertExpectations(t)
+	ioMock.On("Insert", mock.Anything).Return(activeOperationInProgressError{}).Once()
+
+	huMock := &automock.HelmClient{}
+	defer huMock.AssertExpectations(t)
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
+	}
+
+	testHookCalled := make(chan struct{})
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy()).
+		WithTestHookOnAsyncCalled(func(internal.OperationID) { close(testHookCalled) })
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUpdateRequest()
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.Equal(t, "ConcurrencyError", *err.ErrorMessage)
+
+	select {
+	case <-testHookCalled:
+		t.Fatal("async test hook called")
+	default:
+	}
+}
+
+func TestUpdateServiceUpdateFailureOnNotProvisionedInstance(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(false, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	cgMock := &automock.ChartGetter{}
+	isMock := &automock.InstanceStorage{}
+	ioMock := &automock.OperationStorage{}
+	huMock := &automock.HelmClient{}
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUpdateRequest()
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+}
+
+func TestUpdateServiceUpdateSuccessOnPlanChange(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+	newPlanID := internal.AddonPlanID("new-plan-id")
+	newChartName := internal.ChartName("new-chart-name")
+	planUpdatable := true
+	expAddon := ts.FixAddon()
+	expAddon.PlanUpdatable = &planUpdatable
+	expAddon.Plans[newPlanID] = internal.AddonPlan{
+		ID:   newPlanID,
+		Name: "new-plan",
+		ChartRef: internal.ChartRef{
+			Name:    newChartName,
+			Version: ts.Exp.Chart.Version,
+		},
+		ChartValues: internal.ChartValues{
+			"replicas": "3",
+		},
+	}
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	defer cgMock.AssertExpectations(t)
+	expChart := ts.FixChart()
+	cgMock.On("Get", internal.ClusterWide, newChartName, ts.Exp.Chart.Version).Return(&expChart, nil).Once()
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	fixInstance := ts.FixInstance()
+	isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+	expInstance := ts.FixInstance()
+	expInstance.ServicePlanID = internal.ServicePlanID(newPlanID)
+	expInstance.ProvisioningParameters = ts.FixMergedParameters()
+	expInstance.ParamsHash = broker.ParamsHash(ts.FixMergedParameters().Data)
+	expInstance.ReleaseInfo = internal.ReleaseInfo{Revision: 2}
+	isMock.On("Upsert", &expInstance).Return(true, nil).Once()
+
+	ioMock := &automock.OperationStorage{}
+	defer ioMock.AssertExpectations(t)
+	expInstOp := ts.FixInstanceOperation()
+	ioMock.On("Insert", &expInstOp).Return(nil).Once()
+	operationSucceeded := make(chan struct{})
+	ioMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.OperationID, internal.OperationStateSucceeded, mock.Anything).Return(nil).Once().
+		Run(func(mock.Arguments) { close(operationSucceeded) })
+
+	huMock := &automock.HelmClient{}
+	defer huMock.AssertExpectations(t)
+	expValues := internal.ChartValues{
+		"addonsRepositoryURL": expAddon.RepositoryURL,
+		"foo":                 "bar",
+		"replicas":            "3",
+	}
+	huMock.On("Upgrade", &expChart, expValues, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}, Version: 2}, nil).Once()
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUpdateRequest()
+	reqPlanID := string(newPlanID)
+	req.PlanID = &reqPlanID
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.Async)
+
+	select {
+	case <-operationSucceeded:
+	case <-time.After(time.Millisecond * 100):
+		t.Fatal("timeout on operation succeeded")
+	}
+}
+
+func TestUpdateServiceUpdateFailureOnPlanChange(t *testing.T) {
+	for tn, planUpdatable := range map[string]*bool{
+		"not set":      nil,
+		"set to false": func() *bool { b := false; return &b }(),
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			ts := newUpdateServiceTestSuite(t)
+			ts.SetUp()
+
+			isgMock := &automock.InstanceStateGetter{}
+			defer isgMock.AssertExpectations(t)
+			isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+			isMock := &automock.InstanceStorage{}
+			defer isMock.AssertExpectations(t)
+			fixInstance := ts.FixInstance()
+			isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+
+			bgMock := &automock.AddonStorage{}
+			defer bgMock.AssertExpectations(t)
+			expAddon := ts.FixAddon()
+			expAddon.PlanUpdatable = planUpdatable
+			bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+
+			cgMock := &automock.ChartGetter{}
+			ioMock := &automock.OperationStorage{}
+			huMock := &automock.HelmClient{}
+
+			oipFake := func() (internal.OperationID, error) {
+				t.Error("operation ID provider called when it should not be")
+				return ts.Exp.OperationID, nil
+			}
+
+			svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+			osbCtx := *broker.NewOSBContext("", "v1")
+			req := ts.FixUpdateRequest()
+			otherPlanID := "other-plan-id"
+			req.PlanID = &otherPlanID
+
+			// WHEN
+			resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+			// THEN
+			assert.Nil(t, resp)
+			require.NotNil(t, err)
+			assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+		})
+	}
+}
+
+func TestUpdateServiceUpdateFailureOnInvalidParameters(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	expAddon := ts.Exp.NewAddonWithPlanSchemas()
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(expAddon, nil).Once()
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	fixInstance := ts.FixInstance()
+	isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	ioMock := &automock.OperationStorage{}
+	huMock := &automock.HelmClient{}
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "v1")
+	req := ts.FixUpdateRequest()
+	req.Parameters = map[string]interface{}{"replicas": 0}
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	assert.Contains(t, *err.ErrorMessage, "replicas: Must be greater than or equal to 1")
+}
+
+func TestUpdateServiceUpdateUpgradesInstanceToAddonVersionFromMaintenanceInfo(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	expAddon := ts.FixAddon()
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	defer cgMock.AssertExpectations(t)
+	expChart := ts.FixChart()
+	cgMock.On("Get", internal.ClusterWide, ts.Exp.Chart.Name, ts.Exp.Chart.Version).Return(&expChart, nil).Once()
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	fixInstance := ts.FixInstance()
+	fixInstance.AddonVersion = "0.0.1"
+	isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+	expInstance := ts.FixInstance()
+	expInstance.ReleaseInfo = internal.ReleaseInfo{Revision: 2}
+	expInstance.ParamsHash = broker.ParamsHash(ts.Exp.ProvisioningParameters.Data)
+	isMock.On("Upsert", &expInstance).Return(true, nil).Once()
+
+	ioMock := &automock.OperationStorage{}
+	defer ioMock.AssertExpectations(t)
+	expInstOp := ts.FixInstanceOperation()
+	expInstOp.ProvisioningParameters = ts.Exp.ProvisioningParameters
+	ioMock.On("Insert", &expInstOp).Return(nil).Once()
+	operationSucceeded := make(chan struct{})
+	ioMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.OperationID, internal.OperationStateSucceeded, mock.Anything).Return(nil).Once().
+		Run(func(mock.Arguments) { close(operationSucceeded) })
+
+	huMock := &automock.HelmClient{}
+	defer huMock.AssertExpectations(t)
+	expValues := internal.ChartValues{
+		"addonsRepositoryURL": expAddon.RepositoryURL,
+	}
+	huMock.On("Upgrade", &expChart, expValues, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}, Version: 2}, nil).Once()
+
+	oipFake := func() (internal.OperationID, error) {
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "2.15")
+	req := ts.FixUpdateRequest()
+	req.Parameters = nil
+	req.MaintenanceInfo = &broker.MaintenanceInfoDTO{Version: ts.Exp.Addon.Version.String()}
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	require.Nil(t, err)
+	assert.True(t, resp.Async)
+
+	select {
+	case <-operationSucceeded:
+	case <-time.After(time.Second):
+		t.Fatal("timeout on operation succeeded")
+	}
+}
+
+func TestUpdateServiceUpdateKeepsInstanceOnItsAddonVersion(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		maintenanceInfo *broker.MaintenanceInfoDTO
+	}{
+		"without maintenance info": {},
+		"with maintenance info of instance version": {
+			maintenanceInfo: &broker.MaintenanceInfoDTO{Version: "0.0.1"},
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// GIVEN
+			ts := newUpdateServiceTestSuite(t)
+			ts.SetUp()
+
+			isgMock := &automock.InstanceStateGetter{}
+			defer isgMock.AssertExpectations(t)
+			isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+			instanceAddonVersion := *semver.MustParse("0.0.1")
+			instanceChartVersion := *semver.MustParse("1.0.0")
+
+			bgMock := &automock.AddonStorage{}
+			defer bgMock.AssertExpectations(t)
+			expAddon := ts.FixAddon()
+			bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+			instanceAddon := ts.FixAddon()
+			instanceAddon.Version = instanceAddonVersion
+			instancePlan := instanceAddon.Plans[ts.Exp.AddonPlan.ID]
+			instancePlan.ChartRef.Version = instanceChartVersion
+			instanceAddon.Plans[ts.Exp.AddonPlan.ID] = instancePlan
+			bgMock.On("Get", internal.ClusterWide, ts.Exp.Addon.Name, instanceAddonVersion).Return(&instanceAddon, nil).Once()
+
+			cgMock := &automock.ChartGetter{}
+			defer cgMock.AssertExpectations(t)
+			instanceChart := ts.FixChart()
+			instanceChart.Metadata.Version = instanceChartVersion.String()
+			cgMock.On("Get", internal.ClusterWide, ts.Exp.Chart.Name, instanceChartVersion).Return(&instanceChart, nil).Once()
+
+			isMock := &automock.InstanceStorage{}
+			defer isMock.AssertExpectations(t)
+			fixInstance := ts.FixInstance()
+			fixInstance.AddonVersion = internal.AddonVersion(instanceAddonVersion.String())
+			isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+			expInstance := ts.FixInstance()
+			expInstance.AddonVersion = internal.AddonVersion(instanceAddonVersion.String())
+			expInstance.ProvisioningParameters = ts.FixMergedParameters()
+			expInstance.ParamsHash = broker.ParamsHash(ts.FixMergedParameters().Data)
+			expInstance.ReleaseInfo = internal.ReleaseInfo{Revision: 2}
+			isMock.On("Upsert", &expInstance).Return(true, nil).Once()
+
+			ioMock := &automock.OperationStorage{}
+			defer ioMock.AssertExpectations(t)
+			expInstOp := ts.FixInstanceOperation()
+			ioMock.On("Insert", &expInstOp).Return(nil).Once()
+			operationSucceeded := make(chan struct{})
+			ioMock.On("UpdateStateDesc", ts.Exp.InstanceID, ts.Exp.OperationID, internal.OperationStateSucceeded, mock.Anything).Return(nil).Once().
+				Run(func(mock.Arguments) { close(operationSucceeded) })
+
+			huMock := &automock.HelmClient{}
+			defer huMock.AssertExpectations(t)
+			huMock.On("Upgrade", &instanceChart, mock.Anything, ts.Exp.ReleaseName, ts.Exp.Namespace, mock.Anything).Return(&release.Release{Info: &release.Info{}, Version: 2}, nil).Once()
+
+			oipFake := func() (internal.OperationID, error) {
+				return ts.Exp.OperationID, nil
+			}
+
+			svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+			osbCtx := *broker.NewOSBContext("", "2.15")
+			req := ts.FixUpdateRequest()
+			req.MaintenanceInfo = tc.maintenanceInfo
+
+			// WHEN
+			resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+			// THEN
+			require.Nil(t, err)
+			assert.True(t, resp.Async)
+
+			select {
+			case <-operationSucceeded:
+			case <-time.After(time.Second):
+				t.Fatal("timeout on operation succeeded")
+			}
+		})
+	}
+}
+
+func TestUpdateServiceUpdateFailureOnNotAvailableInstanceAddonVersion(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	expAddon := ts.FixAddon()
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+	// the storage resolves the replaced version to the current one
+	bgMock.On("Get", internal.ClusterWide, ts.Exp.Addon.Name, *semver.MustParse("0.0.1")).Return(&expAddon, nil).Once()
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	fixInstance := ts.FixInstance()
+	fixInstance.AddonVersion = "0.0.1"
+	isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	ioMock := &automock.OperationStorage{}
+	huMock := &automock.HelmClient{}
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "2.15")
+	req := ts.FixUpdateRequest()
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	assert.Contains(t, *err.ErrorMessage, "must be upgraded to the version \"0.1.2\" with the maintenance info")
+}
+
+func TestUpdateServiceUpdateFailureOnMaintenanceInfoConflict(t *testing.T) {
+	// GIVEN
+	ts := newUpdateServiceTestSuite(t)
+	ts.SetUp()
+
+	isgMock := &automock.InstanceStateGetter{}
+	defer isgMock.AssertExpectations(t)
+	isgMock.On("IsProvisioned", ts.Exp.InstanceID).Return(true, nil).Once()
+
+	bgMock := &automock.AddonStorage{}
+	defer bgMock.AssertExpectations(t)
+	expAddon := ts.FixAddon()
+	bgMock.On("GetByID", internal.ClusterWide, ts.Exp.Addon.ID).Return(&expAddon, nil).Once()
+
+	isMock := &automock.InstanceStorage{}
+	defer isMock.AssertExpectations(t)
+	fixInstance := ts.FixInstance()
+	isMock.On("Get", ts.Exp.InstanceID).Return(&fixInstance, nil).Once()
+
+	cgMock := &automock.ChartGetter{}
+	ioMock := &automock.OperationStorage{}
+	huMock := &automock.HelmClient{}
+
+	oipFake := func() (internal.OperationID, error) {
+		t.Error("operation ID provider called when it should not be")
+		return ts.Exp.OperationID, nil
+	}
+
+	svc := broker.NewUpdateService(bgMock, cgMock, isMock, isgMock, ioMock, ioMock, huMock, oipFake, spy.NewLogDummy())
+
+	osbCtx := *broker.NewOSBContext("", "2.15")
+	req := ts.FixUpdateRequest()
+	req.MaintenanceInfo = &broker.MaintenanceInfoDTO{Version: "9.9.9"}
+
+	// WHEN
+	resp, err := svc.Update(context.Background(), osbCtx, &req)
+
+	// THEN
+	assert.Nil(t, resp)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.Equal(t, "MaintenanceInfoConflict", *err.ErrorMessage)
+}
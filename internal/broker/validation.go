@@ -0,0 +1,62 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+// parametersValidationError is returned when given parameters do not match the plan schema.
+// It contains the description of each invalid field.
+type parametersValidationError struct {
+	fieldErrors []string
+}
+
+func (e *parametersValidationError) Error() string {
+	return strings.Join(e.fieldErrors, "; ")
+}
+
+// IsParametersValidationError checks if given error is the parameters validation error
+func IsParametersValidationError(err error) bool {
+	_, ok := errors.Cause(err).(*parametersValidationError)
+	return ok
+}
+
+// validateParameters validates given parameters against the plan schema of given type.
+// If the plan does not define such schema then parameters are not validated.
+func validateParameters(plan internal.AddonPlan, schemaType internal.PlanSchemaType, params map[string]interface{}) error {
+	schema, found := plan.Schemas[schemaType]
+	if !found {
+		return nil
+	}
+
+	rawSchema, err := json.Marshal(schema)
+	if err != nil {
+		return errors.Wrapf(err, "while marshalling %s schema of the plan %q", schemaType, plan.Name)
+	}
+
+	// parameters are optional in the OSB API, missing parameters are validated as an empty object
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(rawSchema), gojsonschema.NewGoLoader(params))
+	if err != nil {
+		return errors.Wrapf(err, "while validating parameters against %s schema of the plan %q", schemaType, plan.Name)
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	validationErr := &parametersValidationError{}
+	for _, resErr := range result.Errors() {
+		validationErr.fieldErrors = append(validationErr.fieldErrors, fmt.Sprintf("%s: %s", resErr.Field(), resErr.Description()))
+	}
+
+	return validationErr
+}
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/ghodss/yaml"
 	"github.com/imdario/mergo"
 
+	"github.com/kyma-project/helm-broker/internal/audit"
+	"github.com/kyma-project/helm-broker/internal/broker"
 	"github.com/kyma-project/helm-broker/internal/platform/logger"
 	"github.com/kyma-project/helm-broker/internal/storage"
 	defaults "github.com/mcuadros/go-defaults"
@@ -34,6 +37,26 @@ type Config struct {
 	MetricsPort int              `default:"8072"`
 	Storage     []storage.Config `valid:"required"`
 	HelmDriver  string           `default:"secrets"`
+	// OperationQueue defines limits of the asynchronous operations processing
+	OperationQueue broker.OperationQueueConfig
+	// OperationReaper defines timeouts after which operations in progress are treated as stale
+	OperationReaper broker.OperationReaperConfig
+	// DriftReconciler defines how the drift between instances and helm releases is reconciled
+	DriftReconciler broker.DriftReconcilerConfig
+	// AtomicProvisioning enables removing the helm release and the instance when the provisioning fails
+	AtomicProvisioning bool
+	// SynchronousOperationTimeout defines how long the synchronous provisioning and deprovisioning requests are awaited
+	SynchronousOperationTimeout time.Duration `default:"30s"`
+	// Auth defines how requests sent to the OSB API are authenticated
+	Auth broker.AuthConfig
+	// Authz defines how the originating identity of requests sent to the OSB API is authorized
+	Authz broker.AuthzConfig
+	// AdminAuth defines how requests sent to the admin API, e.g. the import of helm releases, are authenticated
+	AdminAuth broker.AdminAuthConfig
+	// TLS defines the certificate with which the OSB API is served, the plain HTTP is used when it is not set
+	TLS broker.TLSConfig
+	// Audit defines where the state transitions of operations are recorded
+	Audit audit.Config
 }
 
 // Load method has following strategy:
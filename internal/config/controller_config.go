@@ -10,6 +10,7 @@ import (
 	"github.com/asaskevich/govalidator"
 	"github.com/ghodss/yaml"
 	"github.com/imdario/mergo"
+	"github.com/kyma-project/helm-broker/internal/controller/broker"
 	"github.com/kyma-project/helm-broker/internal/platform/logger"
 	"github.com/kyma-project/helm-broker/internal/storage"
 	defaults "github.com/mcuadros/go-defaults"
@@ -38,6 +39,10 @@ type ControllerConfig struct {
 	UploadServiceURL         string `default:"http://rafter-upload-service.kyma-system.svc.cluster.local:3000"`
 	DocumentationEnabled     bool
 	ReprocessOnErrorDuration time.Duration `default:"5m"`
+	// BrokerAuth defines credentials with which the ServiceBrokers and the ClusterServiceBroker are registered
+	BrokerAuth broker.AuthConfig
+	// BrokerTLS defines if the ServiceBrokers and the ClusterServiceBroker are registered with the https URL
+	BrokerTLS broker.TLSConfig
 }
 
 // LoadControllerConfig method has following strategy:
@@ -0,0 +1,174 @@
+package broker
+
+import (
+	"context"
+
+	"github.com/kubernetes-sigs/service-catalog/pkg/apis/servicecatalog/v1beta1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kyma-project/helm-broker/internal/platform/nsauth"
+)
+
+const (
+	// AuthTypeBasic registers brokers with the basic auth credentials
+	AuthTypeBasic = "basic"
+	// AuthTypeBearer registers brokers with the bearer token
+	AuthTypeBearer = "bearer"
+
+	// NamespacedBrokerAuthSecretName name of the Secret with credentials of the namespaced Service Broker
+	NamespacedBrokerAuthSecretName = "helm-broker-auth"
+
+	basicAuthUsernameKey = "username"
+	basicAuthPasswordKey = "password"
+)
+
+// AuthConfig defines credentials which Service Catalog uses to authenticate to the broker
+type AuthConfig struct {
+	// Type defines the authentication type, one of: basic, bearer. Brokers are registered without credentials when it is not set.
+	Type string
+	// SecretName defines the Secret in the system namespace which holds the username and password keys
+	// for the basic auth or the token key for the bearer auth
+	SecretName string
+}
+
+func (c AuthConfig) enabled() bool {
+	return c.Type == AuthTypeBasic || c.Type == AuthTypeBearer
+}
+
+// clusterAuthInfo returns ClusterServiceBroker auth info which refers to the Secret in the system namespace
+func (c AuthConfig) clusterAuthInfo(systemNamespace string) *v1beta1.ClusterServiceBrokerAuthInfo {
+	ref := &v1beta1.ObjectReference{Namespace: systemNamespace, Name: c.SecretName}
+	switch c.Type {
+	case AuthTypeBasic:
+		return &v1beta1.ClusterServiceBrokerAuthInfo{Basic: &v1beta1.ClusterBasicAuthConfig{SecretRef: ref}}
+	case AuthTypeBearer:
+		return &v1beta1.ClusterServiceBrokerAuthInfo{Bearer: &v1beta1.ClusterBearerTokenAuthConfig{SecretRef: ref}}
+	}
+	return nil
+}
+
+// authInfo returns ServiceBroker auth info which refers to the Secret with credentials issued for the broker namespace
+func (c AuthConfig) authInfo() *v1beta1.ServiceBrokerAuthInfo {
+	ref := &v1beta1.LocalObjectReference{Name: NamespacedBrokerAuthSecretName}
+	switch c.Type {
+	case AuthTypeBasic:
+		return &v1beta1.ServiceBrokerAuthInfo{Basic: &v1beta1.BasicAuthConfig{SecretRef: ref}}
+	case AuthTypeBearer:
+		return &v1beta1.ServiceBrokerAuthInfo{Bearer: &v1beta1.BearerTokenAuthConfig{SecretRef: ref}}
+	}
+	return nil
+}
+
+// ensureNamespaceCredentials creates in the namespace of the ServiceBroker the Secret with credentials which the broker
+// accepts only for the routes of that namespace. For the basic auth the credentials are derived from the Secret
+// in the system namespace. For the bearer auth the Secret holds the token of the ServiceAccount created for the namespace.
+func ensureNamespaceCredentials(cli client.Client, cfg AuthConfig, systemNamespace, namespace string) error {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NamespacedBrokerAuthSecretName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				BrokerLabelKey: BrokerLabelValue,
+			},
+		},
+	}
+
+	switch cfg.Type {
+	case AuthTypeBasic:
+		source := &v1.Secret{}
+		if err := cli.Get(context.Background(), types.NamespacedName{Namespace: systemNamespace, Name: cfg.SecretName}, source); err != nil {
+			return errors.Wrapf(err, "while getting Secret %s/%s", systemNamespace, cfg.SecretName)
+		}
+		username, password := nsauth.BasicCredentials(source.Data[basicAuthUsernameKey], source.Data[basicAuthPasswordKey], namespace)
+		secret.Type = v1.SecretTypeOpaque
+		secret.Data = map[string][]byte{
+			basicAuthUsernameKey: username,
+			basicAuthPasswordKey: password,
+		}
+	case AuthTypeBearer:
+		if err := ensureServiceAccount(cli, namespace); err != nil {
+			return err
+		}
+		// the token of the ServiceAccount is added to the Secret by the Kubernetes token controller
+		secret.Type = v1.SecretTypeServiceAccountToken
+		secret.Annotations = map[string]string{v1.ServiceAccountNameKey: nsauth.ServiceAccountName}
+	}
+
+	return ensureSecret(cli, secret)
+}
+
+func ensureServiceAccount(cli client.Client, namespace string) error {
+	sa := &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nsauth.ServiceAccountName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				BrokerLabelKey: BrokerLabelValue,
+			},
+		},
+	}
+	err := cli.Create(context.Background(), sa)
+	switch {
+	case err == nil:
+		return nil
+	case !k8serrors.IsAlreadyExists(err):
+		return errors.Wrapf(err, "while creating ServiceAccount %s/%s", namespace, nsauth.ServiceAccountName)
+	}
+
+	existing := &v1.ServiceAccount{}
+	if err := cli.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: nsauth.ServiceAccountName}, existing); err != nil {
+		return errors.Wrapf(err, "while getting ServiceAccount %s/%s", namespace, nsauth.ServiceAccountName)
+	}
+	if !isManagedByBroker(existing.Labels) {
+		return errors.Errorf("ServiceAccount %s/%s already exists and is not managed by the broker", namespace, nsauth.ServiceAccountName)
+	}
+	return nil
+}
+
+// ensureSecret creates the Secret or updates the existing one, but only if it was created by the broker
+func ensureSecret(cli client.Client, secret *v1.Secret) error {
+	err := cli.Create(context.Background(), secret)
+	switch {
+	case err == nil:
+		return nil
+	case !k8serrors.IsAlreadyExists(err):
+		return errors.Wrapf(err, "while creating Secret %s/%s", secret.Namespace, secret.Name)
+	}
+
+	existing := &v1.Secret{}
+	if err := cli.Get(context.Background(), types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, existing); err != nil {
+		return errors.Wrapf(err, "while getting Secret %s/%s", secret.Namespace, secret.Name)
+	}
+	if !isManagedByBroker(existing.Labels) {
+		return errors.Errorf("Secret %s/%s already exists and is not managed by the broker", secret.Namespace, secret.Name)
+	}
+
+	// the type of the Secret cannot be changed, so the Secret created for other authentication type is replaced
+	if existing.Type != secret.Type {
+		if err := cli.Delete(context.Background(), existing); err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "while deleting Secret %s/%s", secret.Namespace, secret.Name)
+		}
+		if err := cli.Create(context.Background(), secret); err != nil {
+			return errors.Wrapf(err, "while creating Secret %s/%s", secret.Namespace, secret.Name)
+		}
+		return nil
+	}
+	if secret.Type == v1.SecretTypeServiceAccountToken {
+		return nil
+	}
+
+	existing.Data = secret.Data
+	if err := cli.Update(context.Background(), existing); err != nil {
+		return errors.Wrapf(err, "while updating Secret %s/%s", secret.Namespace, secret.Name)
+	}
+	return nil
+}
+
+func isManagedByBroker(labels map[string]string) bool {
+	return labels[BrokerLabelKey] == BrokerLabelValue
+}
@@ -1,7 +1,9 @@
 package broker
 
 import (
+	"bytes"
 	"fmt"
+	"reflect"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -11,10 +13,14 @@ import (
 	"github.com/kubernetes-sigs/service-catalog/pkg/apis/servicecatalog/v1beta1"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kyma-project/helm-broker/internal/platform/nsauth"
 )
 
 const (
@@ -32,16 +38,20 @@ type Facade struct {
 	systemNamespace string
 	serviceName     string
 	namespace       string
+	auth            AuthConfig
+	tls             TLSConfig
 
 	log logrus.FieldLogger
 }
 
 // NewBrokersFacade returns facade
-func NewBrokersFacade(cli client.Client, systemNamespace, serviceName string, log logrus.FieldLogger) *Facade {
+func NewBrokersFacade(cli client.Client, systemNamespace, serviceName string, auth AuthConfig, tls TLSConfig, log logrus.FieldLogger) *Facade {
 	return &Facade{
 		client:          cli,
 		systemNamespace: systemNamespace,
 		serviceName:     serviceName,
+		auth:            auth,
+		tls:             tls,
 		log:             log.WithField("service", "broker-facade"),
 	}
 }
@@ -54,10 +64,20 @@ func (f *Facade) SetNamespace(namespace string) {
 // Create creates ServiceBroker
 func (f *Facade) Create() error {
 	f.log.Infof("- creating ServiceBroker %s/%s", NamespacedBrokerName, f.namespace)
-	svcURL := fmt.Sprintf("http://%s.%s.svc.cluster.local", f.serviceName, f.systemNamespace)
+	svcURL := fmt.Sprintf("%s://%s.%s.svc.cluster.local", f.tls.scheme(), f.serviceName, f.systemNamespace)
+	caBundle, err := f.tls.caBundle()
+	if err != nil {
+		return errors.Wrap(err, "while getting CA bundle")
+	}
 
-	err := wait.PollImmediate(time.Second, time.Second*30, func() (bool, error) {
-		_, err := f.createServiceBroker(svcURL)
+	err = wait.PollImmediate(time.Second, time.Second*30, func() (bool, error) {
+		if f.auth.enabled() {
+			if err := ensureNamespaceCredentials(f.client, f.auth, f.systemNamespace, f.namespace); err != nil {
+				f.log.Errorf("creation of the credentials for ServiceBroker %s results in error: [%s]", NamespacedBrokerName, err)
+				return false, nil
+			}
+		}
+		_, err := f.createServiceBroker(svcURL, caBundle)
 		if err != nil && !k8serrors.IsAlreadyExists(err) {
 			f.log.Errorf("creation of ServiceBroker %s results in error: [%s]", NamespacedBrokerName, err)
 			return false, nil
@@ -71,7 +91,7 @@ func (f *Facade) Create() error {
 }
 
 // createServiceBroker returns just created or existing ServiceBroker
-func (f *Facade) createServiceBroker(svcURL string) (*v1beta1.ServiceBroker, error) {
+func (f *Facade) createServiceBroker(svcURL string, caBundle []byte) (*v1beta1.ServiceBroker, error) {
 	url := fmt.Sprintf("%s/ns/%s", svcURL, f.namespace)
 	broker := &v1beta1.ServiceBroker{
 		ObjectMeta: metav1.ObjectMeta{
@@ -84,8 +104,10 @@ func (f *Facade) createServiceBroker(svcURL string) (*v1beta1.ServiceBroker, err
 		Spec: v1beta1.ServiceBrokerSpec{
 			CommonServiceBrokerSpec: v1beta1.CommonServiceBrokerSpec{
 				URL:            url,
+				CABundle:       caBundle,
 				RelistRequests: 1,
 			},
+			AuthInfo: f.auth.authInfo(),
 		},
 	}
 
@@ -94,6 +116,14 @@ func (f *Facade) createServiceBroker(svcURL string) (*v1beta1.ServiceBroker, err
 		f.log.Infof("ServiceBroker for namespace [%s] already exist. Attempt to get resource.", f.namespace)
 		result := &v1beta1.ServiceBroker{}
 		err := f.client.Get(context.Background(), types.NamespacedName{Namespace: f.namespace, Name: NamespacedBrokerName}, result)
+		if err != nil || (result.Spec.URL == url && bytes.Equal(result.Spec.CABundle, caBundle) && reflect.DeepEqual(result.Spec.AuthInfo, broker.Spec.AuthInfo)) {
+			return result, err
+		}
+		// the broker registered before the TLS or authentication configuration was changed must use the current one
+		result.Spec.URL = url
+		result.Spec.CABundle = caBundle
+		result.Spec.AuthInfo = broker.Spec.AuthInfo
+		err = f.client.Update(context.Background(), result)
 		return result, err
 	}
 
@@ -109,6 +139,9 @@ func (f *Facade) Delete() error {
 		},
 	}
 	f.log.Infof("- deleting ServiceBroker %s/%s", NamespacedBrokerName, f.namespace)
+	if f.auth.enabled() {
+		f.deleteNamespaceCredentials()
+	}
 	err := f.client.Delete(context.Background(), sb)
 	switch {
 	case k8serrors.IsNotFound(err):
@@ -120,6 +153,36 @@ func (f *Facade) Delete() error {
 
 }
 
+// deleteNamespaceCredentials removes the Secret and the ServiceAccount with credentials issued for the namespace of the ServiceBroker.
+// Objects which were not created by the broker are left untouched.
+func (f *Facade) deleteNamespaceCredentials() {
+	key := types.NamespacedName{Namespace: f.namespace, Name: NamespacedBrokerAuthSecretName}
+	f.deleteIfManagedByBroker(key, &v1.Secret{})
+	key.Name = nsauth.ServiceAccountName
+	f.deleteIfManagedByBroker(key, &v1.ServiceAccount{})
+}
+
+func (f *Facade) deleteIfManagedByBroker(key types.NamespacedName, obj interface {
+	runtime.Object
+	metav1.Object
+}) {
+	err := f.client.Get(context.Background(), key, obj)
+	switch {
+	case k8serrors.IsNotFound(err):
+		return
+	case err != nil:
+		f.log.Warnf("Getting of the auth object %s for namespace [%s] results in error: [%s].", key.Name, f.namespace, err)
+		return
+	case !isManagedByBroker(obj.GetLabels()):
+		f.log.Infof("Auth object %s in namespace [%s] is not managed by the broker, skipping deletion.", key.Name, f.namespace)
+		return
+	}
+
+	if err := f.client.Delete(context.Background(), obj); err != nil && !k8serrors.IsNotFound(err) {
+		f.log.Warnf("Deletion of the auth object %s for namespace [%s] results in error: [%s].", key.Name, f.namespace, err)
+	}
+}
+
 // Exist check if ServiceBroker exists.
 func (f *Facade) Exist() (bool, error) {
 	err := f.client.Get(context.Background(), types.NamespacedName{Namespace: f.namespace, Name: NamespacedBrokerName}, &v1beta1.ServiceBroker{})
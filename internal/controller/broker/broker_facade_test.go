@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -18,6 +19,8 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	k8s_testing "k8s.io/client-go/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kyma-project/helm-broker/internal/platform/nsauth"
 )
 
 func TestServiceBrokerCreateHappyPath(t *testing.T) {
@@ -26,7 +29,7 @@ func TestServiceBrokerCreateHappyPath(t *testing.T) {
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
 
 	svcURL := fmt.Sprintf("http://%s.%s.svc.cluster.local/ns/%s", fixService(), fixWorkingNs(), "stage")
-	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), logrus.New())
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	sut.SetNamespace(fixDestNs())
 	err := sut.Create()
@@ -43,12 +46,180 @@ func TestServiceBrokerCreateHappyPath(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceBrokerCreateWithAuth(t *testing.T) {
+	// GIVEN
+	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "hb-auth",
+			Namespace: fixWorkingNs(),
+		},
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("pass"),
+		},
+	})
+
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{Type: AuthTypeBasic, SecretName: "hb-auth"}, TLSConfig{}, logrus.New())
+	// WHEN
+	sut.SetNamespace(fixDestNs())
+	err := sut.Create()
+
+	// THEN
+	require.NoError(t, err)
+
+	actualBroker := &v1beta1.ServiceBroker{}
+	err = cli.Get(context.Background(), types.NamespacedName{Name: fixBrokerName(), Namespace: fixDestNs()}, actualBroker)
+	require.NoError(t, err)
+	require.NotNil(t, actualBroker.Spec.AuthInfo)
+	assert.Equal(t, &v1beta1.LocalObjectReference{Name: NamespacedBrokerAuthSecretName}, actualBroker.Spec.AuthInfo.Basic.SecretRef)
+
+	// the namespace gets its own credentials, not the copy of the broker credentials
+	expUsername, expPassword := nsauth.BasicCredentials([]byte("admin"), []byte("pass"), fixDestNs())
+	nsSecret := &v1.Secret{}
+	err = cli.Get(context.Background(), types.NamespacedName{Name: NamespacedBrokerAuthSecretName, Namespace: fixDestNs()}, nsSecret)
+	require.NoError(t, err)
+	assert.Equal(t, expUsername, nsSecret.Data["username"])
+	assert.Equal(t, expPassword, nsSecret.Data["password"])
+	assert.NotEqual(t, []byte("pass"), nsSecret.Data["password"])
+	assert.Equal(t, BrokerLabelValue, nsSecret.Labels[BrokerLabelKey])
+}
+
+func TestServiceBrokerCreateWithBearerAuth(t *testing.T) {
+	// GIVEN
+	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{Type: AuthTypeBearer, SecretName: "hb-auth"}, TLSConfig{}, logrus.New())
+	// WHEN
+	sut.SetNamespace(fixDestNs())
+	err := sut.Create()
+
+	// THEN
+	require.NoError(t, err)
+
+	actualBroker := &v1beta1.ServiceBroker{}
+	err = cli.Get(context.Background(), types.NamespacedName{Name: fixBrokerName(), Namespace: fixDestNs()}, actualBroker)
+	require.NoError(t, err)
+	require.NotNil(t, actualBroker.Spec.AuthInfo)
+	assert.Equal(t, &v1beta1.LocalObjectReference{Name: NamespacedBrokerAuthSecretName}, actualBroker.Spec.AuthInfo.Bearer.SecretRef)
+
+	sa := &v1.ServiceAccount{}
+	err = cli.Get(context.Background(), types.NamespacedName{Name: nsauth.ServiceAccountName, Namespace: fixDestNs()}, sa)
+	require.NoError(t, err)
+	assert.Equal(t, BrokerLabelValue, sa.Labels[BrokerLabelKey])
+
+	tokenSecret := &v1.Secret{}
+	err = cli.Get(context.Background(), types.NamespacedName{Name: NamespacedBrokerAuthSecretName, Namespace: fixDestNs()}, tokenSecret)
+	require.NoError(t, err)
+	assert.Equal(t, v1.SecretTypeServiceAccountToken, tokenSecret.Type)
+	assert.Equal(t, nsauth.ServiceAccountName, tokenSecret.Annotations[v1.ServiceAccountNameKey])
+}
+
+func TestEnsureNamespaceCredentialsDoesNotOverwriteNotManagedSecret(t *testing.T) {
+	// GIVEN
+	userSecret := &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      NamespacedBrokerAuthSecretName,
+			Namespace: fixDestNs(),
+		},
+		Data: map[string][]byte{"username": []byte("user-data")},
+	}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, fixBrokerAuthSecret(), userSecret)
+
+	// WHEN
+	err := ensureNamespaceCredentials(cli, AuthConfig{Type: AuthTypeBasic, SecretName: "hb-auth"}, fixWorkingNs(), fixDestNs())
+
+	// THEN
+	assert.Error(t, err)
+	actual := &v1.Secret{}
+	require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Name: NamespacedBrokerAuthSecretName, Namespace: fixDestNs()}, actual))
+	assert.Equal(t, []byte("user-data"), actual.Data["username"])
+}
+
+func TestEnsureNamespaceCredentialsUpdatesManagedSecret(t *testing.T) {
+	// GIVEN
+	staleSecret := &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      NamespacedBrokerAuthSecretName,
+			Namespace: fixDestNs(),
+			Labels:    map[string]string{BrokerLabelKey: BrokerLabelValue},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{"username": []byte("admin"), "password": []byte("pass")},
+	}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, fixBrokerAuthSecret(), staleSecret)
+
+	// WHEN
+	err := ensureNamespaceCredentials(cli, AuthConfig{Type: AuthTypeBasic, SecretName: "hb-auth"}, fixWorkingNs(), fixDestNs())
+
+	// THEN
+	require.NoError(t, err)
+	_, expPassword := nsauth.BasicCredentials([]byte("admin"), []byte("pass"), fixDestNs())
+	actual := &v1.Secret{}
+	require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Name: NamespacedBrokerAuthSecretName, Namespace: fixDestNs()}, actual))
+	assert.Equal(t, expPassword, actual.Data["password"])
+}
+
+func TestServiceBrokerDeleteRemovesAuthSecret(t *testing.T) {
+	// GIVEN
+	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      NamespacedBrokerAuthSecretName,
+			Namespace: fixDestNs(),
+			Labels:    map[string]string{BrokerLabelKey: BrokerLabelValue},
+		},
+	}, &v1beta1.ServiceBroker{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      fixBrokerName(),
+			Namespace: fixDestNs(),
+		},
+	})
+
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{Type: AuthTypeBasic, SecretName: "hb-auth"}, TLSConfig{}, logrus.New())
+	// WHEN
+	sut.SetNamespace(fixDestNs())
+	err := sut.Delete()
+
+	// THEN
+	require.NoError(t, err)
+	err = cli.Get(context.Background(), types.NamespacedName{Name: NamespacedBrokerAuthSecretName, Namespace: fixDestNs()}, &v1.Secret{})
+	assert.True(t, k8s_errors.IsNotFound(err))
+}
+
+func TestServiceBrokerDeleteKeepsNotManagedAuthSecret(t *testing.T) {
+	// GIVEN
+	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      NamespacedBrokerAuthSecretName,
+			Namespace: fixDestNs(),
+		},
+	}, &v1beta1.ServiceBroker{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      fixBrokerName(),
+			Namespace: fixDestNs(),
+		},
+	})
+
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{Type: AuthTypeBasic, SecretName: "hb-auth"}, TLSConfig{}, logrus.New())
+	// WHEN
+	sut.SetNamespace(fixDestNs())
+	err := sut.Delete()
+
+	// THEN
+	require.NoError(t, err)
+	err = cli.Get(context.Background(), types.NamespacedName{Name: NamespacedBrokerAuthSecretName, Namespace: fixDestNs()}, &v1.Secret{})
+	assert.NoError(t, err)
+}
+
 func TestServiceBrokerDeleteHappyPath(t *testing.T) {
 	// GIVEN
 	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
 
-	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), logrus.New())
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	sut.SetNamespace(fixDestNs())
 	err := sut.Delete()
@@ -61,7 +232,7 @@ func TestServiceBrokerDeleteNotFoundErrorsIgnored(t *testing.T) {
 	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
 
-	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), logrus.New())
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	sut.SetNamespace(fixDestNs())
 	err := sut.Delete()
@@ -74,7 +245,7 @@ func TestServiceBrokerDoesNotExist(t *testing.T) {
 	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
 
-	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), logrus.New())
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	sut.SetNamespace(fixDestNs())
 	ex, err := sut.Exist()
@@ -92,7 +263,7 @@ func TestServiceBrokerExist(t *testing.T) {
 			Namespace: fixDestNs(),
 		}})
 
-	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), logrus.New())
+	sut := NewBrokersFacade(cli, fixWorkingNs(), fixService(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	sut.SetNamespace(fixDestNs())
 	ex, err := sut.Exist()
@@ -101,6 +272,19 @@ func TestServiceBrokerExist(t *testing.T) {
 	assert.True(t, ex)
 }
 
+func fixBrokerAuthSecret() *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "hb-auth",
+			Namespace: fixWorkingNs(),
+		},
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("pass"),
+		},
+	}
+}
+
 func fixDestNs() string {
 	return "stage"
 }
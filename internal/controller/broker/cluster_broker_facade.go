@@ -1,7 +1,9 @@
 package broker
 
 import (
+	"bytes"
 	"fmt"
+	"reflect"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -28,17 +30,21 @@ type ClusterFacade struct {
 	serviceName       string
 	log               logrus.FieldLogger
 	clusterBrokerName string
+	auth              AuthConfig
+	tls               TLSConfig
 
 	clusterBrokerSyncer clusterBrokerSyncer
 }
 
 // NewClusterBrokersFacade returns facade
-func NewClusterBrokersFacade(client client.Client, workingNamespace, serviceName, clusterBrokerName string, log logrus.FieldLogger) *ClusterFacade {
+func NewClusterBrokersFacade(client client.Client, workingNamespace, serviceName, clusterBrokerName string, auth AuthConfig, tls TLSConfig, log logrus.FieldLogger) *ClusterFacade {
 	return &ClusterFacade{
 		client:            client,
 		workingNamespace:  workingNamespace,
 		clusterBrokerName: clusterBrokerName,
 		serviceName:       serviceName,
+		auth:              auth,
+		tls:               tls,
 		log:               log.WithField("service", "cluster-broker-facade"),
 	}
 }
@@ -46,10 +52,14 @@ func NewClusterBrokersFacade(client client.Client, workingNamespace, serviceName
 // Create creates ClusterServiceBroker
 func (f *ClusterFacade) Create() error {
 	f.log.Infof("- creating ClusterServiceBroker %s", f.clusterBrokerName)
-	svcURL := fmt.Sprintf("http://%s.%s.svc.cluster.local", f.serviceName, f.workingNamespace)
+	svcURL := fmt.Sprintf("%s://%s.%s.svc.cluster.local", f.tls.scheme(), f.serviceName, f.workingNamespace)
+	caBundle, err := f.tls.caBundle()
+	if err != nil {
+		return errors.Wrap(err, "while getting CA bundle")
+	}
 
-	err := wait.PollImmediate(time.Second, time.Second*30, func() (bool, error) {
-		_, err := f.createClusterServiceBroker(svcURL)
+	err = wait.PollImmediate(time.Second, time.Second*30, func() (bool, error) {
+		_, err := f.createClusterServiceBroker(svcURL, caBundle)
 		if err != nil && !k8serrors.IsAlreadyExists(err) {
 			f.log.Errorf("creation of ClusterServiceBroker %s results in error: [%s]", f.clusterBrokerName, err)
 			return false, nil
@@ -95,7 +105,7 @@ func (f *ClusterFacade) Exist() (bool, error) {
 }
 
 // createServiceBroker returns just created or existing ClusterServiceBroker
-func (f *ClusterFacade) createClusterServiceBroker(svcURL string) (*v1beta1.ClusterServiceBroker, error) {
+func (f *ClusterFacade) createClusterServiceBroker(svcURL string, caBundle []byte) (*v1beta1.ClusterServiceBroker, error) {
 	url := fmt.Sprintf("%s/cluster", svcURL)
 	broker := &v1beta1.ClusterServiceBroker{
 		ObjectMeta: metav1.ObjectMeta{
@@ -104,8 +114,10 @@ func (f *ClusterFacade) createClusterServiceBroker(svcURL string) (*v1beta1.Clus
 		Spec: v1beta1.ClusterServiceBrokerSpec{
 			CommonServiceBrokerSpec: v1beta1.CommonServiceBrokerSpec{
 				URL:            url,
+				CABundle:       caBundle,
 				RelistRequests: 1,
 			},
+			AuthInfo: f.auth.clusterAuthInfo(f.workingNamespace),
 		},
 	}
 
@@ -114,6 +126,14 @@ func (f *ClusterFacade) createClusterServiceBroker(svcURL string) (*v1beta1.Clus
 		f.log.Infof("ClusterServiceBroker [%s] already exist. Attempt to get resource.", broker.Name)
 		createdBroker := &v1beta1.ClusterServiceBroker{}
 		err = f.client.Get(context.Background(), types.NamespacedName{Name: f.clusterBrokerName}, createdBroker)
+		if err != nil || (createdBroker.Spec.URL == url && bytes.Equal(createdBroker.Spec.CABundle, caBundle) && reflect.DeepEqual(createdBroker.Spec.AuthInfo, broker.Spec.AuthInfo)) {
+			return createdBroker, err
+		}
+		// the broker registered before the TLS or authentication configuration was changed must use the current one
+		createdBroker.Spec.URL = url
+		createdBroker.Spec.CABundle = caBundle
+		createdBroker.Spec.AuthInfo = broker.Spec.AuthInfo
+		err = f.client.Update(context.Background(), createdBroker)
 		return createdBroker, err
 	}
 
@@ -2,6 +2,8 @@ package broker
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"context"
@@ -22,7 +24,7 @@ func TestClusterServiceBrokerCreateHappyPath(t *testing.T) {
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
 
 	svcURL := fmt.Sprintf("http://%s.%s.svc.cluster.local/cluster", fixService(), fixWorkingNs())
-	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), logrus.New())
+	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	err := sut.Create()
 
@@ -37,12 +39,91 @@ func TestClusterServiceBrokerCreateHappyPath(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestClusterServiceBrokerCreateWithAuth(t *testing.T) {
+	// GIVEN
+	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), AuthConfig{Type: AuthTypeBearer, SecretName: "hb-auth"}, TLSConfig{}, logrus.New())
+	// WHEN
+	err := sut.Create()
+
+	// THEN
+	require.NoError(t, err)
+
+	sb := &v1beta1.ClusterServiceBroker{}
+	err = cli.Get(context.Background(), types.NamespacedName{Name: fixBrokerName()}, sb)
+	require.NoError(t, err)
+	require.NotNil(t, sb.Spec.AuthInfo)
+	assert.Nil(t, sb.Spec.AuthInfo.Basic)
+	assert.Equal(t, &v1beta1.ObjectReference{Namespace: fixWorkingNs(), Name: "hb-auth"}, sb.Spec.AuthInfo.Bearer.SecretRef)
+}
+
+func TestClusterServiceBrokerCreateUpdatesAuthOfExistingBroker(t *testing.T) {
+	// GIVEN
+	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, &v1beta1.ClusterServiceBroker{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: fixBrokerName(),
+		},
+	})
+
+	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), AuthConfig{Type: AuthTypeBasic, SecretName: "hb-auth"}, TLSConfig{}, logrus.New())
+	// WHEN
+	err := sut.Create()
+
+	// THEN
+	require.NoError(t, err)
+
+	sb := &v1beta1.ClusterServiceBroker{}
+	err = cli.Get(context.Background(), types.NamespacedName{Name: fixBrokerName()}, sb)
+	require.NoError(t, err)
+	require.NotNil(t, sb.Spec.AuthInfo)
+	assert.Equal(t, &v1beta1.ObjectReference{Namespace: fixWorkingNs(), Name: "hb-auth"}, sb.Spec.AuthInfo.Basic.SecretRef)
+}
+
+func TestClusterServiceBrokerCreateWithTLS(t *testing.T) {
+	// GIVEN
+	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, &v1beta1.ClusterServiceBroker{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: fixBrokerName(),
+		},
+		Spec: v1beta1.ClusterServiceBrokerSpec{
+			CommonServiceBrokerSpec: v1beta1.CommonServiceBrokerSpec{
+				URL: fmt.Sprintf("http://%s.%s.svc.cluster.local/cluster", fixService(), fixWorkingNs()),
+			},
+		},
+	})
+
+	caBundleFile, err := ioutil.TempFile("", "ca")
+	require.NoError(t, err)
+	defer os.Remove(caBundleFile.Name())
+	_, err = caBundleFile.WriteString("ca-bundle")
+	require.NoError(t, err)
+	require.NoError(t, caBundleFile.Close())
+
+	svcURL := fmt.Sprintf("https://%s.%s.svc.cluster.local/cluster", fixService(), fixWorkingNs())
+	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), AuthConfig{}, TLSConfig{Enabled: true, CABundleFile: caBundleFile.Name()}, logrus.New())
+	// WHEN
+	err = sut.Create()
+
+	// THEN
+	require.NoError(t, err)
+
+	sb := &v1beta1.ClusterServiceBroker{}
+	err = cli.Get(context.Background(), types.NamespacedName{Name: fixBrokerName()}, sb)
+	require.NoError(t, err)
+	assert.Equal(t, svcURL, sb.Spec.URL)
+	assert.Equal(t, []byte("ca-bundle"), sb.Spec.CABundle)
+}
+
 func TestClusterServiceBrokerDeleteHappyPath(t *testing.T) {
 	// GIVEN
 	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
 
-	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), logrus.New())
+	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	err := sut.Delete()
 	// THEN
@@ -54,7 +135,7 @@ func TestClusterServiceBrokerDeleteNotFoundErrorsIgnored(t *testing.T) {
 	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
 
-	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), logrus.New())
+	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	err := sut.Delete()
 	// THEN
@@ -66,7 +147,7 @@ func TestClusterServiceBrokerDoesNotExist(t *testing.T) {
 	require.NoError(t, v1beta1.AddToScheme(scheme.Scheme))
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme)
 
-	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), logrus.New())
+	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	ex, err := sut.Exist()
 	// THEN
@@ -82,7 +163,7 @@ func TestClusterServiceBrokerExist(t *testing.T) {
 			Name: fixBrokerName(),
 		}})
 
-	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), logrus.New())
+	sut := NewClusterBrokersFacade(cli, fixWorkingNs(), fixService(), fixBrokerName(), AuthConfig{}, TLSConfig{}, logrus.New())
 	// WHEN
 	ex, err := sut.Exist()
 	// THEN
@@ -0,0 +1,35 @@
+package broker
+
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// TLSConfig defines how Service Catalog connects to the broker served over TLS
+type TLSConfig struct {
+	// Enabled registers brokers with the https URL
+	Enabled bool
+	// CABundleFile defines the path to the PEM encoded CA bundle which Service Catalog uses to verify the broker certificate.
+	// The system trust store is used when it is not set.
+	CABundleFile string
+}
+
+func (c TLSConfig) scheme() string {
+	if c.Enabled {
+		return "https"
+	}
+	return "http"
+}
+
+// caBundle reads the CA bundle on every call, so the rotated bundle is used for brokers registered later
+func (c TLSConfig) caBundle() ([]byte, error) {
+	if !c.Enabled || c.CABundleFile == "" {
+		return nil, nil
+	}
+	caBundle, err := ioutil.ReadFile(c.CABundleFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while reading CA bundle %s", c.CABundleFile)
+	}
+	return caBundle, nil
+}
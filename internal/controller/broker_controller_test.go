@@ -129,7 +129,7 @@ func prepareBrokerController(t *testing.T, initObjs ...runtime.Object) (*control
 	require.NoError(t, v1alpha1.AddToScheme(scheme.Scheme))
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme, initObjs...)
 	iChecker := instance.New(cli, broker.NamespacedBrokerName)
-	bFacade := broker.NewBrokersFacade(cli, "default", broker.NamespacedBrokerName, spy.NewLogDummy())
+	bFacade := broker.NewBrokersFacade(cli, "default", broker.NamespacedBrokerName, broker.AuthConfig{}, broker.TLSConfig{}, spy.NewLogDummy())
 	svc := controller.NewBrokerController(iChecker, cli, bFacade)
 
 	return svc, cli
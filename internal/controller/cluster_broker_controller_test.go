@@ -134,7 +134,7 @@ func prepareClusterBrokerController(t *testing.T, initObjs ...runtime.Object) (*
 	require.NoError(t, v1alpha1.AddToScheme(scheme.Scheme))
 	cli := fake.NewFakeClientWithScheme(scheme.Scheme, initObjs...)
 	iChecker := instance.New(cli, broker.NamespacedBrokerName)
-	bFacade := broker.NewClusterBrokersFacade(cli, "default", "helm-broker", broker.NamespacedBrokerName, spy.NewLogDummy())
+	bFacade := broker.NewClusterBrokersFacade(cli, "default", "helm-broker", broker.NamespacedBrokerName, broker.AuthConfig{}, broker.TLSConfig{}, spy.NewLogDummy())
 	svc := controller.NewClusterBrokerController(iChecker, cli, bFacade, broker.NamespacedBrokerName)
 
 	return svc, cli
@@ -56,8 +56,8 @@ func SetupAndStartController(cfg *rest.Config, ctrCfg *config.ControllerConfig,
 	}
 	sbSyncer := broker.NewBrokerSyncer(mgr.GetClient(), lg)
 	csbSyncer := broker.NewClusterBrokerSyncer(mgr.GetClient(), ctrCfg.ClusterServiceBrokerName, lg)
-	sbFacade := broker.NewBrokersFacade(mgr.GetClient(), ctrCfg.Namespace, ctrCfg.ServiceName, lg)
-	csbFacade := broker.NewClusterBrokersFacade(mgr.GetClient(), ctrCfg.Namespace, ctrCfg.ServiceName, ctrCfg.ClusterServiceBrokerName, lg)
+	sbFacade := broker.NewBrokersFacade(mgr.GetClient(), ctrCfg.Namespace, ctrCfg.ServiceName, ctrCfg.BrokerAuth, ctrCfg.BrokerTLS, lg)
+	csbFacade := broker.NewClusterBrokersFacade(mgr.GetClient(), ctrCfg.Namespace, ctrCfg.ServiceName, ctrCfg.ClusterServiceBrokerName, ctrCfg.BrokerAuth, ctrCfg.BrokerTLS, lg)
 
 	templateService := repository.NewTemplate(mgr.GetClient())
 
@@ -97,7 +97,7 @@ func SetupAndStartController(cfg *rest.Config, ctrCfg *config.ControllerConfig,
 	err = cacController.Start(mgr)
 	fatalOnError(err, "unable to start ClusterAddonsConfigurationController")
 
-	bController := NewBrokerController(instChecker, mgr.GetClient(), broker.NewBrokersFacade(mgr.GetClient(), ctrCfg.Namespace, ctrCfg.ServiceName, lg))
+	bController := NewBrokerController(instChecker, mgr.GetClient(), broker.NewBrokersFacade(mgr.GetClient(), ctrCfg.Namespace, ctrCfg.ServiceName, ctrCfg.BrokerAuth, ctrCfg.BrokerTLS, lg))
 	err = bController.Start(mgr)
 	fatalOnError(err, "unable to start BrokerController")
 
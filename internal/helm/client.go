@@ -1,6 +1,9 @@
 package helm
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kyma-project/helm-broker/internal"
@@ -9,6 +12,7 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/rest"
 )
@@ -34,7 +38,8 @@ func NewClient(restConfig *rest.Config, helmDriver string, log logrus.FieldLogge
 	}, nil
 }
 
-func (c *Client) Install(chrt *chart.Chart, values internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace) (*release.Release, error) {
+// Install installs the release of the chart with the given values and helm options
+func (c *Client) Install(chrt *chart.Chart, values internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) (*release.Release, error) {
 	c.log.Infof("Installing chart with release name [%s], namespace: [%s]", releaseName, namespace)
 
 	ns := string(namespace)
@@ -46,8 +51,12 @@ func (c *Client) Install(chrt *chart.Chart, values internal.ChartValues, release
 	installAction := action.NewInstall(cfg)
 	installAction.ReleaseName = string(releaseName)
 	installAction.Namespace = ns
-	installAction.Wait = true
-	installAction.Timeout = c.installingTimeout
+	installAction.Wait = opts.IsWait()
+	installAction.WaitForJobs = opts.WaitForJobs
+	installAction.Timeout = c.timeout(opts)
+	installAction.Atomic = opts.Atomic
+	installAction.DisableHooks = opts.DisableHooks
+	installAction.SkipCRDs = opts.SkipCRDs
 	installAction.CreateNamespace = true // https://v3.helm.sh/docs/faq/#automatically-creating-namespaces
 
 	release, err := installAction.Run(chrt, values)
@@ -58,8 +67,112 @@ func (c *Client) Install(chrt *chart.Chart, values internal.ChartValues, release
 	return release, nil
 }
 
-// Delete is deleting release of the chart
-func (c *Client) Delete(releaseName internal.ReleaseName, namespace internal.Namespace) error {
+// Upgrade is upgrading already installed release with the given chart, values and helm options
+func (c *Client) Upgrade(chrt *chart.Chart, values internal.ChartValues, releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) (*release.Release, error) {
+	c.log.Infof("Upgrading chart with release name [%s], namespace: [%s]", releaseName, namespace)
+
+	ns := string(namespace)
+	cfg, err := c.getConfig(ns)
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting config")
+	}
+
+	upgradeAction := action.NewUpgrade(cfg)
+	upgradeAction.Namespace = ns
+	upgradeAction.Wait = opts.IsWait()
+	upgradeAction.WaitForJobs = opts.WaitForJobs
+	upgradeAction.Timeout = c.timeout(opts)
+	upgradeAction.Atomic = opts.Atomic
+	upgradeAction.DisableHooks = opts.DisableHooks
+	upgradeAction.SkipCRDs = opts.SkipCRDs
+
+	release, err := upgradeAction.Run(string(releaseName), chrt, values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while upgrading release from chart with name [%s] in namespace [%s]", releaseName, namespace)
+	}
+
+	return release, nil
+}
+
+// testLogsTailLines is the number of the last log lines of every failed test pod put in the tests failure summary
+const testLogsTailLines int64 = 10
+
+// RunTests runs the test hooks of the release with the timeout from the helm options. When the tests fail,
+// the returned error contains the summary of logs of the failed test pods.
+func (c *Client) RunTests(releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) error {
+	c.log.Infof("Running tests of release [%s], namespace: [%s]", releaseName, namespace)
+
+	ns := string(namespace)
+	cfg, err := c.getConfig(ns)
+	if err != nil {
+		return errors.Wrap(err, "while getting config")
+	}
+
+	testAction := action.NewReleaseTesting(cfg)
+	testAction.Namespace = ns
+	testAction.Timeout = c.timeout(opts)
+
+	rel, err := testAction.Run(string(releaseName))
+	if err == nil {
+		return nil
+	}
+	if rel == nil {
+		return errors.Wrapf(err, "while running tests of release [%s] in namespace [%s]", releaseName, namespace)
+	}
+
+	summary := summarizeFailedTests(rel.Hooks, func(podName string) (string, error) {
+		return podLogs(cfg, ns, podName)
+	})
+	return errors.Errorf("tests of release [%s] in namespace [%s] failed: %v; %s", releaseName, namespace, err, summary)
+}
+
+// summarizeFailedTests describes the failed test hooks with the last lines of logs of their pods
+func summarizeFailedTests(hooks []*release.Hook, podLogs func(podName string) (string, error)) string {
+	var failed []string
+	for _, h := range hooks {
+		if !isTestHook(h) || h.LastRun.Phase != release.HookPhaseFailed {
+			continue
+		}
+
+		logs, err := podLogs(h.Name)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("test pod %s failed, cannot get its logs: %v", h.Name, err))
+			continue
+		}
+		failed = append(failed, fmt.Sprintf("test pod %s failed with logs: %s", h.Name, strings.TrimSpace(logs)))
+	}
+
+	if len(failed) == 0 {
+		return "no failed test pods found"
+	}
+	return strings.Join(failed, "; ")
+}
+
+func isTestHook(h *release.Hook) bool {
+	for _, e := range h.Events {
+		if e == release.HookTest {
+			return true
+		}
+	}
+	return false
+}
+
+func podLogs(cfg *action.Configuration, namespace, podName string) (string, error) {
+	cs, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return "", errors.Wrap(err, "while getting kubernetes client")
+	}
+
+	tailLines := testLogsTailLines
+	logs, err := cs.CoreV1().Pods(namespace).GetLogs(podName, &v1.PodLogOptions{TailLines: &tailLines}).DoRaw(context.Background())
+	if err != nil {
+		return "", errors.Wrapf(err, "while getting logs of pod [%s]", podName)
+	}
+	return string(logs), nil
+}
+
+// Delete is deleting release of the chart, only the timeout and hooks helm options are applied
+func (c *Client) Delete(releaseName internal.ReleaseName, namespace internal.Namespace, opts internal.HelmOptions) error {
 	c.log.Infof("Deleting chart with release name [%s], namespace: [%s]", releaseName, namespace)
 	cfg, err := c.getConfig(string(namespace))
 	if err != nil {
@@ -67,6 +180,8 @@ func (c *Client) Delete(releaseName internal.ReleaseName, namespace internal.Nam
 	}
 
 	uninstallAction := action.NewUninstall(cfg)
+	uninstallAction.DisableHooks = opts.DisableHooks
+	uninstallAction.Timeout = opts.Timeout
 	_, err = uninstallAction.Run(string(releaseName))
 	if err != nil {
 		return errors.Wrap(err, "while executing uninstall action")
@@ -75,6 +190,47 @@ func (c *Client) Delete(releaseName internal.ReleaseName, namespace internal.Nam
 	return err
 }
 
+// GetRelease returns the last revision of the release. If the release does not exist,
+// the returned error wraps the driver.ErrReleaseNotFound error.
+func (c *Client) GetRelease(releaseName internal.ReleaseName, namespace internal.Namespace) (*release.Release, error) {
+	cfg, err := c.getConfig(string(namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting config")
+	}
+
+	rel, err := action.NewGet(cfg).Run(string(releaseName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "while getting release [%s] in namespace [%s]", releaseName, namespace)
+	}
+
+	return rel, nil
+}
+
+// ReleaseHealth checks the readiness of the resources of the last revision of the release. If the release does not exist,
+// the returned error wraps the driver.ErrReleaseNotFound error.
+func (c *Client) ReleaseHealth(releaseName internal.ReleaseName, namespace internal.Namespace) (internal.ReleaseHealth, error) {
+	cfg, err := c.getConfig(string(namespace))
+	if err != nil {
+		return internal.ReleaseHealth{}, errors.Wrap(err, "while getting config")
+	}
+
+	rel, err := action.NewGet(cfg).Run(string(releaseName))
+	if err != nil {
+		return internal.ReleaseHealth{}, errors.Wrapf(err, "while getting release [%s] in namespace [%s]", releaseName, namespace)
+	}
+
+	cs, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return internal.ReleaseHealth{}, errors.Wrap(err, "while getting kubernetes client")
+	}
+
+	health, err := NewHealthEvaluator(cs).Evaluate(rel.Manifest, namespace)
+	if err != nil {
+		return internal.ReleaseHealth{}, errors.Wrapf(err, "while evaluating health of release [%s] in namespace [%s]", releaseName, namespace)
+	}
+	return health, nil
+}
+
 // ListReleases returns a list of helm releases in the given namespace
 func (c *Client) ListReleases(namespace internal.Namespace) ([]*release.Release, error) {
 	cfg, err := c.getConfig(string(namespace))
@@ -85,6 +241,14 @@ func (c *Client) ListReleases(namespace internal.Namespace) ([]*release.Release,
 	return listAction.Run()
 }
 
+// timeout returns the timeout from the helm options, the installing timeout is used when it is not set
+func (c *Client) timeout(opts internal.HelmOptions) time.Duration {
+	if opts.Timeout > 0 {
+		return opts.Timeout
+	}
+	return c.installingTimeout
+}
+
 func (c *Client) getConfig(namespace string) (*action.Configuration, error) {
 	actionConfig := new(action.Configuration)
 	// You can pass an empty string to all namespaces
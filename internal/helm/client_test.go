@@ -2,13 +2,17 @@ package helm_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/kyma-project/helm-broker/internal"
 	"github.com/kyma-project/helm-broker/internal/helm"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -32,7 +36,7 @@ func TestInstallListDelete(t *testing.T) {
 	_, err = svc.Install(chrt, map[string]interface{}{
 		"planName":       "micro",
 		"additionalData": "abc",
-	}, "nice-alpaca", "playground")
+	}, "nice-alpaca", "playground", internal.HelmOptions{})
 	require.NoError(t, err)
 
 	// then
@@ -50,11 +54,39 @@ func TestInstallListDelete(t *testing.T) {
 	assert.Len(t, rels, 1)
 	assert.Equal(t, "nice-alpaca", rels[0].Name)
 
+	rel, err := svc.GetRelease("nice-alpaca", "playground")
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+
 	// delete
-	err = svc.Delete("nice-alpaca", "playground")
+	err = svc.Delete("nice-alpaca", "playground", internal.HelmOptions{})
 	require.NoError(t, err)
 	rels, err = svc.ListReleases("playground")
 	require.NoError(t, err)
 	assert.Len(t, rels, 0)
+	_, err = svc.GetRelease("nice-alpaca", "playground")
+	assert.True(t, errors.Is(err, driver.ErrReleaseNotFound))
 
 }
+
+func TestSummarizeFailedTests(t *testing.T) {
+	// given
+	hooks := []*release.Hook{
+		{Name: "passed-test", Events: []release.HookEvent{release.HookTest}, LastRun: release.HookExecution{Phase: release.HookPhaseSucceeded}},
+		{Name: "failed-test", Events: []release.HookEvent{release.HookTest}, LastRun: release.HookExecution{Phase: release.HookPhaseFailed}},
+		{Name: "failed-install-hook", Events: []release.HookEvent{release.HookPostInstall}, LastRun: release.HookExecution{Phase: release.HookPhaseFailed}},
+		{Name: "failed-test-without-logs", Events: []release.HookEvent{release.HookTest}, LastRun: release.HookExecution{Phase: release.HookPhaseFailed}},
+	}
+	podLogs := func(podName string) (string, error) {
+		if podName == "failed-test-without-logs" {
+			return "", errors.New("pod not found")
+		}
+		return "connection refused\n", nil
+	}
+
+	// when
+	summary := helm.SummarizeFailedTests(hooks, podLogs)
+
+	// then
+	assert.Equal(t, "test pod failed-test failed with logs: connection refused; test pod failed-test-without-logs failed, cannot get its logs: pod not found", summary)
+}
@@ -0,0 +1,7 @@
+package helm
+
+import "helm.sh/helm/v3/pkg/release"
+
+func SummarizeFailedTests(hooks []*release.Hook, podLogs func(podName string) (string, error)) string {
+	return summarizeFailedTests(hooks, podLogs)
+}
@@ -0,0 +1,162 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kyma-project/helm-broker/internal"
+)
+
+const (
+	kindDeployment            = "Deployment"
+	kindStatefulSet           = "StatefulSet"
+	kindJob                   = "Job"
+	kindPersistentVolumeClaim = "PersistentVolumeClaim"
+)
+
+// HealthEvaluator checks the readiness of the Deployments, StatefulSets, Jobs and PersistentVolumeClaims of the release
+type HealthEvaluator struct {
+	clientset kubernetes.Interface
+}
+
+// NewHealthEvaluator returns the evaluator which gets the release resources with the given clientset
+func NewHealthEvaluator(clientset kubernetes.Interface) *HealthEvaluator {
+	return &HealthEvaluator{clientset: clientset}
+}
+
+type manifestHead struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+// Evaluate checks the resources from the release manifest. Resources of other kinds are not checked.
+// The release is healthy when all checked resources are healthy.
+func (e *HealthEvaluator) Evaluate(manifest string, namespace internal.Namespace) (internal.ReleaseHealth, error) {
+	health := internal.ReleaseHealth{Healthy: true}
+
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var head manifestHead
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil {
+			return internal.ReleaseHealth{}, errors.Wrap(err, "while decoding release manifest")
+		}
+		ns := head.Metadata.Namespace
+		if ns == "" {
+			ns = string(namespace)
+		}
+
+		resource, checked, err := e.evaluateResource(head.Kind, head.Metadata.Name, ns)
+		if err != nil {
+			return internal.ReleaseHealth{}, errors.Wrapf(err, "while checking %s %s", head.Kind, head.Metadata.Name)
+		}
+		if !checked {
+			continue
+		}
+		health.Resources = append(health.Resources, resource)
+		health.Healthy = health.Healthy && resource.Healthy
+	}
+
+	sort.Slice(health.Resources, func(i, j int) bool {
+		if health.Resources[i].Kind != health.Resources[j].Kind {
+			return health.Resources[i].Kind < health.Resources[j].Kind
+		}
+		return health.Resources[i].Name < health.Resources[j].Name
+	})
+
+	return health, nil
+}
+
+func (e *HealthEvaluator) evaluateResource(kind, name, namespace string) (internal.ResourceHealth, bool, error) {
+	var (
+		healthy bool
+		message string
+		err     error
+	)
+
+	ctx := context.Background()
+	switch kind {
+	case kindDeployment:
+		var d *appsv1.Deployment
+		if d, err = e.clientset.AppsV1().Deployments(namespace).Get(ctx, name, v1.GetOptions{}); err == nil {
+			healthy, message = deploymentHealth(d)
+		}
+	case kindStatefulSet:
+		var s *appsv1.StatefulSet
+		if s, err = e.clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, v1.GetOptions{}); err == nil {
+			healthy, message = statefulSetHealth(s)
+		}
+	case kindJob:
+		var j *batchv1.Job
+		if j, err = e.clientset.BatchV1().Jobs(namespace).Get(ctx, name, v1.GetOptions{}); err == nil {
+			healthy, message = jobHealth(j)
+		}
+	case kindPersistentVolumeClaim:
+		var pvc *corev1.PersistentVolumeClaim
+		if pvc, err = e.clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, v1.GetOptions{}); err == nil {
+			healthy, message = pvcHealth(pvc)
+		}
+	default:
+		return internal.ResourceHealth{}, false, nil
+	}
+
+	switch {
+	case apiErrors.IsNotFound(err):
+		healthy, message = false, "not found"
+	case err != nil:
+		return internal.ResourceHealth{}, false, err
+	}
+
+	return internal.ResourceHealth{Kind: kind, Name: name, Healthy: healthy, Message: message}, true, nil
+}
+
+func deploymentHealth(d *appsv1.Deployment) (bool, string) {
+	replicas := replicasOrDefault(d.Spec.Replicas)
+	if d.Status.ObservedGeneration < d.Generation {
+		return false, "rollout is not observed yet"
+	}
+	healthy := d.Status.UpdatedReplicas >= replicas && d.Status.AvailableReplicas >= replicas
+	return healthy, fmt.Sprintf("%d/%d replicas available", d.Status.AvailableReplicas, replicas)
+}
+
+func statefulSetHealth(s *appsv1.StatefulSet) (bool, string) {
+	replicas := replicasOrDefault(s.Spec.Replicas)
+	if s.Status.ObservedGeneration < s.Generation {
+		return false, "rollout is not observed yet"
+	}
+	healthy := s.Status.UpdatedReplicas >= replicas && s.Status.ReadyReplicas >= replicas
+	return healthy, fmt.Sprintf("%d/%d replicas ready", s.Status.ReadyReplicas, replicas)
+}
+
+func jobHealth(j *batchv1.Job) (bool, string) {
+	for _, c := range j.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return false, fmt.Sprintf("failed: %s", c.Message)
+		}
+	}
+	completions := replicasOrDefault(j.Spec.Completions)
+	return j.Status.Succeeded >= completions, fmt.Sprintf("%d/%d completions succeeded", j.Status.Succeeded, completions)
+}
+
+func pvcHealth(pvc *corev1.PersistentVolumeClaim) (bool, string) {
+	return pvc.Status.Phase == corev1.ClaimBound, fmt.Sprintf("phase %s", pvc.Status.Phase)
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,122 @@
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/helm"
+)
+
+const fixManifest = `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: redis-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: redis
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: redis-replica
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: redis-migration
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: redis-data
+`
+
+func TestHealthEvaluatorEvaluate(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		// given
+		evaluator := helm.NewHealthEvaluator(fake.NewSimpleClientset(
+			fixDeployment(1, 1),
+			fixStatefulSet(2, 2),
+			fixJob(1, nil),
+			fixPVC(corev1.ClaimBound),
+		))
+
+		// when
+		health, err := evaluator.Evaluate(fixManifest, "stage")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, health.Healthy)
+		assert.Equal(t, []internal.ResourceHealth{
+			{Kind: "Deployment", Name: "redis", Healthy: true, Message: "1/1 replicas available"},
+			{Kind: "Job", Name: "redis-migration", Healthy: true, Message: "1/1 completions succeeded"},
+			{Kind: "PersistentVolumeClaim", Name: "redis-data", Healthy: true, Message: "phase Bound"},
+			{Kind: "StatefulSet", Name: "redis-replica", Healthy: true, Message: "2/2 replicas ready"},
+		}, health.Resources)
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		// given
+		failed := &batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}
+		evaluator := helm.NewHealthEvaluator(fake.NewSimpleClientset(
+			fixDeployment(1, 0),
+			fixJob(0, failed),
+			fixPVC(corev1.ClaimPending),
+		))
+
+		// when
+		health, err := evaluator.Evaluate(fixManifest, "stage")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, health.Healthy)
+		assert.Equal(t, "Deployment redis: 0/1 replicas available, Job redis-migration: failed: BackoffLimitExceeded, "+
+			"PersistentVolumeClaim redis-data: phase Pending, StatefulSet redis-replica: not found", health.Summary())
+	})
+}
+
+func fixDeployment(replicas, available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "redis", Namespace: "stage"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{UpdatedReplicas: replicas, AvailableReplicas: available},
+	}
+}
+
+func fixStatefulSet(replicas, ready int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: v1.ObjectMeta{Name: "redis-replica", Namespace: "stage"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{UpdatedReplicas: replicas, ReadyReplicas: ready},
+	}
+}
+
+func fixJob(succeeded int32, condition *batchv1.JobCondition) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: v1.ObjectMeta{Name: "redis-migration", Namespace: "stage"},
+		Status:     batchv1.JobStatus{Succeeded: succeeded},
+	}
+	if condition != nil {
+		job.Status.Conditions = append(job.Status.Conditions, *condition)
+	}
+	return job
+}
+
+func fixPVC(phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: v1.ObjectMeta{Name: "redis-data", Namespace: "stage"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
@@ -3,6 +3,8 @@ package internal
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
@@ -124,6 +126,9 @@ func EmptyChartValues() ChartValues {
 // AddonPlanBindTemplate represents template used for helm chart installation
 type AddonPlanBindTemplate []byte
 
+// AddonPlanDashboardURLTemplate represents template used for rendering the dashboard URL of the service instance
+type AddonPlanDashboardURLTemplate []byte
+
 // AddonPlan is a container for whole data of addon plan.
 // Each addon needs to have at least one plan.
 type AddonPlan struct {
@@ -137,6 +142,37 @@ type AddonPlan struct {
 	Bindable     *bool
 	Free         *bool
 	BindTemplate AddonPlanBindTemplate
+	// DashboardURLTemplate is rendered after the release is installed or upgraded, it is optional
+	DashboardURLTemplate AddonPlanDashboardURLTemplate
+	// AtomicProvisioning overrides the broker configuration of the cleanup of failed provisioning
+	AtomicProvisioning *bool
+	// SynchronousOperations allows for provisioning and deprovisioning the plan instances without the asynchronous operation mode
+	SynchronousOperations bool
+	// RunTests enables running the chart tests after the release is installed or upgraded
+	RunTests bool
+	// HelmOptions defines how the release of the plan is installed, upgraded and deleted
+	HelmOptions HelmOptions
+}
+
+// HelmOptions defines the options of the helm operations on the release
+type HelmOptions struct {
+	// Timeout of the helm operation, the helm client default timeout is used when it is not set
+	Timeout time.Duration
+	// Wait enables waiting until the release resources are ready, it is enabled when it is not set
+	Wait *bool
+	// WaitForJobs enables waiting until the release jobs are completed
+	WaitForJobs bool
+	// Atomic enables removing the failed installation and rolling back the failed upgrade
+	Atomic bool
+	// DisableHooks prevents running the release hooks
+	DisableHooks bool
+	// SkipCRDs prevents installing the CRDs of the chart
+	SkipCRDs bool
+}
+
+// IsWait returns true when waiting until the release resources are ready is enabled
+func (o HelmOptions) IsWait() bool {
+	return o.Wait == nil || *o.Wait
 }
 
 // AddonPlanMetadata provides metadata of the addon.
@@ -298,6 +334,8 @@ type InstanceOperation struct {
 	State                  OperationState
 	StateDescription       *string
 	ProvisioningParameters *RequestParameters
+	// RequestedBy identifies the user on whose behalf the Platform requested the operation
+	RequestedBy OperationRequester
 
 	// CreatedAt points to creation time of the operation.
 	// Field should be treated as immutable and is responsibility of storage implementation.
@@ -305,6 +343,13 @@ type InstanceOperation struct {
 	CreatedAt time.Time
 }
 
+// OperationRequester is the user decoded from the originating identity of the request which started the operation.
+// It is empty when the Platform did not send the originating identity.
+type OperationRequester struct {
+	Username string
+	UID      string
+}
+
 // ReleaseName is the name of the Helm release.
 type ReleaseName string
 
@@ -332,6 +377,36 @@ type ReleaseInfo struct {
 	ConfigValues map[string]interface{}
 }
 
+// ReleaseHealth describes the readiness of the workloads and volumes of the release
+type ReleaseHealth struct {
+	Healthy   bool
+	Resources []ResourceHealth
+}
+
+// ResourceHealth describes the readiness of the single release resource
+type ResourceHealth struct {
+	Kind    string
+	Name    string
+	Healthy bool
+	// Message describes the state of the resource, e.g. the number of ready replicas
+	Message string
+}
+
+// Summary describes the unhealthy resources of the release
+func (h ReleaseHealth) Summary() string {
+	if h.Healthy {
+		return fmt.Sprintf("all %d checked resources are healthy", len(h.Resources))
+	}
+
+	var unhealthy []string
+	for _, r := range h.Resources {
+		if !r.Healthy {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s %s: %s", r.Kind, r.Name, r.Message))
+		}
+	}
+	return strings.Join(unhealthy, ", ")
+}
+
 // RequestParameters wraps a map containing provided YAML with parameters from request
 type RequestParameters struct {
 	Data map[string]interface{}
@@ -352,6 +427,13 @@ type Instance struct {
 	ReleaseInfo            ReleaseInfo
 	ProvisioningParameters *RequestParameters
 	ParamsHash             string
+	// AddonVersion is the version of the addon with which the instance was provisioned or last updated
+	AddonVersion AddonVersion
+	// DashboardURL is rendered from the plan dashboard URL template, it is empty when the plan does not define it
+	DashboardURL string
+	// HelmOptions are the helm options of the plan with which the instance was provisioned or last updated,
+	// they are used when the release is deleted
+	HelmOptions HelmOptions
 }
 
 // InstanceCredentials are created when we bind a service instance.
@@ -371,6 +453,12 @@ type BindOperation struct {
 	Type             OperationType
 	State            OperationState
 	StateDescription *string
+	// RequestedBy identifies the user on whose behalf the Platform requested the operation
+	RequestedBy OperationRequester
+	// Parameters are the parameters of the bind request which created the binding
+	Parameters *RequestParameters
+	// ParamsHash is the hash of the Parameters, used to detect repeated bind requests with different parameters
+	ParamsHash string
 
 	// CreatedAt points to creation time of the operation.
 	// Field should be treated as immutable and is responsibility of storage implementation.
@@ -378,9 +466,10 @@ type BindOperation struct {
 	CreatedAt time.Time
 }
 
-// InstanceBindData contains data about service instance and it's credentials.
+// InstanceBindData contains data about service binding and it's credentials.
 type InstanceBindData struct {
 	InstanceID  InstanceID
+	BindingID   BindingID
 	Credentials InstanceCredentials
 }
 
@@ -409,6 +498,8 @@ const (
 	OperationTypeCreate OperationType = "create"
 	// OperationTypeRemove means removing OperationType
 	OperationTypeRemove OperationType = "remove"
+	// OperationTypeUpdate means updating OperationType
+	OperationTypeUpdate OperationType = "update"
 	// OperationTypeUndefined means undefined OperationType
 	OperationTypeUndefined OperationType = ""
 )
@@ -0,0 +1,53 @@
+// Package nsauth defines the credentials which authenticate only the requests sent to the broker of a single namespace.
+// The controller issues them for the namespaced ServiceBrokers and the broker accepts them only on the /ns/{namespace} routes.
+package nsauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// ServiceAccountName is the name of the ServiceAccount created in the namespace of the ServiceBroker.
+	// Its token authenticates requests to the broker of that namespace when the bearer authentication is used.
+	ServiceAccountName = "helm-broker-auth"
+
+	usernameSeparator      = "@"
+	serviceAccountPrefix   = "system:serviceaccount:"
+	serviceAccountSegments = 4
+)
+
+// BasicCredentials derives from the broker credentials the username and password for the broker of the given namespace.
+// The password is the HMAC of the namespace, so it cannot be used to get the broker password or credentials of other namespaces.
+func BasicCredentials(username, password []byte, namespace string) ([]byte, []byte) {
+	mac := hmac.New(sha256.New, password)
+	mac.Write([]byte(namespace))
+
+	nsUsername := string(username) + usernameSeparator + namespace
+	nsPassword := hex.EncodeToString(mac.Sum(nil))
+	return []byte(nsUsername), []byte(nsPassword)
+}
+
+// NamespaceFromBasicUsername returns the namespace of the username created by the BasicCredentials function
+func NamespaceFromBasicUsername(username string) (string, bool) {
+	idx := strings.LastIndex(username, usernameSeparator)
+	if idx < 0 || idx == len(username)-1 {
+		return "", false
+	}
+	return username[idx+1:], true
+}
+
+// NamespaceFromServiceAccount returns the namespace of the ServiceAccount user authenticated by the TokenReview,
+// e.g. system:serviceaccount:stage:helm-broker-auth. False is returned for all other users.
+func NamespaceFromServiceAccount(username string) (string, bool) {
+	if !strings.HasPrefix(username, serviceAccountPrefix) {
+		return "", false
+	}
+	segments := strings.Split(username, ":")
+	if len(segments) != serviceAccountSegments || segments[2] == "" || segments[3] != ServiceAccountName {
+		return "", false
+	}
+	return segments[2], true
+}
@@ -1,7 +1,13 @@
 package etcd
 
 import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
 	"go.etcd.io/etcd/clientv3"
+
+	"github.com/kyma-project/helm-broker/internal"
 )
 
 // TODO list:
@@ -42,3 +48,29 @@ func entityNamespacePrefixParts() []string {
 type generic struct {
 	kv clientv3.KV
 }
+
+// instanceIDs returns the distinct instance IDs from the keys which start with the instance key prefix
+func (g *generic) instanceIDs() ([]internal.InstanceID, error) {
+	// special character NULL hex (\x00) is used to select all keys, empty string is not allowed for etcd/clientv3 library
+	resp, err := g.kv.Get(context.TODO(), "\x00", clientv3.WithFromKey(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, errors.Wrap(err, "while calling database")
+	}
+
+	out := []internal.InstanceID{}
+	seen := map[internal.InstanceID]struct{}{}
+	for _, kv := range resp.Kvs {
+		idx := strings.Index(string(kv.Key), entityNamespaceSeparator)
+		if idx <= 0 {
+			continue
+		}
+		iID := internal.InstanceID(kv.Key[:idx])
+		if _, found := seen[iID]; found {
+			continue
+		}
+		seen[iID] = struct{}{}
+		out = append(out, iID)
+	}
+
+	return out, nil
+}
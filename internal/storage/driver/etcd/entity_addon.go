@@ -319,29 +319,39 @@ func newAddonPlanDSO(plan internal.AddonPlan) (addonPlanDSO, error) {
 	}
 
 	return addonPlanDSO{
-		Schemas:      plan.Schemas,
-		Name:         plan.Name,
-		ChartRef:     plan.ChartRef,
-		Bindable:     plan.Bindable,
-		ChartValues:  chartValuesDSO,
-		ID:           plan.ID,
-		Description:  plan.Description,
-		Metadata:     plan.Metadata,
-		BindTemplate: plan.BindTemplate,
+		Schemas:               plan.Schemas,
+		Name:                  plan.Name,
+		ChartRef:              plan.ChartRef,
+		Bindable:              plan.Bindable,
+		ChartValues:           chartValuesDSO,
+		ID:                    plan.ID,
+		Description:           plan.Description,
+		Metadata:              plan.Metadata,
+		BindTemplate:          plan.BindTemplate,
+		DashboardURLTemplate:  plan.DashboardURLTemplate,
+		AtomicProvisioning:    plan.AtomicProvisioning,
+		SynchronousOperations: plan.SynchronousOperations,
+		RunTests:              plan.RunTests,
+		HelmOptions:           plan.HelmOptions,
 	}, nil
 }
 
 type addonPlanDSO struct {
-	ID           internal.AddonPlanID
-	Name         internal.AddonPlanName
-	Description  string
-	Schemas      map[internal.PlanSchemaType]internal.PlanSchema
-	ChartRef     internal.ChartRef
-	ChartValues  chartValuesDSO
-	Metadata     internal.AddonPlanMetadata
-	BindTemplate internal.AddonPlanBindTemplate
-	Bindable     *bool
-	Free         *bool
+	ID                    internal.AddonPlanID
+	Name                  internal.AddonPlanName
+	Description           string
+	Schemas               map[internal.PlanSchemaType]internal.PlanSchema
+	ChartRef              internal.ChartRef
+	ChartValues           chartValuesDSO
+	Metadata              internal.AddonPlanMetadata
+	BindTemplate          internal.AddonPlanBindTemplate
+	DashboardURLTemplate  internal.AddonPlanDashboardURLTemplate
+	Bindable              *bool
+	Free                  *bool
+	AtomicProvisioning    *bool
+	SynchronousOperations bool
+	RunTests              bool
+	HelmOptions           internal.HelmOptions
 }
 
 func (dso *addonPlanDSO) ToModel() (internal.AddonPlan, error) {
@@ -350,16 +360,21 @@ func (dso *addonPlanDSO) ToModel() (internal.AddonPlan, error) {
 		return internal.AddonPlan{}, errors.Wrap(err, "while converting addonPlanDSO to model")
 	}
 	return internal.AddonPlan{
-		ID:           dso.ID,
-		BindTemplate: dso.BindTemplate,
-		Metadata:     dso.Metadata,
-		Description:  dso.Description,
-		Bindable:     dso.Bindable,
-		ChartRef:     dso.ChartRef,
-		Name:         dso.Name,
-		Schemas:      dso.Schemas,
-		Free:         dso.Free,
-		ChartValues:  chValues,
+		ID:                    dso.ID,
+		BindTemplate:          dso.BindTemplate,
+		DashboardURLTemplate:  dso.DashboardURLTemplate,
+		Metadata:              dso.Metadata,
+		Description:           dso.Description,
+		Bindable:              dso.Bindable,
+		ChartRef:              dso.ChartRef,
+		Name:                  dso.Name,
+		Schemas:               dso.Schemas,
+		Free:                  dso.Free,
+		ChartValues:           chValues,
+		AtomicProvisioning:    dso.AtomicProvisioning,
+		SynchronousOperations: dso.SynchronousOperations,
+		RunTests:              dso.RunTests,
+		HelmOptions:           dso.HelmOptions,
 	}, nil
 }
 
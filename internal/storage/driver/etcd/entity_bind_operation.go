@@ -22,6 +22,14 @@ func NewBindOperation(cli clientv3.KV) (*BindOperation, error) {
 	prefixParts := append(entityNamespacePrefixParts(), string(entityNamespaceBindOperation))
 	kv := namespace.NewKV(cli, strings.Join(prefixParts, entityNamespaceSeparator))
 
+	// Register interface types which are used by this domain.
+	// Bind parameters are decoded from JSON, so nested objects and arrays are stored as these types.
+	// Not registered globally as helm-broker gives an option to configure storage
+	// driver for each domain, so they should be treated separately and cannot
+	// assume that other domain registered that type already.
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+
 	d := &BindOperation{
 		generic: generic{
 			kv: kv,
@@ -149,6 +157,11 @@ func (s *BindOperation) GetAll(iID internal.InstanceID) ([]*internal.BindOperati
 	return out, nil
 }
 
+// GetInstanceIDs returns IDs of all instances which have bind operations in storage.
+func (s *BindOperation) GetInstanceIDs() ([]internal.InstanceID, error) {
+	return s.instanceIDs()
+}
+
 // UpdateState modifies state on object in storage.
 func (s *BindOperation) UpdateState(iID internal.InstanceID, bID internal.BindingID, opID internal.OperationID, state internal.OperationState) error {
 	return s.updateStateDesc(iID, bID, opID, state, nil)
@@ -160,6 +160,11 @@ func (s *InstanceOperation) GetAll(iID internal.InstanceID) ([]*internal.Instanc
 	return out, nil
 }
 
+// GetInstanceIDs returns IDs of all instances which have operations in storage.
+func (s *InstanceOperation) GetInstanceIDs() ([]internal.InstanceID, error) {
+	return s.instanceIDs()
+}
+
 func (*InstanceOperation) handleGetError(errIn error) error {
 	return errors.Wrap(errIn, "while calling database")
 }
@@ -98,6 +98,18 @@ func (s *BindOperation) GetAll(iID internal.InstanceID) ([]*internal.BindOperati
 	return out, nil
 }
 
+// GetInstanceIDs returns IDs of all instances which have bind operations in storage.
+func (s *BindOperation) GetInstanceIDs() ([]internal.InstanceID, error) {
+	defer unlock(s.lockR())
+
+	out := []internal.InstanceID{}
+	for iID := range s.storage {
+		out = append(out, iID)
+	}
+
+	return out, nil
+}
+
 // UpdateState modifies state on object in storage.
 func (s *BindOperation) UpdateState(iID internal.InstanceID, bID internal.BindingID, opID internal.OperationID, state internal.OperationState) error {
 	defer unlock(s.lockW())
@@ -9,14 +9,14 @@ import (
 // NewInstanceBindData returns new instance of BindData storage.
 func NewInstanceBindData() *InstanceBindData {
 	return &InstanceBindData{
-		storage: make(map[internal.InstanceID]*internal.InstanceBindData),
+		storage: make(map[internal.InstanceID]map[internal.BindingID]*internal.InstanceBindData),
 	}
 }
 
 // InstanceBindData implements in-memory based storage for BindData.
 type InstanceBindData struct {
 	threadSafeStorage
-	storage map[internal.InstanceID]*internal.InstanceBindData
+	storage map[internal.InstanceID]map[internal.BindingID]*internal.InstanceBindData
 }
 
 // Insert inserts object into storage.
@@ -31,22 +31,30 @@ func (s *InstanceBindData) Insert(ibd *internal.InstanceBindData) error {
 		return errors.New("instance id must be set")
 	}
 
-	if _, found := s.storage[ibd.InstanceID]; found {
+	if ibd.BindingID.IsZero() {
+		return errors.New("binding id must be set")
+	}
+
+	if _, found := s.storage[ibd.InstanceID]; !found {
+		s.storage[ibd.InstanceID] = make(map[internal.BindingID]*internal.InstanceBindData)
+	}
+
+	if _, found := s.storage[ibd.InstanceID][ibd.BindingID]; found {
 		return alreadyExistsError{}
 	}
 
 	// TODO switch to deep copy?
 	cpy := *ibd
-	s.storage[ibd.InstanceID] = &cpy
+	s.storage[ibd.InstanceID][ibd.BindingID] = &cpy
 
 	return nil
 }
 
 // Get returns object from storage.
-func (s *InstanceBindData) Get(iID internal.InstanceID) (*internal.InstanceBindData, error) {
+func (s *InstanceBindData) Get(iID internal.InstanceID, bID internal.BindingID) (*internal.InstanceBindData, error) {
 	defer unlock(s.lockR())
 
-	i, found := s.storage[iID]
+	i, found := s.storage[iID][bID]
 	if !found {
 		return nil, notFoundError{}
 	}
@@ -55,7 +63,23 @@ func (s *InstanceBindData) Get(iID internal.InstanceID) (*internal.InstanceBindD
 }
 
 // Remove removes object from storage.
-func (s *InstanceBindData) Remove(iID internal.InstanceID) error {
+func (s *InstanceBindData) Remove(iID internal.InstanceID, bID internal.BindingID) error {
+	defer unlock(s.lockW())
+
+	if _, found := s.storage[iID][bID]; !found {
+		return notFoundError{}
+	}
+
+	delete(s.storage[iID], bID)
+	if len(s.storage[iID]) == 0 {
+		delete(s.storage, iID)
+	}
+
+	return nil
+}
+
+// RemoveAll removes all objects of the given instance from storage.
+func (s *InstanceBindData) RemoveAll(iID internal.InstanceID) error {
 	defer unlock(s.lockW())
 
 	if _, found := s.storage[iID]; !found {
@@ -65,10 +65,16 @@ func (s *InstanceOperation) Insert(io *internal.InstanceOperation) error {
 }
 
 // Get returns object from storage.
+// The copy of the object is returned, so it is not changed by the later updates.
 func (s *InstanceOperation) Get(iID internal.InstanceID, opID internal.OperationID) (*internal.InstanceOperation, error) {
 	defer unlock(s.lockR())
 
-	return s.get(iID, opID)
+	io, err := s.get(iID, opID)
+	if err != nil {
+		return nil, err
+	}
+	ioCopy := *io
+	return &ioCopy, nil
 }
 
 func (s *InstanceOperation) get(iID internal.InstanceID, opID internal.OperationID) (*internal.InstanceOperation, error) {
@@ -100,7 +106,20 @@ func (s *InstanceOperation) GetAll(iID internal.InstanceID) ([]*internal.Instanc
 	}
 
 	for i := range opsForInstance {
-		out = append(out, opsForInstance[i])
+		ioCopy := *opsForInstance[i]
+		out = append(out, &ioCopy)
+	}
+
+	return out, nil
+}
+
+// GetInstanceIDs returns IDs of all instances which have operations in storage.
+func (s *InstanceOperation) GetInstanceIDs() ([]internal.InstanceID, error) {
+	defer unlock(s.lockR())
+
+	out := []internal.InstanceID{}
+	for iID := range s.storage {
+		out = append(out, iID)
 	}
 
 	return out, nil
@@ -42,6 +42,8 @@ type InstanceOperation interface {
 	Insert(*internal.InstanceOperation) error
 	Get(internal.InstanceID, internal.OperationID) (*internal.InstanceOperation, error)
 	GetAll(internal.InstanceID) ([]*internal.InstanceOperation, error)
+	// GetInstanceIDs returns IDs of all instances which have operations, also the ones which were already removed.
+	GetInstanceIDs() ([]internal.InstanceID, error)
 	UpdateState(internal.InstanceID, internal.OperationID, internal.OperationState) error
 	UpdateStateDesc(internal.InstanceID, internal.OperationID, internal.OperationState, *string) error
 	Remove(internal.InstanceID, internal.OperationID) error
@@ -52,6 +54,8 @@ type BindOperation interface {
 	Insert(*internal.BindOperation) error
 	Get(internal.InstanceID, internal.BindingID, internal.OperationID) (*internal.BindOperation, error)
 	GetAll(internal.InstanceID) ([]*internal.BindOperation, error)
+	// GetInstanceIDs returns IDs of all instances which have bind operations, also the ones which were already removed.
+	GetInstanceIDs() ([]internal.InstanceID, error)
 	UpdateState(internal.InstanceID, internal.BindingID, internal.OperationID, internal.OperationState) error
 	UpdateStateDesc(internal.InstanceID, internal.BindingID, internal.OperationID, internal.OperationState, *string) error
 	Remove(internal.InstanceID, internal.BindingID, internal.OperationID) error
@@ -60,8 +64,9 @@ type BindOperation interface {
 // InstanceBindData is an interface that describe storage layer operations for InstanceBindData entities
 type InstanceBindData interface {
 	Insert(*internal.InstanceBindData) error
-	Get(internal.InstanceID) (*internal.InstanceBindData, error)
-	Remove(internal.InstanceID) error
+	Get(internal.InstanceID, internal.BindingID) (*internal.InstanceBindData, error)
+	Remove(internal.InstanceID, internal.BindingID) error
+	RemoveAll(internal.InstanceID) error
 }
 
 // IsNotFoundError checks if given error is NotFound error
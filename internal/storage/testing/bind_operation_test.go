@@ -0,0 +1,45 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/helm-broker/internal"
+	"github.com/kyma-project/helm-broker/internal/storage"
+)
+
+func TestBindOperationInsertGetWithNestedParameters(t *testing.T) {
+	tRunDrivers(t, "Found", func(t *testing.T, sf storage.Factory) {
+		// GIVEN:
+		params := map[string]interface{}{
+			"name": "redis",
+			"tls": map[string]interface{}{
+				"enabled": true,
+			},
+			"users": []interface{}{
+				map[string]interface{}{"name": "admin"},
+				"guest",
+			},
+		}
+		exp := &internal.BindOperation{
+			InstanceID:  "iID-001",
+			BindingID:   "bID-001",
+			OperationID: "oID-001",
+			Type:        internal.OperationTypeCreate,
+			State:       internal.OperationStateInProgress,
+			Parameters:  &internal.RequestParameters{Data: params},
+		}
+
+		// WHEN:
+		err := sf.BindOperation().Insert(exp)
+		require.NoError(t, err)
+		got, err := sf.BindOperation().Get(exp.InstanceID, exp.BindingID, exp.OperationID)
+
+		// THEN:
+		require.NoError(t, err)
+		require.NotNil(t, got.Parameters)
+		assert.Equal(t, params, got.Parameters.Data)
+	})
+}
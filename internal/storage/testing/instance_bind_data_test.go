@@ -18,20 +18,38 @@ func TestInstanceBindDataGet(t *testing.T) {
 		exp := ts.MustGetFixture("single")
 
 		// WHEN:
-		got, err := ts.s.Get(exp.InstanceID)
+		got, err := ts.s.Get(exp.InstanceID, exp.BindingID)
 
 		// THEN:
 		assert.NoError(t, err)
 		ts.AssertInstanceEqual(exp, got)
 	})
 
+	tRunDrivers(t, "Found/SeparateBindingsOfSameInstance", func(t *testing.T, sf storage.Factory) {
+		// GIVEN:
+		ts := newInstanceBindDataTestSuite(t, sf)
+		ts.PopulateStorage()
+		exp := ts.MustGetFixture("multiple")
+		expOther := ts.MustGetFixture("multipleOtherBinding")
+
+		// WHEN:
+		got, err := ts.s.Get(exp.InstanceID, exp.BindingID)
+		gotOther, errOther := ts.s.Get(expOther.InstanceID, expOther.BindingID)
+
+		// THEN:
+		assert.NoError(t, err)
+		assert.NoError(t, errOther)
+		ts.AssertInstanceEqual(exp, got)
+		ts.AssertInstanceEqual(expOther, gotOther)
+	})
+
 	tRunDrivers(t, "Failure/NotFound", func(t *testing.T, sf storage.Factory) {
 		// GIVEN:
 		ts := newInstanceBindDataTestSuite(t, sf)
 		ts.PopulateStorage()
 
 		// WHEN:
-		got, err := ts.s.Get(internal.InstanceID("non-existing-iID"))
+		got, err := ts.s.Get(internal.InstanceID("non-existing-iID"), internal.BindingID("non-existing-bID"))
 
 		// THEN:
 		ts.AssertNotFoundError(err)
@@ -78,6 +96,19 @@ func TestInstanceBindDataInsert(t *testing.T) {
 		// THEN:
 		assert.EqualError(t, err, "instance id must be set")
 	})
+
+	tRunDrivers(t, "Failure/EmptyBindingID", func(t *testing.T, sf storage.Factory) {
+		// GIVEN:
+		ts := newInstanceBindDataTestSuite(t, sf)
+		fix := ts.MustGetFixture("single")
+		fix.BindingID = internal.BindingID("")
+
+		// WHEN:
+		err := ts.s.Insert(fix)
+
+		// THEN:
+		assert.EqualError(t, err, "binding id must be set")
+	})
 }
 
 func TestInstanceBindDataRemove(t *testing.T) {
@@ -88,20 +119,71 @@ func TestInstanceBindDataRemove(t *testing.T) {
 		exp := ts.MustGetFixture("single")
 
 		// WHEN:
-		err := ts.s.Remove(exp.InstanceID)
+		err := ts.s.Remove(exp.InstanceID, exp.BindingID)
 
 		// THEN:
 		assert.NoError(t, err)
 		ts.AssertInstanceBindDataDoesNotExist(exp)
 	})
 
+	tRunDrivers(t, "Success/OtherBindingsOfInstanceKept", func(t *testing.T, sf storage.Factory) {
+		// GIVEN:
+		ts := newInstanceBindDataTestSuite(t, sf)
+		ts.PopulateStorage()
+		exp := ts.MustGetFixture("multiple")
+		expOther := ts.MustGetFixture("multipleOtherBinding")
+
+		// WHEN:
+		err := ts.s.Remove(exp.InstanceID, exp.BindingID)
+
+		// THEN:
+		assert.NoError(t, err)
+		ts.AssertInstanceBindDataDoesNotExist(exp)
+		got, err := ts.s.Get(expOther.InstanceID, expOther.BindingID)
+		assert.NoError(t, err)
+		ts.AssertInstanceEqual(expOther, got)
+	})
+
+	tRunDrivers(t, "Failure/NotFound", func(t *testing.T, sf storage.Factory) {
+		// GIVEN:
+		ts := newInstanceBindDataTestSuite(t, sf)
+		ts.PopulateStorage()
+
+		// WHEN:
+		err := ts.s.Remove(internal.InstanceID("non-existing-iID"), internal.BindingID("non-existing-bID"))
+
+		// THEN:
+		ts.AssertNotFoundError(err)
+	})
+}
+
+func TestInstanceBindDataRemoveAll(t *testing.T) {
+	tRunDrivers(t, "Success", func(t *testing.T, sf storage.Factory) {
+		// GIVEN:
+		ts := newInstanceBindDataTestSuite(t, sf)
+		ts.PopulateStorage()
+		exp := ts.MustGetFixture("multiple")
+		expOther := ts.MustGetFixture("multipleOtherBinding")
+		expKept := ts.MustGetFixture("single")
+
+		// WHEN:
+		err := ts.s.RemoveAll(exp.InstanceID)
+
+		// THEN:
+		assert.NoError(t, err)
+		ts.AssertInstanceBindDataDoesNotExist(exp)
+		ts.AssertInstanceBindDataDoesNotExist(expOther)
+		_, err = ts.s.Get(expKept.InstanceID, expKept.BindingID)
+		assert.NoError(t, err)
+	})
+
 	tRunDrivers(t, "Failure/NotFound", func(t *testing.T, sf storage.Factory) {
 		// GIVEN:
 		ts := newInstanceBindDataTestSuite(t, sf)
 		ts.PopulateStorage()
 
 		// WHEN:
-		err := ts.s.Remove(internal.InstanceID("non-existing-iID"))
+		err := ts.s.RemoveAll(internal.InstanceID("non-existing-iID"))
 
 		// THEN:
 		ts.AssertNotFoundError(err)
@@ -112,8 +194,8 @@ func newInstanceBindDataTestSuite(t *testing.T, sf storage.Factory) *instanceBin
 	ts := instanceBindDataTestSuite{
 		t:                   t,
 		s:                   sf.InstanceBindData(),
-		fixtures:            make(map[internal.InstanceID]*internal.InstanceBindData),
-		fixturesSymToKeyMap: make(map[string]internal.InstanceID),
+		fixtures:            make(map[instanceBindDataKey]*internal.InstanceBindData),
+		fixturesSymToKeyMap: make(map[string]instanceBindDataKey),
 	}
 
 	ts.generateFixtures()
@@ -121,31 +203,43 @@ func newInstanceBindDataTestSuite(t *testing.T, sf storage.Factory) *instanceBin
 	return &ts
 }
 
+type instanceBindDataKey struct {
+	iID internal.InstanceID
+	bID internal.BindingID
+}
+
 type instanceBindDataTestSuite struct {
 	t                   *testing.T
 	s                   storage.InstanceBindData
-	fixtures            map[internal.InstanceID]*internal.InstanceBindData
-	fixturesSymToKeyMap map[string]internal.InstanceID
+	fixtures            map[instanceBindDataKey]*internal.InstanceBindData
+	fixturesSymToKeyMap map[string]instanceBindDataKey
 }
 
 func (ts *instanceBindDataTestSuite) generateFixtures() {
 	for fs, ft := range map[string]struct {
 		id   string
+		bID  string
 		cred map[string]string
 	}{
-		"single":   {"id-01", map[string]string{"c1": "v1"}},
-		"multiple": {"id-02", map[string]string{"c1": "v1", "c2": "v2"}},
-		"empty":    {"id-03", map[string]string{}},
+		"single":               {"id-01", "bid-01", map[string]string{"c1": "v1"}},
+		"multiple":             {"id-02", "bid-02", map[string]string{"c1": "v1", "c2": "v2"}},
+		"multipleOtherBinding": {"id-02", "bid-03", map[string]string{"c1": "v3", "c2": "v4"}},
+		"empty":                {"id-03", "bid-04", map[string]string{}},
 	} {
 		cred := make(internal.InstanceCredentials)
+		for k, v := range ft.cred {
+			cred[k] = v
+		}
 
 		i := &internal.InstanceBindData{
 			InstanceID:  internal.InstanceID(ft.id),
+			BindingID:   internal.BindingID(ft.bID),
 			Credentials: cred,
 		}
 
-		ts.fixtures[i.InstanceID] = i
-		ts.fixturesSymToKeyMap[fs] = i.InstanceID
+		key := instanceBindDataKey{iID: i.InstanceID, bID: i.BindingID}
+		ts.fixtures[key] = i
+		ts.fixturesSymToKeyMap[fs] = key
 	}
 }
 
@@ -174,6 +268,7 @@ func (ts *instanceBindDataTestSuite) MustGetFixture(sym string) *internal.Instan
 func (ts *instanceBindDataTestSuite) MustCopyFixture(in *internal.InstanceBindData) *internal.InstanceBindData {
 	out := &internal.InstanceBindData{
 		InstanceID:  in.InstanceID,
+		BindingID:   in.BindingID,
 		Credentials: make(internal.InstanceCredentials),
 	}
 
@@ -202,6 +297,6 @@ func (ts *instanceBindDataTestSuite) AssertAlreadyExistsError(err error) bool {
 
 func (ts *instanceBindDataTestSuite) AssertInstanceBindDataDoesNotExist(i *internal.InstanceBindData) bool {
 	ts.t.Helper()
-	_, err := ts.s.Get(i.InstanceID)
+	_, err := ts.s.Get(i.InstanceID, i.BindingID)
 	return assert.True(ts.t, storage.IsNotFoundError(err), "NotFound error expected")
 }
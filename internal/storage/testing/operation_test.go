@@ -212,6 +212,33 @@ func TestInstanceOperationGetAll(t *testing.T) {
 	})
 }
 
+func TestInstanceOperationGetInstanceIDs(t *testing.T) {
+	tRunDrivers(t, "Found", func(t *testing.T, sf storage.Factory) {
+		// GIVEN:
+		ts := newInMemoryOperationTestSuite(t, sf)
+		ts.PopulateStorage()
+
+		// WHEN:
+		got, err := ts.s.GetInstanceIDs()
+
+		// THEN:
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []internal.InstanceID{"iID-001", "iID-002"}, got)
+	})
+
+	tRunDrivers(t, "Empty", func(t *testing.T, sf storage.Factory) {
+		// GIVEN:
+		ts := newInMemoryOperationTestSuite(t, sf)
+
+		// WHEN:
+		got, err := ts.s.GetInstanceIDs()
+
+		// THEN:
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
 func TestInstanceOperationUpdateState(t *testing.T) {
 	tRunDrivers(t, "Success", func(t *testing.T, sf storage.Factory) {
 		// GIVEN:
@@ -367,6 +394,7 @@ func (ts *operationTestSuite) generateFixtures() {
 			State:                  ft.opState,
 			StateDescription:       &ft.sDesc,
 			ProvisioningParameters: &internal.RequestParameters{Data: ft.params},
+			RequestedBy:            internal.OperationRequester{Username: "user-" + ft.opID, UID: "uid-" + ft.opID},
 		}
 
 		ts.fixtures[ir] = &io
@@ -407,6 +435,7 @@ func (ts *operationTestSuite) MustCopyFixture(in *internal.InstanceOperation) *i
 		State:                  in.State,
 		CreatedAt:              in.CreatedAt,
 		ProvisioningParameters: in.ProvisioningParameters,
+		RequestedBy:            in.RequestedBy,
 	}
 
 	if in.StateDescription != nil {
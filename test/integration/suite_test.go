@@ -139,7 +139,10 @@ func newTestSuite(t *testing.T, docsEnabled, httpBasicAuth bool) *testSuite {
 	helmClient.SetInstallingTimeout(time.Second)
 
 	brokerServer := broker.New(sFact.Addon(), sFact.Chart(), sFact.InstanceOperation(), sFact.BindOperation(), sFact.Instance(), sFact.InstanceBindData(),
-		bind.NewRenderer(), bind.NewResolver(k8sClientset.CoreV1()), helmClient, logger.WithField("test", "int"))
+		bind.NewRenderer(), bind.NewResolver(k8sClientset.CoreV1()), bind.NewRenderer().WithNamespacedLookup(restConfig), helmClient, nil, nil, broker.Config{OperationQueue: broker.OperationQueueConfig{GlobalLimit: 10, NamespaceLimit: 10}}, logger.WithField("test", "int"))
+	go func() {
+		assert.NoError(t, brokerServer.ProcessOperations(context.Background()))
+	}()
 
 	// OSB API Server
 	server := httptest.NewServer(brokerServer.CreateHandler())